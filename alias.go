@@ -0,0 +1,71 @@
+package env
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	aliasesMu sync.RWMutex
+	aliases   map[string]*alias
+)
+
+// alias is a former name a variable can still be resolved from
+type alias struct {
+	old  string
+	warn bool
+	// warned is set once the deprecation warning has been logged
+	warned atomic.Bool
+}
+
+// AliasOption configures an alias registered with Alias
+type AliasOption func(*alias)
+
+// WithDeprecationWarning logs a warning the first time a variable is resolved
+// from its old name, to the logger given by WithLogger or else slog.Default
+func WithDeprecationWarning() AliasOption {
+	return func(a *alias) {
+		a.warn = true
+	}
+}
+
+// Alias lets the variable newName be resolved from oldName when newName is
+// not set, so that a renamed variable keeps working during a migration. It is
+// honored by every lookup of an Expander, including expansion, Lookup and
+// Decode, and is safe for concurrent use. Registering newName again replaces
+// its alias.
+func Alias(oldName, newName string, opts ...AliasOption) {
+	a := &alias{old: oldName}
+	for _, opt := range opts {
+		opt(a)
+	}
+	aliasesMu.Lock()
+	defer aliasesMu.Unlock()
+	if aliases == nil {
+		aliases = make(map[string]*alias)
+	}
+	aliases[newName] = a
+}
+
+// aliasLookup resolves name from its old name registered with Alias
+func (e *expander) aliasLookup(name string) (string, bool, error) {
+	aliasesMu.RLock()
+	a, ok := aliases[name]
+	aliasesMu.RUnlock()
+	if !ok || !e.allowed(a.old) {
+		return "", false, nil
+	}
+	value, found, err := e.providerLookup(a.old)
+	if err == nil && found && a.warn && a.warned.CompareAndSwap(false, true) {
+		logger := e.cfg.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		logger.LogAttrs(context.Background(), slog.LevelWarn, "variable is deprecated, use its new name",
+			slog.String("name", a.old),
+			slog.String("new_name", name))
+	}
+	return value, found, err
+}
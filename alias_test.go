@@ -0,0 +1,62 @@
+package env
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// setAlias registers an alias for the duration of the test
+func setAlias(t *testing.T, oldName, newName string, opts ...AliasOption) {
+	t.Helper()
+	Alias(oldName, newName, opts...)
+	t.Cleanup(func() {
+		aliasesMu.Lock()
+		delete(aliases, newName)
+		aliasesMu.Unlock()
+	})
+}
+
+func TestAlias(t *testing.T) {
+	setAlias(t, "DB_HOSTNAME", "DB_HOST", WithDeprecationWarning())
+	setAlias(t, "OLD_PORT", "PORT")
+
+	var buf bytes.Buffer
+	x := NewExpander(
+		WithProvider(Map{"DB_HOSTNAME": "db", "OLD_PORT": "1", "PORT": "5432"}),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+	)
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"$DB_HOST:$PORT", "db:5432"},
+		{"${DB_HOST:-localhost}", "db"},
+		{"${DB_HOSTNAME}", "db"},
+		{"${MISSING:-none}", "none"},
+	}
+	for _, tt := range tests {
+		if got, err := x.Expand(tt.input); err != nil || got != tt.want {
+			t.Errorf("Expand(%q) got = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if value, found, err := x.Lookup("DB_HOST"); err != nil || !found || value != "db" {
+		t.Errorf("Lookup(DB_HOST) got = %q, %v, %v", value, found, err)
+	}
+
+	var cfg struct {
+		Host string `env:"DB_HOST"`
+	}
+	if err := x.Decode(&cfg); err != nil || cfg.Host != "db" {
+		t.Errorf("Decode() got = %+v, %v", cfg, err)
+	}
+
+	if n := strings.Count(buf.String(), "variable is deprecated"); n != 1 {
+		t.Errorf("logged %d deprecation warnings, want 1:\n%s", n, buf.String())
+	}
+	if !strings.Contains(buf.String(), "name=DB_HOSTNAME new_name=DB_HOST") {
+		t.Errorf("warning does not name the variables: %s", buf.String())
+	}
+}
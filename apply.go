@@ -0,0 +1,69 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Rollback restores the process environment to the state it had before the
+// changes that returned it were applied
+type Rollback func() error
+
+// Apply sets or unsets variables of the process environment: a non-nil value
+// sets the variable, a nil value unsets it.
+// If any change fails, the changes applied so far are reverted and the error is
+// returned. On success the returned Rollback restores the previous values.
+func Apply(changes map[string]*string) (Rollback, error) {
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Remember the previous state of every variable before touching any of them
+	previous := make(map[string]*string, len(names))
+	for _, name := range names {
+		if value, ok := os.LookupEnv(name); ok {
+			previous[name] = &value
+		} else {
+			previous[name] = nil
+		}
+	}
+
+	rollback := func(applied []string) error {
+		var errs []error
+		for i := len(applied) - 1; i >= 0; i-- {
+			if err := setOrUnset(applied[i], previous[applied[i]]); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	for i, name := range names {
+		if err := setOrUnset(name, changes[name]); err != nil {
+			if rbErr := rollback(names[:i]); rbErr != nil {
+				return nil, errors.Join(err, fmt.Errorf("rollback failed: %w", rbErr))
+			}
+			return nil, err
+		}
+	}
+
+	return func() error { return rollback(names) }, nil
+}
+
+// setOrUnset sets name to *value, or unsets it if value is nil
+func setOrUnset(name string, value *string) error {
+	if value == nil {
+		if err := os.Unsetenv(name); err != nil {
+			return fmt.Errorf("failed to unset variable '%s': %w", name, err)
+		}
+		return nil
+	}
+	if err := os.Setenv(name, *value); err != nil {
+		return fmt.Errorf("failed to set variable '%s': %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package env
+
+import (
+	"os"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	os.Setenv("APPLY_CHANGED", "before")
+	os.Setenv("APPLY_REMOVED", "present")
+	os.Unsetenv("APPLY_ADDED")
+	defer func() {
+		os.Unsetenv("APPLY_CHANGED")
+		os.Unsetenv("APPLY_REMOVED")
+		os.Unsetenv("APPLY_ADDED")
+	}()
+
+	after, added := "after", "added"
+	rollback, err := Apply(map[string]*string{
+		"APPLY_CHANGED": &after,
+		"APPLY_REMOVED": nil,
+		"APPLY_ADDED":   &added,
+	})
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+
+	if got := os.Getenv("APPLY_CHANGED"); got != "after" {
+		t.Errorf("APPLY_CHANGED got = %v, want after", got)
+	}
+	if _, ok := os.LookupEnv("APPLY_REMOVED"); ok {
+		t.Errorf("APPLY_REMOVED should have been unset")
+	}
+	if got := os.Getenv("APPLY_ADDED"); got != "added" {
+		t.Errorf("APPLY_ADDED got = %v, want added", got)
+	}
+
+	if err := rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+
+	if got := os.Getenv("APPLY_CHANGED"); got != "before" {
+		t.Errorf("APPLY_CHANGED got = %v after rollback, want before", got)
+	}
+	if got := os.Getenv("APPLY_REMOVED"); got != "present" {
+		t.Errorf("APPLY_REMOVED got = %v after rollback, want present", got)
+	}
+	if _, ok := os.LookupEnv("APPLY_ADDED"); ok {
+		t.Errorf("APPLY_ADDED should have been unset by rollback")
+	}
+}
+
+func TestApplyFailure(t *testing.T) {
+	os.Setenv("APPLY_FIRST", "original")
+	defer os.Unsetenv("APPLY_FIRST")
+
+	value := "changed"
+	// Names containing '=' cannot be set; it sorts after APPLY_FIRST so the
+	// earlier change has to be reverted
+	_, err := Apply(map[string]*string{
+		"APPLY_FIRST": &value,
+		"Z=INVALID":   &value,
+	})
+	if err == nil {
+		t.Fatal("Apply() expected an error for an invalid variable name")
+	}
+	if got := os.Getenv("APPLY_FIRST"); got != "original" {
+		t.Errorf("APPLY_FIRST got = %v, want original after a failed Apply", got)
+	}
+}
@@ -0,0 +1,68 @@
+package env
+
+import "fmt"
+
+// prefetch looks up the variables referenced by input in one round trip if
+// the provider is a BatchProvider. Only the default syntax is supported;
+// references written otherwise are looked up one by one.
+func (e *expander) prefetch(input string) error {
+	bp, ok := e.cfg.provider.(BatchProvider)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, name := range e.referencedNames(input) {
+		if _, ok := e.batch[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	values, err := bp.LookupMany(names)
+	if err != nil {
+		return fmt.Errorf("failed to look up variables: %w", err)
+	}
+	if e.batch == nil {
+		e.batch = make(map[string]cacheEntry, len(names))
+	}
+	for _, name := range names {
+		value, found := values[name]
+		e.batch[name] = cacheEntry{value: value, found: found}
+	}
+	return nil
+}
+
+// referencedNames returns the names of the variables referenced by inputs
+// that the lookup filters allow, in order of appearance and without
+// duplicates. Only the default syntax is supported, and inputs that cannot
+// be parsed are skipped.
+func (e *expander) referencedNames(inputs ...string) []string {
+	if e.cfg.refSyntax() != defaultSyntax {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool)
+	for _, input := range inputs {
+		refs, err := References(input)
+		if err != nil {
+			continue // reported by the expansion itself
+		}
+		for _, ref := range refs {
+			name := ref.Name
+			if ref.Braced {
+				name = e.normalize(name)
+				if !e.isValidName(name) {
+					continue
+				}
+			}
+			if seen[name] || !e.allowed(name) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// batchMap is a BatchProvider recording its calls
+type batchMap struct {
+	Map
+	batches [][]string
+	lookups []string
+	err     error
+}
+
+func (b *batchMap) Lookup(name string) (string, bool) {
+	b.lookups = append(b.lookups, name)
+	return b.Map.Lookup(name)
+}
+
+func (b *batchMap) LookupMany(names []string) (map[string]string, error) {
+	b.batches = append(b.batches, names)
+	if b.err != nil {
+		return nil, b.err
+	}
+	values := make(map[string]string)
+	for _, name := range names {
+		if value, ok := b.Map[name]; ok {
+			values[name] = value
+		}
+	}
+	return values, nil
+}
+
+func TestBatchProvider(t *testing.T) {
+	b := &batchMap{Map: Map{"HOST": "db", "PORT": ""}}
+	x := NewExpander(WithProvider(b))
+
+	got, err := x.Expand("$HOST:${PORT:=5432}/${NAME:-app} $HOST ${bad-name}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "db:5432/app db ${bad-name}"; got != want {
+		t.Errorf("Expand() got = %q, want %q", got, want)
+	}
+	if want := [][]string{{"HOST", "PORT", "NAME"}}; !reflect.DeepEqual(b.batches, want) {
+		t.Errorf("LookupMany() calls = %v, want %v", b.batches, want)
+	}
+	if len(b.lookups) != 0 {
+		t.Errorf("Lookup() called for %v", b.lookups)
+	}
+	if b.Map["PORT"] != "5432" {
+		t.Errorf("assignment not written back: %v", b.Map)
+	}
+
+	b.err = errors.New("unreachable")
+	if _, err := x.Expand("$HOST"); err == nil {
+		t.Error("Expand() expected an error")
+	}
+}
+
+func TestBatchProviderFilter(t *testing.T) {
+	b := &batchMap{Map: Map{"HOST": "db", "TOKEN": "secret"}}
+	x := NewExpander(WithProvider(b), WithLookupFilter(func(name string) bool { return name == "HOST" }))
+	if got, err := x.Expand("$HOST $TOKEN"); err != nil || got != "db " {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+	if want := [][]string{{"HOST"}}; !reflect.DeepEqual(b.batches, want) {
+		t.Errorf("LookupMany() calls = %v, want %v", b.batches, want)
+	}
+}
@@ -0,0 +1,171 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// CachedProvider remembers the results of lookups made to a slower provider,
+// such as a remote one, for a limited time. Misses are cached as well.
+// It is safe for concurrent use if the wrapped provider is.
+type CachedProvider struct {
+	base    Provider
+	ttl     time.Duration
+	now     func() time.Time
+	metrics Metrics
+
+	mu       sync.Mutex
+	entries  map[string]cacheEntry
+	onChange []func(changes []dotenv.Change)
+}
+
+type cacheEntry struct {
+	value   string
+	found   bool
+	expires time.Time // zero if the entry never expires
+}
+
+// Cached wraps p with a cache keeping lookup results for ttl.
+// A ttl of zero or less keeps results until they are invalidated.
+func Cached(p Provider, ttl time.Duration) *CachedProvider {
+	return &CachedProvider{
+		base:    p,
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// SetMetrics reports cache hits and misses to m
+func (c *CachedProvider) SetMetrics(m Metrics) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.metrics = m
+}
+
+// Lookup returns the cached result for name, consulting the wrapped provider
+// if there is none or it has expired
+func (c *CachedProvider) Lookup(name string) (string, bool) {
+	value, found, _ := c.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext is like Lookup, using the context-aware lookup of the wrapped
+// provider if it has one. Failed lookups are not cached.
+func (c *CachedProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[name]
+	if ok && !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		ok = false
+	}
+	metrics := c.metrics
+	c.mu.Unlock()
+
+	if metrics != nil {
+		metrics.ObserveCache(ok)
+	}
+	if ok {
+		return entry.value, entry.found, nil
+	}
+
+	value, found, err := c.lookupBase(ctx, name)
+	if err != nil {
+		return "", false, err
+	}
+	c.store(name, value, found)
+	return value, found, nil
+}
+
+// lookupBase looks name up in the wrapped provider, bypassing the cache
+func (c *CachedProvider) lookupBase(ctx context.Context, name string) (string, bool, error) {
+	if cp, ok := c.base.(ContextProvider); ok {
+		return cp.LookupContext(ctx, name)
+	}
+	value, found := c.base.Lookup(name)
+	return value, found, nil
+}
+
+// Prefetch looks up the names that are not cached yet and caches the
+// results, in one round trip if the wrapped provider is a BatchProvider
+func (c *CachedProvider) Prefetch(ctx context.Context, names []string) error {
+	var missing []string
+	c.mu.Lock()
+	for _, name := range names {
+		entry, ok := c.entries[name]
+		if !ok || !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+			missing = append(missing, name)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if bp, ok := c.base.(BatchProvider); ok {
+		values, err := bp.LookupMany(missing)
+		if err != nil {
+			return err
+		}
+		for _, name := range missing {
+			value, found := values[name]
+			c.store(name, value, found)
+		}
+		return nil
+	}
+	for _, name := range missing {
+		if _, _, err := c.LookupContext(ctx, name); err != nil {
+			return fmt.Errorf("failed to look up variable '%s': %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Set assigns name in the wrapped provider, if it is a Setter, and updates the
+// cached entry
+func (c *CachedProvider) Set(name, value string) error {
+	if setter, ok := c.base.(Setter); ok {
+		if err := setter.Set(name, value); err != nil {
+			return err
+		}
+	}
+	c.store(name, value, true)
+	return nil
+}
+
+// Invalidate drops the cached entries of names, or all entries if no names are given
+func (c *CachedProvider) Invalidate(names ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(names) == 0 {
+		c.entries = make(map[string]cacheEntry)
+		return
+	}
+	for _, name := range names {
+		delete(c.entries, name)
+	}
+}
+
+func (c *CachedProvider) store(name, value string, found bool) {
+	entry := c.entry(value, found)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[name] = entry
+}
+
+// entry returns a cache entry for a lookup made now
+func (c *CachedProvider) entry(value string, found bool) cacheEntry {
+	entry := cacheEntry{value: value, found: found}
+	if c.ttl > 0 {
+		entry.expires = c.now().Add(c.ttl)
+	}
+	return entry
+}
+
+// Ping checks the wrapped provider, see HealthChecker
+func (c *CachedProvider) Ping(ctx context.Context) error {
+	return ping(ctx, c.base)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var completionCommand = &command{
+	name:  "completion",
+	usage: "completion bash|zsh|fish   print a shell completion script",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("completion needs a shell: bash, zsh or fish")
+		}
+		script, ok := completionScripts[args[0]]
+		if !ok {
+			return fmt.Errorf("unsupported shell %q", args[0])
+		}
+		_, err := fmt.Fprint(os.Stdout, script)
+		return err
+	},
+}
+
+// completeCommand prints the candidates for the last of its arguments, which
+// are the words of the command line after go-env. It is called by the
+// completion scripts and left out of the usage.
+var completeCommand = &command{
+	name: "__complete",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		for _, candidate := range complete(args) {
+			fmt.Fprintln(os.Stdout, candidate)
+		}
+		return nil
+	},
+}
+
+func init() {
+	// Registered here as it refers to commands
+	commands = append(commands, completeCommand)
+}
+
+// Directives printed instead of candidates to let the shell complete the word
+const (
+	completeFiles    = ":file"
+	completeCommands = ":command"
+)
+
+// complete returns the candidates for the last word of words, the words of
+// the command line following go-env
+func complete(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur, words := words[len(words)-1], words[:len(words)-1]
+
+	var files []string
+	i := 0
+	for ; i < len(words) && strings.HasPrefix(words[i], "-"); i++ {
+		if words[i] == "-f" && i+1 < len(words) {
+			i++
+			files = append(files, words[i])
+		} else if file, ok := strings.CutPrefix(words[i], "-f="); ok {
+			files = append(files, file)
+		}
+	}
+	if i == len(words) {
+		switch {
+		case len(words) > 0 && words[len(words)-1] == "-f":
+			return []string{completeFiles}
+		case strings.HasPrefix(cur, "-"):
+			return filter([]string{"-f", "-o", "-trace"}, cur)
+		}
+		var names []string
+		for _, cmd := range commands {
+			if cmd.usage != "" {
+				names = append(names, cmd.name)
+			}
+		}
+		return filter(names, cur)
+	}
+
+	name, args := words[i], words[i+1:]
+	var cmd *command
+	for _, c := range commands {
+		if c.name == name {
+			cmd = c
+		}
+	}
+	if cmd == nil {
+		return nil
+	}
+	switch {
+	case name == "get":
+		return filter(without(loadNames(files), args), cur)
+	case name == "exec" || name == "run":
+		if len(args) == 0 && cur == "--" {
+			return []string{cur}
+		}
+		if len(args) == 0 || len(args) == 1 && args[0] == "--" {
+			return []string{completeCommands}
+		}
+		if ref, ok := strings.CutPrefix(cur, "${"); ok {
+			return wrap(filter(loadNames(files), ref), "${", "}")
+		}
+		if ref, ok := strings.CutPrefix(cur, "$"); ok {
+			return wrap(filter(loadNames(files), ref), "$", "")
+		}
+	case strings.HasPrefix(cur, "-"):
+		return filter(usageFlags(cmd.usage), cur)
+	case name == "completion":
+		return filter([]string{"bash", "fish", "zsh"}, cur)
+	}
+	return []string{completeFiles}
+}
+
+// flagPattern matches the flags in the usage of a command
+var flagPattern = regexp.MustCompile(`(?:^|[\s\[])(-[a-z][a-z-]*)`)
+
+// usageFlags returns the flags mentioned in the usage of a command
+func usageFlags(usage string) []string {
+	var flags []string
+	for _, m := range flagPattern.FindAllStringSubmatch(usage, -1) {
+		flags = append(flags, m[1])
+	}
+	return flags
+}
+
+// loadNames returns the sorted names of the variables of the .env files,
+// skipping the files that cannot be read
+func loadNames(files []string) []string {
+	seen := make(map[string]bool)
+	for _, file := range files {
+		vars, err := dotenv.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		for name := range vars {
+			seen[name] = true
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filter returns the candidates starting with prefix
+func filter(candidates []string, prefix string) []string {
+	var matches []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			matches = append(matches, c)
+		}
+	}
+	return matches
+}
+
+// without returns the candidates not in exclude
+func without(candidates, exclude []string) []string {
+	var kept []string
+	for _, c := range candidates {
+		if !slices.Contains(exclude, c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// wrap surrounds every candidate with prefix and suffix
+func wrap(candidates []string, prefix, suffix string) []string {
+	for i, c := range candidates {
+		candidates[i] = prefix + c + suffix
+	}
+	return candidates
+}
+
+var completionScripts = map[string]string{
+	"bash": `# bash completion for go-env
+_go_env() {
+    local cur=${COMP_WORDS[COMP_CWORD]}
+    local IFS=$'\n'
+    local out
+    out=($(go-env __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+    case ${out[0]} in
+    :file) COMPREPLY=($(compgen -f -- "$cur")) ;;
+    :command) COMPREPLY=($(compgen -c -- "$cur")) ;;
+    *) COMPREPLY=("${out[@]}") ;;
+    esac
+}
+complete -o filenames -F _go_env go-env
+`,
+	"zsh": `#compdef go-env
+# zsh completion for go-env
+_go_env() {
+    local -a out
+    out=(${(f)"$(go-env __complete "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+    case $out[1] in
+    :file) _files ;;
+    :command) _command_names -e ;;
+    *) compadd -Q -- $out ;;
+    esac
+}
+compdef _go_env go-env
+`,
+	"fish": `# fish completion for go-env
+function __go_env_complete
+    set -l words (commandline -opc)
+    set -e words[1]
+    set -l out (go-env __complete $words (commandline -ct) 2>/dev/null)
+    switch "$out[1]"
+        case :file
+            __fish_complete_path (commandline -ct)
+        case :command
+            __fish_complete_command (commandline -ct)
+        case '*'
+            printf '%s\n' $out
+    end
+end
+complete -c go-env -f -a '(__go_env_complete)'
+`,
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var diffCommand = &command{
+	name:  "diff",
+	usage: "diff [-values] a.env b.env   report variables added, removed or changed",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+		values := fs.Bool("values", false, "show values instead of redacting them")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() != 2 {
+			return errors.New("diff needs exactly two files")
+		}
+
+		changes, err := dotenv.Diff(fs.Arg(0), fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		for _, c := range changes {
+			switch {
+			case !*values:
+				fmt.Fprintf(os.Stdout, "%s %s\n", diffMarks[c.Kind], c.Key)
+			case c.Kind == dotenv.Added:
+				fmt.Fprintf(os.Stdout, "+ %s=%s\n", c.Key, c.New)
+			case c.Kind == dotenv.Removed:
+				fmt.Fprintf(os.Stdout, "- %s=%s\n", c.Key, c.Old)
+			default:
+				fmt.Fprintf(os.Stdout, "~ %s: %s -> %s\n", c.Key, c.Old, c.New)
+			}
+		}
+		if len(changes) > 0 {
+			return exitStatus(1)
+		}
+		return nil
+	},
+}
+
+var diffMarks = map[dotenv.ChangeKind]string{
+	dotenv.Added:   "+",
+	dotenv.Removed: "-",
+	dotenv.Changed: "~",
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hadi77ir/go-env"
+)
+
+var doctorCommand = &command{
+	name:  "doctor",
+	usage: "doctor -schema file [-no-color]   check the environment against a schema",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+		schemaFile := fs.String("schema", "", "read the schema from `file` (YAML or JSON)")
+		noColor := fs.Bool("no-color", false, "disable colored output")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *schemaFile == "" {
+			return errors.New("doctor needs a schema")
+		}
+
+		schema, err := env.ReadSchema(*schemaFile)
+		if err != nil {
+			return err
+		}
+		vars, err := g.load()
+		if err != nil {
+			return err
+		}
+
+		results := schema.Check(vars)
+		printReport(os.Stdout, results, !*noColor && useColor(os.Stdout))
+		for _, result := range results {
+			if result.Err != nil {
+				return exitStatus(1)
+			}
+		}
+		return nil
+	},
+}
+
+const (
+	colorReset = "\x1b[0m"
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorGray  = "\x1b[90m"
+)
+
+// useColor reports whether colors should be written to f: it must be a
+// terminal and NO_COLOR must not be set
+func useColor(f *os.File) bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// printReport writes a line for every checked variable followed by a summary
+func printReport(w io.Writer, results []env.CheckResult, color bool) {
+	paint := func(c, s string) string {
+		if !color {
+			return s
+		}
+		return c + s + colorReset
+	}
+
+	failed := 0
+	for _, result := range results {
+		switch {
+		case result.Err != nil:
+			failed++
+			fmt.Fprintf(w, "%s %s: %v\n", paint(colorRed, "FAIL"), result.Name, result.Err)
+		case !result.Found:
+			fmt.Fprintf(w, "%s %s: not set\n", paint(colorGray, "skip"), result.Name)
+		default:
+			fmt.Fprintf(w, "%s %s\n", paint(colorGreen, "ok  "), result.Name)
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintln(w, paint(colorRed, fmt.Sprintf("%d of %d variables failed", failed, len(results))))
+	} else {
+		fmt.Fprintln(w, paint(colorGreen, fmt.Sprintf("all %d variables ok", len(results))))
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var execCommand = &command{
+	name:  "exec",
+	usage: "exec [--] program [arguments]   replace go-env with a program under the loaded environment",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return errors.New("no command given")
+		}
+		vars, err := g.load()
+		if err != nil {
+			return err
+		}
+
+		argv := make([]string, len(args))
+		for i, arg := range args {
+			if argv[i], err = g.expander(vars, fmt.Sprintf("argument %d", i)).Expand(arg); err != nil {
+				return fmt.Errorf("failed to expand argument %d: %w", i, err)
+			}
+		}
+		return execProgram(ctx, argv, vars)
+	},
+}
@@ -0,0 +1,28 @@
+//go:build !unix
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/hadi77ir/go-env"
+)
+
+// execProgram runs argv as a child process, as replacing the current process
+// is not supported on this platform. The program is resolved with the PATH of
+// vars, and the exit status of the child is propagated by main.
+func execProgram(ctx context.Context, argv []string, vars env.Map) error {
+	program, err := env.LookPath(argv[0], vars)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, program, argv[1:]...)
+	cmd.Args[0] = argv[0]
+	cmd.Env = vars.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// helperName is the name the test binary runs as the program started by exec
+const helperName = "go-env-helper"
+
+// TestMain runs the test binary as go-env when GO_ENV_TEST_MAIN is set, and as
+// a program printing its arguments and GREETING when started as helperName
+func TestMain(m *testing.M) {
+	if strings.TrimSuffix(filepath.Base(os.Args[0]), ".exe") == helperName {
+		for _, arg := range os.Args[1:] {
+			fmt.Println(arg)
+		}
+		fmt.Printf("GREETING=%s\n", os.Getenv("GREETING"))
+		status, _ := strconv.Atoi(os.Getenv("HELPER_EXIT"))
+		os.Exit(status)
+	}
+	if os.Getenv("GO_ENV_TEST_MAIN") == "1" {
+		main()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// installHelper copies the test binary to dir under helperName
+func installHelper(t *testing.T, dir string) {
+	t.Helper()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := helperName
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runGoEnv runs go-env with args in a child process, returning its output and
+// exit status
+func runGoEnv(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Env = append(os.Environ(), "GO_ENV_TEST_MAIN=1")
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return string(out), 0
+	case errors.As(err, &exitErr):
+		return string(out), exitErr.ExitCode()
+	}
+	t.Fatalf("running go-env: %v", err)
+	return "", 0
+}
+
+func TestExecCommand(t *testing.T) {
+	bin := t.TempDir()
+	installHelper(t, bin)
+	dotEnv := filepath.Join(t.TempDir(), ".env")
+	// The helper is only found through the PATH of the file, not the one
+	// go-env runs with
+	content := fmt.Sprintf("PATH=%s\nGREETING=hello\nHELPER_EXIT=3\n", bin)
+	if err := os.WriteFile(dotEnv, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, status := runGoEnv(t, "-f", dotEnv, "-o", "exec", "--", helperName, "${GREETING}-arg", "plain")
+	if want := "hello-arg\nplain\nGREETING=hello\n"; out != want {
+		t.Errorf("exec output = %q, want %q", out, want)
+	}
+	if status != 3 {
+		t.Errorf("exec exit status = %d, want the status of the program, 3", status)
+	}
+
+	out, status = runGoEnv(t, "-f", dotEnv, "exec", "--", helperName)
+	if status != 1 || !strings.Contains(out, helperName) {
+		t.Errorf("exec without -o got %q, status %d, want the program not found in the PATH of go-env", out, status)
+	}
+
+	out, status = runGoEnv(t, "-f", dotEnv, "-o", "exec", "--", helperName, "${MISSING:?not set}")
+	if status != 1 || !strings.Contains(out, "argument 1") {
+		t.Errorf("exec with a failing argument got %q, status %d", out, status)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"syscall"
+
+	"github.com/hadi77ir/go-env"
+)
+
+// execProgram replaces the current process with argv, resolving the program
+// with the PATH of vars
+func execProgram(_ context.Context, argv []string, vars env.Map) error {
+	program, err := env.LookPath(argv[0], vars)
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(program, argv, vars.Environ())
+}
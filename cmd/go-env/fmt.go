@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var fmtCommand = &command{
+	name:  "fmt",
+	usage: "fmt [-w] [-l] [-sort] [-strip-export] [files]   format .env files",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("fmt", flag.ContinueOnError)
+		write := fs.Bool("w", false, "write the result to the files instead of stdout")
+		list := fs.Bool("l", false, "list the files whose formatting differs")
+		sortKeys := fs.Bool("sort", false, "sort variables by name")
+		stripExport := fs.Bool("strip-export", false, "remove export prefixes")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		var opts []dotenv.FormatOption
+		if *sortKeys {
+			opts = append(opts, dotenv.SortKeys())
+		}
+		if *stripExport {
+			opts = append(opts, dotenv.StripExport())
+		}
+
+		if fs.NArg() == 0 {
+			src, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			out, err := dotenv.Format(src, opts...)
+			if err != nil {
+				return fmt.Errorf("<stdin>: %w", err)
+			}
+			_, err = os.Stdout.Write(out)
+			return err
+		}
+
+		for _, file := range fs.Args() {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				return err
+			}
+			out, err := dotenv.Format(src, opts...)
+			if err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+			changed := !bytes.Equal(src, out)
+			if *list && changed {
+				fmt.Println(file)
+			}
+			switch {
+			case *write && changed:
+				info, err := os.Stat(file)
+				if err != nil {
+					return err
+				}
+				if err := os.WriteFile(file, out, info.Mode().Perm()); err != nil {
+					return err
+				}
+			case !*write && !*list:
+				if _, err := os.Stdout.Write(out); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
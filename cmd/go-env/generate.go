@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-env"
+)
+
+var generateCommand = &command{
+	name:  "generate",
+	usage: "generate -schema file [-package name] [-type name] [-o file]   write a typed Go struct for a schema",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+		schemaFile := fs.String("schema", "", "read the schema from `file` (YAML or JSON)")
+		pkg := fs.String("package", os.Getenv("GOPACKAGE"), "name of the generated package, defaulting to $GOPACKAGE as set by go generate")
+		typeName := fs.String("type", "Config", "name of the generated struct")
+		out := fs.String("o", "", "write the code to `file` instead of the standard output")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *schemaFile == "" {
+			return errors.New("generate needs a schema")
+		}
+		if *pkg == "" {
+			*pkg = "config"
+		}
+
+		schema, err := env.ReadSchema(*schemaFile)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		if err := generate(&buf, schema, filepath.Base(*schemaFile), *pkg, *typeName); err != nil {
+			return err
+		}
+		if *out == "" {
+			_, err = os.Stdout.Write(buf.Bytes())
+			return err
+		}
+		return os.WriteFile(*out, buf.Bytes(), 0o644)
+	},
+}
+
+// goTypes maps the types of a schema to the types of the generated fields.
+// URLs are kept as strings, the schema checking their syntax.
+var goTypes = map[string]string{
+	"":         "string",
+	"string":   "string",
+	"int":      "int",
+	"float":    "float64",
+	"bool":     "bool",
+	"duration": "time.Duration",
+	"url":      "string",
+}
+
+// generate writes Go code for schema, read from source, to w: a struct with
+// a field per variable, the schema itself and a Load function validating the
+// variables against the schema before decoding them
+func generate(w io.Writer, schema *env.Schema, source, pkg, typeName string) error {
+	names := make([]string, 0, len(schema.Variables))
+	usesTime := false
+	for name, spec := range schema.Variables {
+		names = append(names, name)
+		usesTime = usesTime || spec.Type == "duration"
+	}
+	sort.Strings(names)
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by go-env generate from %s; DO NOT EDIT.\n\n", source)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n")
+	if usesTime {
+		b.WriteString("\t\"time\"\n\n")
+	}
+	b.WriteString("\tenv \"github.com/hadi77ir/go-env\"\n)\n\n")
+
+	fmt.Fprintf(&b, "// %s holds the variables declared in %s\n", typeName, source)
+	fmt.Fprintf(&b, "type %s struct {\n", typeName)
+	for i, name := range names {
+		spec := schema.Variables[name]
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if spec.Description != "" {
+			for _, line := range strings.Split(strings.TrimSpace(spec.Description), "\n") {
+				fmt.Fprintf(&b, "\t// %s\n", strings.TrimSpace(line))
+			}
+		}
+		if len(spec.Enum) > 0 {
+			fmt.Fprintf(&b, "\t// One of %s\n", strings.Join(spec.Enum, ", "))
+		}
+		tag := name
+		if spec.Required {
+			tag += ",required"
+		}
+		fmt.Fprintf(&b, "\t%s %s `env:%q`\n", fieldName(name), goTypes[spec.Type], tag)
+	}
+	b.WriteString("}\n\n")
+
+	fmt.Fprintf(&b, "// Schema is the schema %s is generated from\n", typeName)
+	b.WriteString("var Schema = &env.Schema{Variables: map[string]env.VarSpec{\n")
+	for _, name := range names {
+		spec := schema.Variables[name]
+		var fields []string
+		if spec.Type != "" {
+			fields = append(fields, fmt.Sprintf("Type: %q", spec.Type))
+		}
+		if spec.Required {
+			fields = append(fields, "Required: true")
+		}
+		if len(spec.Enum) > 0 {
+			fields = append(fields, fmt.Sprintf("Enum: %#v", spec.Enum))
+		}
+		if spec.Description != "" {
+			fields = append(fields, fmt.Sprintf("Description: %q", spec.Description))
+		}
+		fmt.Fprintf(&b, "\t%q: {%s},\n", name, strings.Join(fields, ", "))
+	}
+	b.WriteString("}}\n\n")
+
+	fmt.Fprintf(&b, "// Load reads %s from the environment, checking every variable against\n", typeName)
+	b.WriteString("// Schema first. Options configure the expander, e.g. env.WithProvider.\n")
+	fmt.Fprintf(&b, "func Load(opts ...env.Option) (*%s, error) {\n", typeName)
+	b.WriteString("\tx := env.NewExpander(opts...)\n")
+	b.WriteString("\tif err := x.Validate(Schema); err != nil {\n\t\treturn nil, err\n\t}\n")
+	fmt.Fprintf(&b, "\tvar c %s\n", typeName)
+	b.WriteString("\tif err := x.Decode(&c); err != nil {\n\t\treturn nil, err\n\t}\n")
+	b.WriteString("\treturn &c, nil\n}\n")
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format the generated code: %w", err)
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// initialisms are the words written in upper case in Go identifiers
+var initialisms = map[string]bool{
+	"API": true, "AWS": true, "CPU": true, "DB": true, "DNS": true, "HTTP": true,
+	"HTTPS": true, "ID": true, "IP": true, "JSON": true, "SQL": true, "SSH": true,
+	"TCP": true, "TLS": true, "TTL": true, "UDP": true, "UI": true, "URI": true,
+	"URL": true, "UUID": true,
+}
+
+// fieldName converts a variable name such as DB_HOST into the name of the
+// exported field holding it, DBHost
+func fieldName(name string) string {
+	var b strings.Builder
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		upper := strings.ToUpper(word)
+		if initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		b.WriteString(upper[:1] + strings.ToLower(word[1:]))
+	}
+	if b.Len() == 0 || b.String()[0] < 'A' || b.String()[0] > 'Z' {
+		return "V" + b.String()
+	}
+	return b.String()
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var getCommand = &command{
+	name:  "get",
+	usage: "get name...   print the values of variables of the loaded environment",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		if len(args) == 0 {
+			return errors.New("get needs at least one variable name")
+		}
+		vars, err := g.load()
+		if err != nil {
+			return err
+		}
+
+		status := 0
+		for _, name := range args {
+			value, ok := vars[name]
+			if !ok {
+				fmt.Fprintf(os.Stderr, "go-env: variable '%s' is not set\n", name)
+				status = 1
+				continue
+			}
+			fmt.Fprintln(os.Stdout, value)
+		}
+		if status != 0 {
+			return exitStatus(status)
+		}
+		return nil
+	},
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var lintCommand = &command{
+	name:  "lint",
+	usage: "lint [-json] [-env] file...   check templates and .env files",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+		asJSON := fs.Bool("json", false, "report findings as JSON")
+		useEnv := fs.Bool("env", false, "treat variables of the process environment as defined")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() == 0 {
+			return errors.New("lint needs at least one file")
+		}
+
+		l := &linter{defined: make(map[string]bool), used: make(map[string]bool)}
+		if *useEnv {
+			for _, kv := range os.Environ() {
+				key, _, _ := strings.Cut(kv, "=")
+				l.defined[key] = true
+			}
+		}
+		for _, file := range append(g.files, fs.Args()...) {
+			if err := l.add(file); err != nil {
+				return err
+			}
+		}
+		findings := l.check()
+
+		if *asJSON {
+			if err := writeFindingsJSON(os.Stdout, findings); err != nil {
+				return err
+			}
+		} else {
+			for _, f := range findings {
+				fmt.Fprintln(os.Stdout, f)
+			}
+		}
+		for _, f := range findings {
+			if f.Severity == severityError {
+				return exitStatus(1)
+			}
+		}
+		return nil
+	},
+}
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// finding is a problem reported by lint
+type finding struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+func (f finding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", f.File, f.Line, f.Column, f.Severity, f.Message, f.Rule)
+}
+
+func writeFindingsJSON(w io.Writer, findings []finding) error {
+	if findings == nil {
+		findings = []finding{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(findings)
+}
+
+// lintRef is a reference found in a linted file
+type lintRef struct {
+	env.Reference
+	file         string
+	line, column int
+}
+
+// linter collects the definitions and references of all linted files before
+// checking them against each other
+type linter struct {
+	findings  []finding
+	refs      []lintRef
+	defined   map[string]bool
+	used      map[string]bool
+	keys      []lintRef // .env keys, checked for use
+	templates int
+	// declared holds the position of the first declaration of every .env key
+	// across files, as "file:line"
+	declared map[string]string
+}
+
+// isEnvFile reports whether name looks like a .env file rather than a template
+func isEnvFile(name string) bool {
+	base := filepath.Base(name)
+	return strings.HasPrefix(base, ".env") || strings.HasSuffix(base, ".env")
+}
+
+func (l *linter) report(file string, line, column int, severity, rule, format string, args ...any) {
+	l.findings = append(l.findings, finding{
+		File:     file,
+		Line:     line,
+		Column:   column,
+		Severity: severity,
+		Rule:     rule,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// add reads a file, recording its definitions and references
+func (l *linter) add(file string) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if isEnvFile(file) {
+		return l.addEnvFile(file, string(data))
+	}
+	l.templates++
+	l.scan(file, string(data), 1, 1)
+	return nil
+}
+
+func (l *linter) addEnvFile(file, data string) error {
+	doc, err := dotenv.ParseDocument(strings.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]int)
+	for _, line := range doc.Lines() {
+		if !line.IsVar {
+			if line.Err != nil {
+				l.report(file, line.Number, line.Err.Column, severityError, "syntax", "%s", line.Err.Msg)
+			}
+			continue
+		}
+
+		column := strings.Index(line.Raw, line.Key) + 1
+		if first, ok := seen[line.Key]; ok {
+			l.report(file, line.Number, column, severityWarning, "duplicate-key", "%s is already defined on line %d", line.Key, first)
+		} else if other, ok := l.declared[line.Key]; ok {
+			l.report(file, line.Number, column, severityWarning, "duplicate-key", "%s overrides its definition in %s", line.Key, other)
+			seen[line.Key] = line.Number
+		} else {
+			seen[line.Key] = line.Number
+			if l.declared == nil {
+				l.declared = make(map[string]string)
+			}
+			l.declared[line.Key] = fmt.Sprintf("%s:%d", file, line.Number)
+		}
+		l.defined[line.Key] = true
+		l.keys = append(l.keys, lintRef{Reference: env.Reference{Name: line.Key}, file: file, line: line.Number, column: column})
+
+		valueColumn := strings.LastIndex(line.Raw, line.RawValue) + 1
+		if raw := line.RawValue; raw != "" && raw[0] != '"' && raw[0] != '\'' {
+			if strings.ContainsAny(raw, `"'`) {
+				l.report(file, line.Number, valueColumn, severityWarning, "quoting", "unquoted value of %s contains quotes", line.Key)
+			} else if strings.HasSuffix(raw, `\`) {
+				l.report(file, line.Number, valueColumn, severityWarning, "quoting", "unquoted value of %s ends with a backslash", line.Key)
+			}
+		}
+		if reason, ok := env.LooksLikeSecret(line.Value); ok && !env.IsSecretName(line.Key) {
+			l.report(file, line.Number, valueColumn, severityWarning, "secret-value", "value of %s looks like a secret (%s) but its name does not mark it as one", line.Key, reason)
+		}
+		// Single-quoted values are taken literally and never expanded
+		if !strings.HasPrefix(line.RawValue, "'") {
+			l.scan(file, line.Value, line.Number, valueColumn)
+		}
+	}
+	return nil
+}
+
+// scan records the references of text, which starts at the given position of file
+func (l *linter) scan(file, text string, line, column int) {
+	refs, err := env.References(text)
+	for _, ref := range refs {
+		refLine, refColumn := position(text, ref.Offset, line, column)
+		if !ref.Valid {
+			l.report(file, refLine, refColumn, severityWarning, "invalid-name", "%q is not a valid variable name and is kept literally", ref.Name)
+			continue
+		}
+		l.used[ref.Name] = true
+		l.refs = append(l.refs, lintRef{Reference: ref, file: file, line: refLine, column: refColumn})
+	}
+	var syntaxErr *env.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		errLine, errColumn := position(text, syntaxErr.Offset, line, column)
+		l.report(file, errLine, errColumn, severityError, "syntax", "%s", syntaxErr.Msg)
+	}
+}
+
+// check reports undefined references and unused variables
+func (l *linter) check() []finding {
+	for _, ref := range l.refs {
+		switch ref.Op {
+		case ":-", ":+", ":=":
+			continue
+		}
+		if !l.defined[ref.Name] {
+			l.report(ref.file, ref.line, ref.column, severityError, "undefined", "%s is not defined", ref.Name)
+		}
+	}
+	// Variables only count as unused when there are templates to use them
+	if l.templates > 0 {
+		for _, key := range l.keys {
+			if !l.used[key.Name] {
+				l.report(key.file, key.line, key.column, severityWarning, "unused", "%s is never referenced", key.Name)
+			}
+		}
+	}
+
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		a, b := l.findings[i], l.findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.findings
+}
+
+// position converts a byte offset of text into a line and column, given the
+// position text starts at
+func position(text string, offset, line, column int) (int, int) {
+	for _, c := range text[:offset] {
+		if c == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
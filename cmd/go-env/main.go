@@ -0,0 +1,183 @@
+// Command go-env loads .env files, expands variables and runs programs
+// under the resulting environment.
+//
+// Usage:
+//
+//	go-env [-f file]... [-o] [-trace] <command> [arguments]
+//
+// For example, to start a server with the variables of two .env files, the
+// later one overriding the earlier:
+//
+//	go-env -f .env -f .env.prod exec -- ./server --flag
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/config"
+)
+
+// command is a go-env subcommand
+type command struct {
+	name  string
+	usage string
+	run   func(ctx context.Context, g *globals, args []string) error
+}
+
+var commands = []*command{
+	execCommand,
+	runCommand,
+	fmtCommand,
+	diffCommand,
+	mergeCommand,
+	lintCommand,
+	doctorCommand,
+	showCommand,
+	generateCommand,
+	getCommand,
+	completionCommand,
+}
+
+// exitStatus makes go-env exit with the given status without printing an error
+type exitStatus int
+
+func (s exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(s))
+}
+
+// globals holds the flags shared by all subcommands
+type globals struct {
+	files    fileList
+	override bool
+	trace    bool
+}
+
+// fileList is a repeatable string flag
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := execute(ctx, os.Args[1:])
+	stop()
+
+	var exitErr *exec.ExitError
+	var status exitStatus
+	switch {
+	case err == nil:
+	case errors.As(err, &exitErr):
+		os.Exit(exitErr.ExitCode())
+	case errors.As(err, &status):
+		os.Exit(int(status))
+	case errors.Is(err, flag.ErrHelp):
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, "go-env:", err)
+		os.Exit(1)
+	}
+}
+
+// execute parses the global flags and dispatches to the requested subcommand
+func execute(ctx context.Context, args []string) error {
+	g := &globals{}
+	fs := flag.NewFlagSet("go-env", flag.ContinueOnError)
+	fs.Var(&g.files, "f", "load variables from `file` (repeatable, later files win)")
+	fs.BoolVar(&g.override, "o", false, "let loaded files override the process environment")
+	fs.BoolVar(&g.trace, "trace", false, "print how every variable reference is resolved to stderr")
+	fs.Usage = func() { usage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return flag.ErrHelp
+	}
+	name := fs.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(ctx, g, fs.Args()[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %q", name)
+}
+
+func usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintln(out, "usage: go-env [-f file]... [-o] [-trace] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+	for _, cmd := range commands {
+		if cmd.usage != "" {
+			fmt.Fprintf(out, "  %s\n", cmd.usage)
+		}
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "flags:")
+	fs.PrintDefaults()
+}
+
+// load builds the environment from the process environment and the .env
+// files given on the command line. Values in each file are expanded in
+// declaration order against the keys declared earlier in the file, then the
+// variables loaded before that file.
+func (g *globals) load() (env.Map, error) {
+	vars := env.Map{}
+	inherited := make(map[string]bool)
+	for _, kv := range os.Environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if key == "" {
+			continue
+		}
+		vars[key] = value
+		inherited[key] = true
+	}
+
+	for _, file := range g.files {
+		values, err := config.ReadFile(file, vars, g.options(file)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		for key, value := range values {
+			if inherited[key] && !g.override {
+				continue
+			}
+			vars[key] = value
+		}
+	}
+	return vars, nil
+}
+
+// expander returns an Expander resolving variables from vars. With -trace,
+// the resolution of every reference is printed to stderr under label.
+func (g *globals) expander(vars env.Map, label string) *env.Expander {
+	return env.NewExpander(append(g.options(label), env.WithProvider(vars))...)
+}
+
+// options returns the expansion options of the global flags. With -trace,
+// the resolution of every reference is printed to stderr under label.
+func (g *globals) options(label string) []env.Option {
+	var opts []env.Option
+	if g.trace {
+		opts = append(opts, env.WithTrace(func(s env.TraceStep) {
+			fmt.Fprintf(os.Stderr, "trace: %s: %s\n", label, s)
+		}))
+	}
+	return opts
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var mergeCommand = &command{
+	name:  "merge",
+	usage: "merge [-strategy s] [-sep s] [-o file] base.env overlay.env...   merge .env files",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("merge", flag.ContinueOnError)
+		strategyName := fs.String("strategy", "last", "how to resolve keys declared twice: first, last, error or append")
+		sep := fs.String("sep", string(os.PathListSeparator), "separator used by the append strategy")
+		output := fs.String("o", "", "write the result to `file` instead of stdout")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if fs.NArg() < 2 {
+			return errors.New("merge needs a base file and at least one overlay")
+		}
+
+		var strategy env.MergeStrategy
+		switch *strategyName {
+		case "first":
+			strategy = env.FirstWins
+		case "last":
+			strategy = env.LastWins
+		case "error":
+			strategy = env.ErrorOnConflict
+		case "append":
+			strategy = env.AppendWith(*sep)
+		default:
+			return fmt.Errorf("unknown merge strategy %q", *strategyName)
+		}
+
+		d, err := dotenv.ReadDocument(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		for _, file := range fs.Args()[1:] {
+			overlay, err := dotenv.ReadDocument(file)
+			if err != nil {
+				return err
+			}
+			if err := d.Merge(overlay, strategy); err != nil {
+				return fmt.Errorf("%s: %w", file, err)
+			}
+		}
+
+		if *output == "" {
+			_, err = d.WriteTo(os.Stdout)
+			return err
+		}
+		return os.WriteFile(*output, []byte(d.String()), 0o600)
+	},
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hadi77ir/go-env"
+)
+
+var runCommand = &command{
+	name:  "run",
+	usage: "run [--] program [arguments]   run a program as a child of go-env under the loaded environment",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+		vars, err := g.load()
+		if err != nil {
+			return err
+		}
+		if g.trace {
+			// Run expands the arguments itself, trace a dry run of it
+			for i, arg := range args {
+				g.expander(vars, fmt.Sprintf("argument %d", i)).Trace(arg)
+			}
+		}
+		return env.Run(ctx, args, vars)
+	},
+}
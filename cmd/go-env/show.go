@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+var showCommand = &command{
+	name:  "show",
+	usage: "show [-format env|json|yaml] [-reveal]   print the resolved variables",
+	run: func(ctx context.Context, g *globals, args []string) error {
+		fs := flag.NewFlagSet("show", flag.ContinueOnError)
+		format := fs.String("format", "env", "print variables as `env`, json or yaml")
+		reveal := fs.Bool("reveal", false, "print the values of secrets instead of redacting them")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+
+		vars, err := g.load()
+		if err != nil {
+			return err
+		}
+		return show(os.Stdout, vars, *format, *reveal)
+	},
+}
+
+// show writes vars to w in format, redacting secrets unless reveal is set
+func show(w io.Writer, vars env.Map, format string, reveal bool) error {
+	var opts []env.ExportOption
+	if reveal {
+		opts = append(opts, env.ExportRedaction(nil))
+	}
+	switch format {
+	case "json":
+		return env.ExportJSON(w, vars, opts...)
+	case "yaml":
+		return env.ExportYAML(w, vars, opts...)
+	case "env":
+		for _, name := range env.NewEnv(vars).Names() {
+			value := vars[name]
+			if !reveal && env.IsSecretName(name) {
+				value = "****"
+			}
+			if _, err := fmt.Fprintf(w, "%s=%s\n", name, dotenv.Quote(value)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
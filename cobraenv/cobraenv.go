@@ -0,0 +1,60 @@
+// Package cobraenv binds the flags of a cobra command tree to environment
+// variables with go-env, in its own module so that go-env does not depend on
+// cobra.
+//
+// Called from the PersistentPreRunE of the root command, once flags are
+// parsed, it gives every flag the precedence: explicit flag, then variable,
+// then default:
+//
+//	root := &cobra.Command{
+//		Use: "myapp",
+//		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+//			return cobraenv.Bind(cmd.Root(), "MYAPP")
+//		},
+//	}
+package cobraenv
+
+import (
+	"github.com/hadi77ir/go-env"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Bind assigns the flags of cmd and its subcommands that were not set
+// explicitly from the process environment, see BindExpander
+func Bind(cmd *cobra.Command, prefix string, opts ...env.Option) error {
+	return BindExpander(env.NewExpander(opts...), cmd, prefix)
+}
+
+// BindExpander assigns the flags of cmd and its subcommands, including
+// persistent flags, that were not set explicitly from variables resolved by x.
+// Variables are named after flags with env.FlagEnvName, so --listen-addr is
+// read from PREFIX_LISTEN_ADDR, and their values are expanded before being
+// set. Flags shared by several commands, such as persistent ones, are
+// assigned once.
+func BindExpander(x *env.Expander, cmd *cobra.Command, prefix string) error {
+	return bindTree(x, cmd, prefix, make(map[*pflag.Flag]bool))
+}
+
+// bindTree binds the flags of cmd not seen yet, then those of its subcommands
+func bindTree(x *env.Expander, cmd *cobra.Command, prefix string, seen map[*pflag.Flag]bool) error {
+	var bindings []env.FlagBinding
+	visit := func(f *pflag.Flag) {
+		if seen[f] {
+			return
+		}
+		seen[f] = true
+		bindings = append(bindings, env.FlagBinding{Name: f.Name, Changed: f.Changed, Set: f.Value.Set})
+	}
+	cmd.PersistentFlags().VisitAll(visit)
+	cmd.Flags().VisitAll(visit)
+	if err := x.Bind(prefix, bindings); err != nil {
+		return err
+	}
+	for _, sub := range cmd.Commands() {
+		if err := bindTree(x, sub, prefix, seen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
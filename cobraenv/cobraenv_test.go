@@ -0,0 +1,72 @@
+package cobraenv
+
+import (
+	"testing"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/spf13/cobra"
+)
+
+func TestBindExpander(t *testing.T) {
+	vars := env.Map{
+		"APP_VERBOSE":     "true",
+		"APP_CONFIG":      "${HOME}/app.yaml",
+		"HOME":            "/home/alice",
+		"APP_LISTEN_ADDR": ":9090",
+		"APP_WORKERS":     "8",
+		"APP_TAGS":        "a,b",
+	}
+
+	var verbose bool
+	var config, listenAddr string
+	var workers int
+	var tags []string
+	var bound error
+
+	root := &cobra.Command{
+		Use: "app",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			bound = BindExpander(env.NewExpander(env.WithProvider(vars)), cmd.Root(), "APP")
+			return bound
+		},
+	}
+	root.PersistentFlags().BoolVar(&verbose, "verbose", false, "")
+	root.PersistentFlags().StringVar(&config, "config", "", "")
+	server := &cobra.Command{Use: "server"}
+	serve := &cobra.Command{Use: "serve", Run: func(*cobra.Command, []string) {}}
+	serve.Flags().StringVar(&listenAddr, "listen-addr", ":8080", "")
+	serve.Flags().IntVar(&workers, "workers", 1, "")
+	serve.Flags().StringSliceVar(&tags, "tags", nil, "")
+	server.AddCommand(serve)
+	root.AddCommand(server)
+
+	root.SetArgs([]string{"server", "serve", "--workers", "2"})
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if bound != nil {
+		t.Fatalf("BindExpander() error = %v", bound)
+	}
+
+	if !verbose || config != "/home/alice/app.yaml" {
+		t.Errorf("persistent flags got verbose = %v, config = %q", verbose, config)
+	}
+	if listenAddr != ":9090" {
+		t.Errorf("--listen-addr got = %q, want the variable", listenAddr)
+	}
+	if workers != 2 {
+		t.Errorf("--workers got = %d, want the explicit flag", workers)
+	}
+	if len(tags) != 2 || tags[0] != "a" || tags[1] != "b" {
+		t.Errorf("--tags got = %v, want the variable set once", tags)
+	}
+}
+
+func TestBindInvalidValue(t *testing.T) {
+	cmd := &cobra.Command{Use: "app"}
+	cmd.Flags().Int("workers", 1, "")
+	x := env.NewExpander(env.WithProvider(env.Map{"APP_WORKERS": "many"}))
+	if err := BindExpander(x, cmd, "APP"); err == nil {
+		t.Error("BindExpander() expected an error for an invalid value")
+	}
+}
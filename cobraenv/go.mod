@@ -0,0 +1,14 @@
+module github.com/hadi77ir/go-env/cobraenv
+
+go 1.24
+
+require (
+	github.com/hadi77ir/go-env v0.0.0
+	github.com/spf13/cobra v1.10.2
+	github.com/spf13/pflag v1.0.9
+)
+
+require github.com/inconshreveable/mousetrap v1.1.0 // indirect
+
+// The root module is developed alongside this one
+replace github.com/hadi77ir/go-env => ../
@@ -0,0 +1,335 @@
+// Package config resolves variables from layers of defaults, .env files, the
+// process environment and explicit overrides, keeping track of the layer each
+// variable comes from.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	env "github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// Names of the layers other than files, which are named by their path
+const (
+	LayerDefaults  = "defaults"
+	LayerEnv       = "env"
+	LayerOverrides = "overrides"
+)
+
+// Layer is a named set of variables
+type Layer struct {
+	Name string
+	Vars map[string]string
+}
+
+// Option configures Load
+type Option func(*loader)
+
+// loader holds the settings of Load
+type loader struct {
+	defaults  map[string]string
+	files     []file
+	environ   []string
+	overrides map[string]string
+	// interpolate expands the values of .env files, falling back to the
+	// process environment unless hermetic is set
+	interpolate bool
+	hermetic    bool
+}
+
+type file struct {
+	path     string
+	optional bool
+}
+
+// Defaults sets the values used for the variables set by no other layer
+func Defaults(vars map[string]string) Option {
+	return func(l *loader) {
+		l.defaults = vars
+	}
+}
+
+// Files reads the .env files at paths, the later ones overriding the earlier
+// ones. Load fails if one of them does not exist.
+func Files(paths ...string) Option {
+	return func(l *loader) {
+		for _, path := range paths {
+			l.files = append(l.files, file{path: path})
+		}
+	}
+}
+
+// OptionalFiles reads the .env files at paths like Files, skipping the ones
+// that do not exist, such as a .env.local kept out of version control
+func OptionalFiles(paths ...string) Option {
+	return func(l *loader) {
+		for _, path := range paths {
+			l.files = append(l.files, file{path: path, optional: true})
+		}
+	}
+}
+
+// Environ replaces the process environment with environ, given in "key=value"
+// form. A nil environ leaves the environment layer out.
+func Environ(environ []string) Option {
+	return func(l *loader) {
+		l.environ = environ
+	}
+}
+
+// Interpolate expands the references in the values of .env files, such as
+// DATA_DIR=${HOME}/data. A reference resolves to the defaults, the files read
+// before and the keys declared earlier in the same file, and then to the
+// process environment unless Hermetic is given. Single-quoted values are
+// taken literally.
+func Interpolate() Option {
+	return func(l *loader) {
+		l.interpolate = true
+	}
+}
+
+// Hermetic keeps Interpolate from resolving references to the process
+// environment, so that the result only depends on the files and defaults
+func Hermetic() Option {
+	return func(l *loader) {
+		l.hermetic = true
+	}
+}
+
+// Overrides sets values taking precedence over every other layer, such as the
+// ones given on the command line
+func Overrides(vars map[string]string) Option {
+	return func(l *loader) {
+		l.overrides = vars
+	}
+}
+
+// Config is the resolved view of its layers. It implements env.Provider and
+// env.Lister, and is safe for concurrent use as it is never modified.
+type Config struct {
+	layers  []Layer
+	vars    env.Map
+	sources map[string]string
+}
+
+// Load reads the layers configured by opts and resolves them, each layer
+// overriding the previous ones in this order: defaults, .env files, the
+// process environment and overrides
+func Load(opts ...Option) (*Config, error) {
+	l := &loader{environ: os.Environ()}
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	var layers []Layer
+	if l.defaults != nil {
+		layers = append(layers, Layer{Name: LayerDefaults, Vars: l.defaults})
+	}
+	s := &scope{vars: make(env.Map)}
+	for name, value := range l.defaults {
+		s.vars[name] = value
+	}
+	if !l.hermetic && l.environ != nil {
+		s.fallback = env.Map(parseEnviron(l.environ))
+	}
+	var errs []error
+	for _, f := range l.files {
+		vars, err := l.readFile(f.path, s)
+		switch {
+		case f.optional && errors.Is(err, fs.ErrNotExist):
+			continue
+		case err != nil:
+			errs = append(errs, fmt.Errorf("failed to read %s: %w", f.path, err))
+			continue
+		}
+		layers = append(layers, Layer{Name: f.path, Vars: vars})
+		for name, value := range vars {
+			s.vars[name] = value
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	if l.environ != nil {
+		layers = append(layers, Layer{Name: LayerEnv, Vars: parseEnviron(l.environ)})
+	}
+	if l.overrides != nil {
+		layers = append(layers, Layer{Name: LayerOverrides, Vars: l.overrides})
+	}
+	return New(layers...), nil
+}
+
+// readFile reads the .env file at path, interpolating its values against s
+// if requested
+func (l *loader) readFile(path string, s *scope) (map[string]string, error) {
+	if !l.interpolate {
+		return dotenv.ReadFile(path)
+	}
+	return ReadFile(path, s)
+}
+
+// ReadFile reads the .env file at path, expanding its values in declaration
+// order as Interpolate does: a reference resolves to the keys declared
+// earlier in the file, then to fallback, which may be nil. Single-quoted
+// values are taken literally. opts configure the expansion.
+func ReadFile(path string, fallback env.Provider, opts ...env.Option) (map[string]string, error) {
+	doc, err := dotenv.ReadDocument(path)
+	if err != nil {
+		return nil, err
+	}
+	file := &scope{vars: make(env.Map), fallback: fallback}
+	x := env.NewExpander(append([]env.Option{env.WithProvider(file), env.WithPure()}, opts...)...)
+	for _, line := range doc.Lines() {
+		if !line.IsVar {
+			continue
+		}
+		value := line.Value
+		if !strings.HasPrefix(line.RawValue, "'") {
+			if value, err = x.Expand(value); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line.Number, err)
+			}
+		}
+		file.vars[line.Key] = value
+	}
+	return file.vars, nil
+}
+
+// scope resolves the variables declared so far during Load, then the ones
+// of its fallback
+type scope struct {
+	vars     env.Map
+	fallback env.Provider
+}
+
+func (s *scope) Lookup(name string) (string, bool) {
+	if value, ok := s.vars[name]; ok {
+		return value, true
+	}
+	if s.fallback == nil {
+		return "", false
+	}
+	return s.fallback.Lookup(name)
+}
+
+// New resolves layers, each overriding the previous ones
+func New(layers ...Layer) *Config {
+	c := &Config{
+		vars:    make(env.Map),
+		sources: make(map[string]string),
+	}
+	for _, layer := range layers {
+		vars := make(map[string]string, len(layer.Vars))
+		for name, value := range layer.Vars {
+			vars[name] = value
+			c.vars[name] = value
+			c.sources[name] = layer.Name
+		}
+		c.layers = append(c.layers, Layer{Name: layer.Name, Vars: vars})
+	}
+	return c
+}
+
+// parseEnviron turns "key=value" pairs into a map
+func parseEnviron(environ []string) map[string]string {
+	vars := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name != "" {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// Lookup returns the resolved value of name
+func (c *Config) Lookup(name string) (string, bool) {
+	return c.vars.Lookup(name)
+}
+
+// Generation always returns 0 as a Config never changes, letting results
+// derived from it be cached, see env.Generational
+func (c *Config) Generation() uint64 {
+	return 0
+}
+
+// Environ returns the resolved variables in "key=value" form, sorted by key
+func (c *Config) Environ() []string {
+	return c.vars.Environ()
+}
+
+// Source returns the name of the layer supplying name: LayerDefaults,
+// LayerEnv, LayerOverrides or the path of a .env file
+func (c *Config) Source(name string) (string, bool) {
+	source, ok := c.sources[name]
+	return source, ok
+}
+
+// Sources returns the name of the layer supplying each variable
+func (c *Config) Sources() map[string]string {
+	sources := make(map[string]string, len(c.sources))
+	for name, source := range c.sources {
+		sources[name] = source
+	}
+	return sources
+}
+
+// Shadowed returns, from the lowest to the highest, the names of the layers
+// setting name whose value is overridden by a later layer
+func (c *Config) Shadowed(name string) []string {
+	var layers []string
+	for _, layer := range c.layers {
+		if _, ok := layer.Vars[name]; ok {
+			layers = append(layers, layer.Name)
+		}
+	}
+	if len(layers) < 2 {
+		return nil
+	}
+	return layers[:len(layers)-1]
+}
+
+// Layers returns the names of the layers in order of precedence, the lowest first
+func (c *Config) Layers() []string {
+	names := make([]string, len(c.layers))
+	for i, layer := range c.layers {
+		names[i] = layer.Name
+	}
+	return names
+}
+
+// Names returns the names of the resolved variables, sorted
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.vars))
+	for name := range c.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Decode populates the struct pointed to by dst from the resolved variables,
+// see env.Expander.Decode. Options configure the expander, e.g. to redact
+// secrets from errors.
+func (c *Config) Decode(dst any, opts ...env.Option) error {
+	opts = append([]env.Option{env.WithProvider(c)}, opts...)
+	return env.NewExpander(opts...).Decode(dst)
+}
+
+// ExportJSON writes the resolved variables to w as JSON, redacting secrets,
+// see env.ExportJSON
+func (c *Config) ExportJSON(w io.Writer, opts ...env.ExportOption) error {
+	return env.ExportJSON(w, c.vars, opts...)
+}
+
+// ExportYAML writes the resolved variables to w as YAML, redacting secrets,
+// see env.ExportYAML
+func (c *Config) ExportYAML(w io.Writer, opts ...env.ExportOption) error {
+	return env.ExportYAML(w, c.vars, opts...)
+}
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	if err := os.WriteFile(base, []byte("DB_HOST=db\nDB_PORT=5432\nDB_USER=app\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(local, []byte("DB_PORT=6543\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := Load(
+		Defaults(map[string]string{"DB_HOST": "localhost", "DB_NAME": "app"}),
+		Files(base),
+		OptionalFiles(local, filepath.Join(dir, "missing.env")),
+		Environ([]string{"DB_USER=admin", "HOME=/root"}),
+		Overrides(map[string]string{"DB_NAME": "test"}),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		value  string
+		source string
+	}{
+		{"DB_HOST", "db", base},
+		{"DB_PORT", "6543", local},
+		{"DB_USER", "admin", LayerEnv},
+		{"DB_NAME", "test", LayerOverrides},
+		{"HOME", "/root", LayerEnv},
+	}
+	for _, tt := range tests {
+		if value, found := c.Lookup(tt.name); !found || value != tt.value {
+			t.Errorf("Lookup(%s) got = %q, %v, want %q", tt.name, value, found, tt.value)
+		}
+		if source, _ := c.Source(tt.name); source != tt.source {
+			t.Errorf("Source(%s) got = %q, want %q", tt.name, source, tt.source)
+		}
+	}
+	if _, found := c.Source("MISSING"); found {
+		t.Error("Source(MISSING) found a layer")
+	}
+
+	if want := []string{LayerDefaults, base, local, LayerEnv, LayerOverrides}; !reflect.DeepEqual(c.Layers(), want) {
+		t.Errorf("Layers() got = %v, want %v", c.Layers(), want)
+	}
+	if want := []string{LayerDefaults}; !reflect.DeepEqual(c.Shadowed("DB_NAME"), want) {
+		t.Errorf("Shadowed(DB_NAME) got = %v, want %v", c.Shadowed("DB_NAME"), want)
+	}
+	if got := c.Shadowed("HOME"); got != nil {
+		t.Errorf("Shadowed(HOME) got = %v, want nil", got)
+	}
+
+	got, err := env.Expand("postgres://${DB_USER}@${DB_HOST}:${DB_PORT}/${DB_NAME}", c)
+	if err != nil || got != "postgres://admin@db:6543/test" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load(Files(filepath.Join(t.TempDir(), ".env"))); err == nil {
+		t.Error("Load() expected an error for a missing file")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	c := New(
+		Layer{Name: LayerDefaults, Vars: map[string]string{"PORT": "8080", "DATA_DIR": "${HOME}/data"}},
+		Layer{Name: LayerEnv, Vars: map[string]string{"HOME": "/home/app"}},
+	)
+	var cfg struct {
+		Port    int
+		DataDir string `env:"DATA_DIR,expand"`
+	}
+	if err := c.Decode(&cfg); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if cfg.Port != 8080 || cfg.DataDir != "/home/app/data" {
+		t.Errorf("Decode() got = %+v", cfg)
+	}
+}
+
+func TestExport(t *testing.T) {
+	c := New(Layer{Name: LayerEnv, Vars: map[string]string{"DB_HOST": "db", "DB_PASSWORD": "hunter2"}})
+	var buf bytes.Buffer
+	if err := c.ExportJSON(&buf); err != nil {
+		t.Fatalf("ExportJSON() error = %v", err)
+	}
+	if want := "{\n  \"DB_HOST\": \"db\",\n  \"DB_PASSWORD\": \"****\"\n}\n"; buf.String() != want {
+		t.Errorf("ExportJSON() got = %q, want %q", buf.String(), want)
+	}
+	buf.Reset()
+	if err := c.ExportYAML(&buf, env.ExportNested("_")); err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+	if want := "db:\n  host: \"db\"\n  password: \"****\"\n"; buf.String() != want {
+		t.Errorf("ExportYAML() got = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestLoadInterpolate(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, ".env")
+	local := filepath.Join(dir, ".env.local")
+	os.WriteFile(base, []byte("HOST=db\nURL=postgres://${HOST}:${PORT:-5432}\nCACHE=${HOME}/cache\nLITERAL='${HOST}'\n"), 0o600)
+	os.WriteFile(local, []byte("HOST=replica\nREPLICA_URL=postgres://${HOST}/${NAME}\n"), 0o600)
+	environ := []string{"HOME=/home/app", "HOST=ignored"}
+
+	c, err := Load(
+		Defaults(map[string]string{"NAME": "app"}),
+		Files(base, local),
+		Environ(environ),
+		Interpolate(),
+	)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for name, want := range map[string]string{
+		"URL":         "postgres://db:5432",
+		"CACHE":       "/home/app/cache",
+		"LITERAL":     "${HOST}",
+		"REPLICA_URL": "postgres://replica/app",
+	} {
+		if value, _ := c.Lookup(name); value != want {
+			t.Errorf("Lookup(%s) got = %q, want %q", name, value, want)
+		}
+	}
+
+	c, err = Load(Files(base), Environ(environ), Interpolate(), Hermetic())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if value, _ := c.Lookup("CACHE"); value != "/cache" {
+		t.Errorf("Lookup(CACHE) got = %q with Hermetic, want /cache", value)
+	}
+
+	os.WriteFile(base, []byte("A=1\nB=${MISSING:?required}\n"), 0o600)
+	if _, err := Load(Files(base), Interpolate()); err == nil {
+		t.Error("Load() expected an error for a failing reference")
+	}
+
+	c, err = Load(Files(local), Environ(nil))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if value, _ := c.Lookup("REPLICA_URL"); value != "postgres://${HOST}/${NAME}" {
+		t.Errorf("Lookup(REPLICA_URL) got = %q without Interpolate", value)
+	}
+}
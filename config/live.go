@@ -0,0 +1,96 @@
+package config
+
+import (
+	"os"
+	"sync"
+
+	env "github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// Live is a Config that can be loaded again, e.g. when the process receives
+// SIGHUP, to pick up the changes made to its .env files. It implements
+// env.Provider, env.Lister, env.Generational and env.Reloader, and is safe for
+// concurrent use.
+type Live struct {
+	opts []Option
+
+	mu         sync.RWMutex
+	current    *Config
+	generation uint64
+	onChange   []func(changes []dotenv.Change)
+}
+
+// LoadLive loads the layers configured by opts like Load, keeping the options
+// to load them again on Reload
+func LoadLive(opts ...Option) (*Live, error) {
+	c, err := Load(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Live{opts: opts, current: c}, nil
+}
+
+// Config returns the current resolution of the layers
+func (l *Live) Config() *Config {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.current
+}
+
+// Lookup returns the current value of name
+func (l *Live) Lookup(name string) (string, bool) {
+	return l.Config().Lookup(name)
+}
+
+// Environ returns the current variables in "key=value" form, sorted by key
+func (l *Live) Environ() []string {
+	return l.Config().Environ()
+}
+
+// Generation returns the number of reloads that changed the variables
+func (l *Live) Generation() uint64 {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.generation
+}
+
+// OnChange registers fn to be called by Reload with the changes of the
+// resolved variables, sorted by name. It is not called when nothing changed.
+func (l *Live) OnChange(fn func(changes []dotenv.Change)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.onChange = append(l.onChange, fn)
+}
+
+// Reload loads the layers again, re-reading the .env files and, unless
+// replaced by Environ, the process environment. On failure, the current
+// resolution is kept.
+func (l *Live) Reload() error {
+	c, err := Load(l.opts...)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	changes := dotenv.DiffVars(l.current.vars, c.vars)
+	l.current = c
+	if len(changes) > 0 {
+		l.generation++
+	}
+	callbacks := l.onChange
+	l.mu.Unlock()
+
+	if len(changes) > 0 {
+		for _, fn := range callbacks {
+			fn(changes)
+		}
+	}
+	return nil
+}
+
+// ReloadOn reloads the layers every time the process receives one of sig,
+// such as syscall.SIGHUP, until the returned function is called, see
+// env.ReloadOn
+func (l *Live) ReloadOn(sig ...os.Signal) (stop func()) {
+	return env.ReloadOn(l, sig...)
+}
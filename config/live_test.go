@@ -0,0 +1,52 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+func TestLive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(path, []byte("HOST=db\nPORT=5432\n"), 0o600)
+
+	l, err := LoadLive(Files(path), Environ([]string{"USER=app"}))
+	if err != nil {
+		t.Fatalf("LoadLive() error = %v", err)
+	}
+	var got [][]dotenv.Change
+	l.OnChange(func(changes []dotenv.Change) {
+		got = append(got, changes)
+	})
+
+	if err := l.Reload(); err != nil || len(got) != 0 || l.Generation() != 0 {
+		t.Errorf("Reload() without changes got = %v, %v, generation %d", err, got, l.Generation())
+	}
+
+	os.WriteFile(path, []byte("HOST=replica\nNAME=app\n"), 0o600)
+	if err := l.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	want := [][]dotenv.Change{{
+		{Key: "HOST", Kind: dotenv.Changed, Old: "db", New: "replica"},
+		{Key: "NAME", Kind: dotenv.Added, New: "app"},
+		{Key: "PORT", Kind: dotenv.Removed, Old: "5432"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reload() changes = %+v, want %+v", got, want)
+	}
+	if value, _ := l.Lookup("HOST"); value != "replica" || l.Generation() != 1 {
+		t.Errorf("Lookup(HOST) got = %q, generation %d after Reload", value, l.Generation())
+	}
+
+	os.Remove(path)
+	if err := l.Reload(); err == nil {
+		t.Error("Reload() expected an error for a missing file")
+	}
+	if value, _ := l.Lookup("HOST"); value != "replica" {
+		t.Errorf("Lookup(HOST) got = %q after a failed Reload, want the previous value", value)
+	}
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+// Profile gathers the resolution rules of an environment, such as
+// development, staging or production
+type Profile struct {
+	Name string
+	// Defaults are the values of the variables set by no other layer
+	Defaults map[string]string
+	// Files are the .env files read in order, which must exist. When neither
+	// Files nor OptionalFiles is set, the optional files .env, .env.NAME,
+	// .env.local and .env.NAME.local are read, NAME being the profile name.
+	Files []string
+	// OptionalFiles are read after Files, skipping the missing ones
+	OptionalFiles []string
+	// Schema, if set, is checked against the resolved variables
+	Schema *env.Schema
+	// Options configure the expansion once the profile is selected. They
+	// are applied after the resolved variables are set as the provider, so a
+	// WithProvider option can replace them, e.g. with a secret store in
+	// production.
+	Options []env.Option
+}
+
+var (
+	profilesMu sync.RWMutex
+	profiles   = make(map[string]Profile)
+	active     string
+)
+
+// RegisterProfile registers p under its name, replacing any profile
+// registered under the same name
+func RegisterProfile(p Profile) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles[p.Name] = p
+}
+
+// SelectProfile loads the variables of the profile registered under name,
+// checks them against its schema and makes them, together with the options of
+// the profile, the defaults of every Expander with env.SetDefault. Package
+// level functions such as env.ExpandEnv and env.DecodeEnv then follow the
+// rules of the profile. It returns the resolved variables.
+func SelectProfile(name string) (*Config, error) {
+	profilesMu.RLock()
+	p, ok := profiles[name]
+	profilesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	c, err := p.Load()
+	if err != nil {
+		return nil, err
+	}
+	env.SetDefault(append([]env.Option{env.WithProvider(c)}, p.Options...)...)
+
+	profilesMu.Lock()
+	active = name
+	profilesMu.Unlock()
+	return c, nil
+}
+
+// ActiveProfile returns the name of the profile last selected with
+// SelectProfile, or "" if none was
+func ActiveProfile() string {
+	profilesMu.RLock()
+	defer profilesMu.RUnlock()
+	return active
+}
+
+// Load reads the layers of the profile and checks them against its schema,
+// without changing the defaults of expansion. Options are applied after the
+// ones of the profile, e.g. to add overrides.
+func (p Profile) Load(opts ...Option) (*Config, error) {
+	files, optional := p.Files, p.OptionalFiles
+	if files == nil && optional == nil {
+		optional = []string{".env", ".env." + p.Name, ".env.local", ".env." + p.Name + ".local"}
+	}
+	opts = append([]Option{Defaults(p.Defaults), Files(files...), OptionalFiles(optional...)}, opts...)
+	c, err := Load(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+	}
+	if p.Schema != nil {
+		if err := p.Schema.Validate(c); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", p.Name, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+func TestSelectProfile(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	os.WriteFile(".env", []byte("DB_HOST=localhost\nLOG_LEVEL=debug\n"), 0o600)
+	os.WriteFile(".env.production", []byte("DB_HOST=db.internal\n"), 0o600)
+	t.Cleanup(func() { env.SetDefault() })
+
+	schema, err := env.ParseSchema([]byte("variables:\n  DB_PORT:\n    type: int\n    required: true\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterProfile(Profile{Name: "development", Defaults: map[string]string{"DB_PORT": "5432"}})
+	RegisterProfile(Profile{
+		Name:     "production",
+		Defaults: map[string]string{"DB_PORT": "5432"},
+		Schema:   schema,
+		Options:  []env.Option{env.WithStrictSyntax()},
+	})
+	RegisterProfile(Profile{Name: "broken", Schema: schema})
+
+	if _, err := SelectProfile("production"); err != nil {
+		t.Fatalf("SelectProfile(production) error = %v", err)
+	}
+	if ActiveProfile() != "production" {
+		t.Errorf("ActiveProfile() got = %q, want production", ActiveProfile())
+	}
+	got, err := env.ExpandEnv("${DB_HOST}:${DB_PORT} ${LOG_LEVEL}")
+	if err != nil || got != "db.internal:5432 debug" {
+		t.Errorf("ExpandEnv() got = %q, %v", got, err)
+	}
+	if _, err := env.ExpandEnv("${DB_HOST"); err == nil {
+		t.Error("ExpandEnv() expected a syntax error with the options of the profile")
+	}
+
+	c, err := SelectProfile("development")
+	if err != nil {
+		t.Fatalf("SelectProfile(development) error = %v", err)
+	}
+	if source, _ := c.Source("DB_HOST"); source != ".env" {
+		t.Errorf("Source(DB_HOST) got = %q, want .env", source)
+	}
+
+	if _, err := SelectProfile("broken"); err == nil {
+		t.Error("SelectProfile(broken) expected a schema error")
+	}
+	if _, err := SelectProfile("missing"); err == nil {
+		t.Error("SelectProfile(missing) expected an error")
+	}
+	if ActiveProfile() != "development" {
+		t.Errorf("ActiveProfile() got = %q after failures, want development", ActiveProfile())
+	}
+}
@@ -0,0 +1,347 @@
+package env
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DecodeEnv populates the struct pointed to by dst from the process
+// environment, see Expander.Decode
+func DecodeEnv(dst any) error {
+	return NewExpander().Decode(dst)
+}
+
+// Decode populates the struct pointed to by dst from the variables of p,
+// see Expander.Decode
+func Decode(dst any, p Provider) error {
+	return NewExpander(WithProvider(p)).Decode(dst)
+}
+
+// Decode populates the exported fields of the struct pointed to by dst from
+// the variables of the expander's provider, leaving the fields of unset
+// variables untouched.
+//
+// A field is read from the variable named by its `env` tag, or by its name
+// converted with ToEnvName, and `env:"-"` skips it. Nested structs and
+// pointers to structs are decoded recursively, their variables prefixed with
+// the name of the field holding them, so that Server.HTTP.Port is read from
+// SERVER_HTTP_PORT. The `env` tag of such a field overrides its part of the
+// prefix, and embedded structs add no prefix unless tagged.
+//
+// The name in the `env` tag may be followed by comma-separated options:
+//   - required: the variable must be set
+//   - notEmpty: the variable must not be empty if set
+//   - expand: the value is expanded before being converted, so that
+//     DATA_DIR=${HOME}/data can be decoded
+//   - secret: the value is a credential and is kept out of errors, logs and
+//     traces; this is implied for fields of type Secret
+//   - default=value: the value to use when the variable is unset, itself
+//     expanded; it must be the last option and may contain commas
+//
+// Strings, booleans, numbers, time.Duration and slices of those, written as
+// comma-separated lists, are supported, as well as types implementing
+// encoding.TextUnmarshaler and types registered with RegisterDecoder. Decode reports the errors of all
+// fields that could not be decoded together.
+func (x *Expander) Decode(dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode destination must be a non-nil pointer to a struct, got %T", dst)
+	}
+	d := &decoder{e: &expander{cfg: &x.cfg, pure: x.cfg.pure}}
+	d.decodeStruct(v.Elem(), "")
+	if len(d.errs) > 0 {
+		return errors.Join(d.errs...)
+	}
+	return d.e.commit()
+}
+
+// decoder holds the state of a single Decode call
+type decoder struct {
+	e    *expander
+	errs []error
+}
+
+// decodeStruct decodes the fields of v, reporting whether any of its
+// variables was set
+func (d *decoder) decodeStruct(v reflect.Value, prefix string) bool {
+	decoded := false
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("env")
+		// The exported fields of embedded structs are settable even if the
+		// embedded type is not
+		embedded := f.Anonymous && f.Type.Kind() == reflect.Struct
+		if !f.IsExported() && !embedded || tag == "-" {
+			continue
+		}
+		name, opts := parseFieldTag(tag)
+		opts.secret = opts.secret || isSecretType(f.Type)
+		fv := v.Field(i)
+
+		if isNested(f.Type) {
+			nestedPrefix := prefix
+			switch {
+			case name != "":
+				nestedPrefix += name + "_"
+			case !f.Anonymous:
+				nestedPrefix += ToEnvName(f.Name) + "_"
+			}
+			if d.decodeNested(fv, nestedPrefix) {
+				decoded = true
+			}
+			continue
+		}
+
+		if name == "" {
+			name = ToEnvName(f.Name)
+		}
+		if d.decodeField(fv, prefix+name, opts) {
+			decoded = true
+		}
+	}
+	return decoded
+}
+
+// decodeNested decodes a struct or pointer to struct field. Nil pointers are
+// only allocated if one of their variables is set.
+func (d *decoder) decodeNested(fv reflect.Value, prefix string) bool {
+	if fv.Kind() != reflect.Pointer {
+		return d.decodeStruct(fv, prefix)
+	}
+	if !fv.IsNil() {
+		return d.decodeStruct(fv.Elem(), prefix)
+	}
+	nested := reflect.New(fv.Type().Elem())
+	if !d.decodeStruct(nested.Elem(), prefix) {
+		return false
+	}
+	fv.Set(nested)
+	return true
+}
+
+// fieldOptions holds the options of an `env` tag
+type fieldOptions struct {
+	required   bool
+	notEmpty   bool
+	expand     bool
+	secret     bool
+	hasDefault bool
+	def        string
+}
+
+// parseFieldTag splits an `env` tag into the variable name and its options
+func parseFieldTag(tag string) (string, fieldOptions) {
+	name, rest, _ := strings.Cut(tag, ",")
+	var opts fieldOptions
+	for rest != "" {
+		if def, ok := strings.CutPrefix(rest, "default="); ok {
+			opts.hasDefault, opts.def = true, def
+			break
+		}
+		var opt string
+		opt, rest, _ = strings.Cut(rest, ",")
+		switch strings.TrimSpace(opt) {
+		case "required":
+			opts.required = true
+		case "notEmpty":
+			opts.notEmpty = true
+		case "expand":
+			opts.expand = true
+		case "secret":
+			opts.secret = true
+		}
+	}
+	return name, opts
+}
+
+// decodeField sets a field from the variable name, reporting whether it was set
+func (d *decoder) decodeField(fv reflect.Value, name string, opts fieldOptions) bool {
+	d.e.secret = opts.secret
+	defer func() { d.e.secret = false }()
+	value, found, err := d.e.lookup(name)
+	if err != nil {
+		d.errs = append(d.errs, err)
+		return false
+	}
+	if found && opts.expand {
+		if value, err = d.e.expand(value); err != nil {
+			d.errs = append(d.errs, fmt.Errorf("failed to expand variable '%s': %w", name, err))
+			return false
+		}
+	}
+	if !found && opts.hasDefault {
+		if value, err = d.e.expand(opts.def); err != nil {
+			d.errs = append(d.errs, fmt.Errorf("failed to expand default of variable '%s': %w", name, err))
+			return false
+		}
+		found = true
+	}
+
+	switch {
+	case !found && opts.required:
+		d.errs = append(d.errs, fmt.Errorf("variable '%s' is required but not set", name))
+		return false
+	case !found:
+		return false
+	case value == "" && opts.notEmpty:
+		d.errs = append(d.errs, fmt.Errorf("variable '%s' must not be empty", name))
+		return false
+	}
+
+	if err := setValue(fv, value); err != nil {
+		if opts.secret {
+			// Parse errors may quote the value
+			err = fmt.Errorf("invalid %s value", fv.Type())
+		}
+		d.errs = append(d.errs, fmt.Errorf("failed to decode variable '%s': %w", name, err))
+	}
+	return true
+}
+
+var secretType = reflect.TypeOf(Secret(""))
+
+// isSecretType reports whether t is Secret or a pointer or slice of it
+func isSecretType(t reflect.Type) bool {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t == secretType
+}
+
+// isNested reports whether fields of type t are decoded recursively
+func isNested(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		if hasDecoder(t) {
+			return false
+		}
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !hasDecoder(t)
+}
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]func(string) (any, error))
+)
+
+// RegisterDecoder registers decode as the function turning variables into
+// values of type t when decoding structs. It takes precedence over the
+// built-in conversions and encoding.TextUnmarshaler, and the values it
+// returns must be assignable to t. For example:
+//
+//	env.RegisterDecoder(reflect.TypeFor[slog.Level](), func(s string) (any, error) {
+//		var level slog.Level
+//		err := level.UnmarshalText([]byte(s))
+//		return level, err
+//	})
+func RegisterDecoder(t reflect.Type, decode func(value string) (any, error)) {
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	decoders[t] = decode
+}
+
+// registeredDecoder returns the function registered for t, if any
+func registeredDecoder(t reflect.Type) func(string) (any, error) {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	return decoders[t]
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// hasDecoder reports whether values of type t are decoded as a whole rather
+// than field by field
+func hasDecoder(t reflect.Type) bool {
+	return registeredDecoder(t) != nil || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+// setValue parses s into v according to the type of v
+func setValue(v reflect.Value, s string) error {
+	if decode := registeredDecoder(v.Type()); decode != nil {
+		value, err := decode(s)
+		if err != nil {
+			return err
+		}
+		rv := reflect.ValueOf(value)
+		if !rv.IsValid() || !rv.Type().AssignableTo(v.Type()) {
+			return fmt.Errorf("decoder registered for %s returned %T", v.Type(), value)
+		}
+		v.Set(rv)
+		return nil
+	}
+	if v.CanAddr() && v.Kind() != reflect.Pointer {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+		v.Set(ptr)
+		return nil
+	}
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		var items []string
+		if s != "" {
+			items = strings.Split(s, ",")
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setValue(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
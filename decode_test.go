@@ -0,0 +1,205 @@
+package env
+
+import (
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testHTTPConfig struct {
+	Port    int
+	Timeout time.Duration
+}
+
+type testLogging struct {
+	Level string `env:"LOG_LEVEL"`
+}
+
+type testConfig struct {
+	Name   string
+	Debug  bool
+	Ratio  float64
+	Hosts  []string
+	Ports  []uint16
+	Server struct {
+		HTTP  testHTTPConfig
+		Admin *testHTTPConfig `env:"ADM"`
+	}
+	Cache   *testHTTPConfig
+	Ignored string `env:"-"`
+	testLogging
+	internal string
+}
+
+func TestDecode(t *testing.T) {
+	vars := Map{
+		"NAME":                     "api",
+		"DEBUG":                    "true",
+		"RATIO":                    "0.5",
+		"HOSTS":                    "a, b",
+		"PORTS":                    "80,443",
+		"SERVER_HTTP_PORT":         "8080",
+		"SERVER_HTTP_TIMEOUT":      "5s",
+		"SERVER_ADM_PORT":          "9090",
+		"IGNORED":                  "x",
+		"LOG_LEVEL":                "debug",
+		"INTERNAL":                 "x",
+		"SERVER_HTTP_UNUSED_FIELD": "x",
+	}
+
+	var cfg testConfig
+	cfg.Ignored = "kept"
+	if err := Decode(&cfg, vars); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if cfg.Name != "api" || !cfg.Debug || cfg.Ratio != 0.5 {
+		t.Errorf("Decode() scalars got = %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) || !reflect.DeepEqual(cfg.Ports, []uint16{80, 443}) {
+		t.Errorf("Decode() slices got = %v, %v", cfg.Hosts, cfg.Ports)
+	}
+	if cfg.Server.HTTP != (testHTTPConfig{Port: 8080, Timeout: 5 * time.Second}) {
+		t.Errorf("Decode() Server.HTTP got = %+v", cfg.Server.HTTP)
+	}
+	if cfg.Server.Admin == nil || cfg.Server.Admin.Port != 9090 {
+		t.Errorf("Decode() Server.Admin got = %+v", cfg.Server.Admin)
+	}
+	if cfg.Cache != nil {
+		t.Errorf("Decode() allocated Cache without variables: %+v", cfg.Cache)
+	}
+	if cfg.Ignored != "kept" || cfg.internal != "" {
+		t.Errorf("Decode() set skipped fields: %q, %q", cfg.Ignored, cfg.internal)
+	}
+	if cfg.Level != "debug" {
+		t.Errorf("Decode() embedded Level got = %q", cfg.Level)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	var cfg testConfig
+	err := Decode(&cfg, Map{"DEBUG": "maybe", "SERVER_HTTP_PORT": "http", "PORTS": "80,x"})
+	if err == nil {
+		t.Fatal("Decode() expected an error")
+	}
+	for _, name := range []string{"'DEBUG'", "'SERVER_HTTP_PORT'", "'PORTS'"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("Decode() error = %v, want it to mention %s", err, name)
+		}
+	}
+
+	if err := Decode(cfg, Map{}); err == nil {
+		t.Error("Decode() expected an error for a non-pointer destination")
+	}
+}
+
+type testLevel int
+
+func TestDecodeCustomTypes(t *testing.T) {
+	RegisterDecoder(reflect.TypeFor[testLevel](), func(s string) (any, error) {
+		switch s {
+		case "low":
+			return testLevel(1), nil
+		case "high":
+			return testLevel(2), nil
+		}
+		return nil, fmt.Errorf("unknown level %q", s)
+	})
+
+	var cfg struct {
+		Addr     netip.Addr
+		Gateway  *netip.Addr
+		Peers    []netip.Addr
+		Since    time.Time
+		LogLevel slog.Level
+		Level    testLevel
+		Levels   []testLevel
+	}
+	err := Decode(&cfg, Map{
+		"ADDR":      "10.0.0.1",
+		"GATEWAY":   "10.0.0.254",
+		"PEERS":     "10.0.0.2,::1",
+		"SINCE":     "2024-01-02T03:04:05Z",
+		"LOG_LEVEL": "warn",
+		"LEVEL":     "high",
+		"LEVELS":    "low,high",
+	})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+
+	if cfg.Addr != netip.MustParseAddr("10.0.0.1") || cfg.Gateway == nil || *cfg.Gateway != netip.MustParseAddr("10.0.0.254") {
+		t.Errorf("Decode() addresses got = %v, %v", cfg.Addr, cfg.Gateway)
+	}
+	if len(cfg.Peers) != 2 || cfg.Peers[1] != netip.IPv6Loopback() {
+		t.Errorf("Decode() Peers got = %v", cfg.Peers)
+	}
+	if !cfg.Since.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) || cfg.LogLevel != slog.LevelWarn {
+		t.Errorf("Decode() got Since = %v, LogLevel = %v", cfg.Since, cfg.LogLevel)
+	}
+	if cfg.Level != 2 || !reflect.DeepEqual(cfg.Levels, []testLevel{1, 2}) {
+		t.Errorf("Decode() got Level = %v, Levels = %v", cfg.Level, cfg.Levels)
+	}
+
+	if err := Decode(&cfg, Map{"ADDR": "nope", "LEVEL": "mid"}); err == nil || !strings.Contains(err.Error(), "'ADDR'") || !strings.Contains(err.Error(), "'LEVEL'") {
+		t.Errorf("Decode() error = %v, want errors for ADDR and LEVEL", err)
+	}
+}
+
+func TestDecodeTagOptions(t *testing.T) {
+	type config struct {
+		Token   string        `env:"TOKEN,required"`
+		Secret  string        `env:"SECRET,required,notEmpty"`
+		Name    string        `env:"NAME,notEmpty"`
+		Timeout time.Duration `env:",default=30s"`
+		URL     string        `env:"URL,default=http://${HOST:-localhost}:8080/a,b"`
+	}
+
+	var cfg config
+	if err := Decode(&cfg, Map{"TOKEN": "t", "SECRET": "s", "HOST": "db"}); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	want := config{Token: "t", Secret: "s", Timeout: 30 * time.Second, URL: "http://db:8080/a,b"}
+	if cfg != want {
+		t.Errorf("Decode() got = %+v, want %+v", cfg, want)
+	}
+
+	err := Decode(&config{}, Map{"SECRET": "", "NAME": "", "TIMEOUT": "1m"})
+	if err == nil {
+		t.Fatal("Decode() expected an error")
+	}
+	for _, msg := range []string{
+		"variable 'TOKEN' is required but not set",
+		"variable 'SECRET' must not be empty",
+		"variable 'NAME' must not be empty",
+	} {
+		if !strings.Contains(err.Error(), msg) {
+			t.Errorf("Decode() error = %v, want it to contain %q", err, msg)
+		}
+	}
+}
+
+func TestDecodeExpand(t *testing.T) {
+	var cfg struct {
+		DataDir string `env:"DATA_DIR,expand"`
+		Raw     string `env:"DATA_DIR"`
+		Limit   int    `env:"LIMIT,expand,default=${DEFAULT_LIMIT}"`
+		Broken  string `env:"BROKEN,expand"`
+	}
+	vars := Map{"HOME": "/home/app", "DATA_DIR": "${HOME}/data", "DEFAULT_LIMIT": "10"}
+	if err := Decode(&cfg, vars); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if cfg.DataDir != "/home/app/data" || cfg.Raw != "${HOME}/data" || cfg.Limit != 10 {
+		t.Errorf("Decode() got = %+v", cfg)
+	}
+
+	vars["BROKEN"] = "${MISSING:?needed}"
+	if err := Decode(&cfg, vars); err == nil || !strings.Contains(err.Error(), "'BROKEN'") {
+		t.Errorf("Decode() error = %v, want an error for BROKEN", err)
+	}
+}
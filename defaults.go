@@ -0,0 +1,79 @@
+package env
+
+import (
+	"slices"
+	"sync"
+)
+
+var (
+	defaultsMu   sync.RWMutex
+	defaultsOpts []Option
+)
+
+// SetDefault replaces the options every Expander starts from, including the
+// ones used by package-level functions such as ExpandEnv and DecodeEnv, so
+// that strictness, the provider or redaction can be configured once at
+// startup. Options given to a function or to NewExpander are applied after
+// the defaults and take precedence. Calling SetDefault without options
+// restores the built-in defaults. It is safe for concurrent use, but does not
+// affect Expanders created before it was called.
+func SetDefault(opts ...Option) {
+	defaultsMu.Lock()
+	defer defaultsMu.Unlock()
+	defaultsOpts = slices.Clone(opts)
+}
+
+// newConfig returns the configuration made of the defaults and opts
+func newConfig(opts ...Option) config {
+	cfg := config{provider: OS}
+	defaultsMu.RLock()
+	for _, opt := range defaultsOpts {
+		opt(&cfg)
+	}
+	defaultsMu.RUnlock()
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// hasDefaults reports whether SetDefault was called with options
+func hasDefaults() bool {
+	defaultsMu.RLock()
+	defer defaultsMu.RUnlock()
+	return len(defaultsOpts) > 0
+}
+
+var (
+	registeredMu sync.RWMutex
+	registered   = make(Map)
+)
+
+// RegisteredDefaults is the Provider of the values registered with
+// RegisterDefault. It implements Lister.
+var RegisteredDefaults Provider = registeredProvider{}
+
+// RegisterDefault declares the value of a variable when no other source sets
+// it, letting libraries provide sane defaults without touching the process
+// environment. Registered values are consulted by every Expander after its
+// provider, aliases included. It is safe for concurrent use and is usually
+// called from init; registering name again replaces its value.
+func RegisterDefault(name, value string) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	registered[name] = value
+}
+
+type registeredProvider struct{}
+
+func (registeredProvider) Lookup(name string) (string, bool) {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return registered.Lookup(name)
+}
+
+func (registeredProvider) Environ() []string {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return registered.Environ()
+}
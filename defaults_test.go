@@ -0,0 +1,94 @@
+package env
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSetDefault(t *testing.T) {
+	defer SetDefault()
+	vars := Map{"HOST": "db"}
+	SetDefault(WithProvider(vars), WithStrictSyntax())
+
+	if got, err := ExpandEnv("${HOST}"); err != nil || got != "db" {
+		t.Errorf("ExpandEnv() got = %q, %v, want the default provider", got, err)
+	}
+	if _, err := ExpandEnv("${BAD-NAME}"); err == nil {
+		t.Error("ExpandEnv() expected a strict syntax error")
+	}
+	if got, err := Expand("${HOST}", Map{"HOST": "other"}); err != nil || got != "other" {
+		t.Errorf("Expand() got = %q, %v, want the explicit provider", got, err)
+	}
+	if got, err := NewExpander(WithProvider(Map{})).Expand("[${HOST}]"); err != nil || got != "[]" {
+		t.Errorf("Expand() got = %q, %v, want options to override defaults", got, err)
+	}
+	var cfg struct{ Host string }
+	if err := DecodeEnv(&cfg); err != nil || cfg.Host != "db" {
+		t.Errorf("DecodeEnv() got = %+v, %v", cfg, err)
+	}
+
+	SetDefault()
+	if _, err := ExpandEnv("${BAD-NAME}"); err != nil {
+		t.Errorf("ExpandEnv() error = %v after restoring the defaults", err)
+	}
+}
+
+func TestSetDefaultConcurrent(t *testing.T) {
+	defer SetDefault()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetDefault(WithProvider(Map{"A": "1"}))
+		}()
+		go func() {
+			defer wg.Done()
+			_, _ = ExpandEnv("$A")
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRegisterDefault(t *testing.T) {
+	t.Cleanup(func() {
+		registeredMu.Lock()
+		delete(registered, "REGISTERED_LEVEL")
+		delete(registered, "REGISTERED_PORT")
+		registeredMu.Unlock()
+	})
+	RegisterDefault("REGISTERED_LEVEL", "info")
+	RegisterDefault("REGISTERED_PORT", "80")
+	RegisterDefault("REGISTERED_PORT", "8080")
+
+	tests := []struct {
+		vars  Map
+		input string
+		want  string
+	}{
+		{Map{}, "$REGISTERED_LEVEL:$REGISTERED_PORT", "info:8080"},
+		{Map{"REGISTERED_LEVEL": "debug"}, "$REGISTERED_LEVEL", "debug"},
+		{Map{"REGISTERED_LEVEL": ""}, "[$REGISTERED_LEVEL]", "[]"},
+		{Map{}, "${REGISTERED_LEVEL:-warn}", "info"},
+	}
+	for _, tt := range tests {
+		if got, err := Expand(tt.input, tt.vars); err != nil || got != tt.want {
+			t.Errorf("Expand(%q) got = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+
+	var cfg struct {
+		Level string `env:"REGISTERED_LEVEL"`
+	}
+	if err := NewExpander(WithProvider(Map{})).Decode(&cfg); err != nil || cfg.Level != "info" {
+		t.Errorf("Decode() got = %+v, %v", cfg, err)
+	}
+	if _, ok := os.LookupEnv("REGISTERED_LEVEL"); ok {
+		t.Error("RegisterDefault() modified the process environment")
+	}
+	if got := RegisteredDefaults.(Lister).Environ(); !slices.Contains(got, "REGISTERED_PORT=8080") {
+		t.Errorf("Environ() got = %v", got)
+	}
+}
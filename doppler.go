@@ -0,0 +1,120 @@
+package env
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// DopplerConfig locates the secrets served by NewDopplerProvider
+type DopplerConfig struct {
+	// APIURL is the address of the Doppler API, https://api.doppler.com by
+	// default
+	APIURL string
+	// Token is a service or personal token with read access to the config
+	Token string
+	// Project and Config select the config to read. Both may be left empty
+	// with a service token, which is scoped to a single config.
+	Project string
+	Config  string
+	// FallbackFile, if set, receives a copy of the secrets after every
+	// successful fetch and is read when Doppler cannot be reached
+	FallbackFile string
+}
+
+// DopplerProvider resolves variables from the secrets of a Doppler config.
+// The secrets are fetched and cached like any HTTPProvider document, so
+// HTTPRefresh and the other HTTP options apply. It is safe for concurrent use.
+type DopplerProvider struct {
+	*HTTPProvider
+	fallback string
+}
+
+// NewDopplerProvider returns a provider serving the secrets of a Doppler
+// config, fetched from its API with the given options.
+//
+// With a fallback file, the secrets are also written to it, readable by the
+// current user only, and served from it when the first fetch fails. With
+// HTTPRefresh, fetching is attempted again once the interval has passed.
+// Writing the file is best-effort.
+func NewDopplerProvider(cfg DopplerConfig, opts ...HTTPOption) *DopplerProvider {
+	apiURL := strings.TrimSuffix(cfg.APIURL, "/")
+	if apiURL == "" {
+		apiURL = "https://api.doppler.com"
+	}
+	query := url.Values{"format": {"json"}}
+	if cfg.Project != "" {
+		query.Set("project", cfg.Project)
+	}
+	if cfg.Config != "" {
+		query.Set("config", cfg.Config)
+	}
+
+	h := NewHTTPProvider(apiURL+"/v3/configs/config/secrets/download?"+query.Encode(), append([]HTTPOption{HTTPBearerToken(cfg.Token)}, opts...)...)
+	d := &DopplerProvider{HTTPProvider: h, fallback: cfg.FallbackFile}
+	h.parse = func(body []byte, _ string) (map[string]string, error) {
+		vars, err := parseDocument(body, "application/json")
+		if err == nil && d.fallback != "" {
+			_ = writeFileAtomic(d.fallback, body, 0o600)
+		}
+		return vars, err
+	}
+	return d
+}
+
+// Lookup returns the value of name, see HTTPProvider.Lookup
+func (d *DopplerProvider) Lookup(name string) (string, bool) {
+	value, found, _ := d.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext returns the value of name, fetching the secrets if needed and
+// reading the fallback file if they cannot be fetched
+func (d *DopplerProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	if err := d.ensureFresh(ctx); err != nil {
+		return "", false, err
+	}
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	value, found := d.vars[name]
+	return value, found, nil
+}
+
+// Prefetch fetches the secrets if they were never fetched or are stale
+func (d *DopplerProvider) Prefetch(ctx context.Context, _ []string) error {
+	return d.ensureFresh(ctx)
+}
+
+// Environ returns the secrets in "key=value" form, sorted by key
+func (d *DopplerProvider) Environ() []string {
+	_ = d.ensureFresh(context.Background())
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return Map(d.vars).Environ()
+}
+
+// ensureFresh fetches the secrets if they are missing or stale, falling back
+// to the fallback file if nothing was fetched yet
+func (d *DopplerProvider) ensureFresh(ctx context.Context) error {
+	err := d.HTTPProvider.ensureFresh(ctx)
+	if err == nil || d.fallback == "" {
+		return err
+	}
+	body, readErr := os.ReadFile(d.fallback)
+	if readErr != nil {
+		return err
+	}
+	vars, parseErr := parseDocument(body, "application/json")
+	if parseErr != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.vars == nil {
+		d.vars = vars
+		d.fetched = d.now()
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package env
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDopplerProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/v3/configs/config/secrets/download" || r.Header.Get("Authorization") != "Bearer dp.st.token" ||
+			q.Get("project") != "api" || q.Get("config") != "prd" || q.Get("format") != "json" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprint(w, `{"DB_PASSWORD": "hunter2", "PORT": "8080"}`)
+	}))
+	defer server.Close()
+
+	fallback := filepath.Join(t.TempDir(), "doppler.json")
+	p := NewDopplerProvider(DopplerConfig{
+		APIURL:       server.URL,
+		Token:        "dp.st.token",
+		Project:      "api",
+		Config:       "prd",
+		FallbackFile: fallback,
+	}, HTTPClient(server.Client()))
+
+	for name, want := range map[string]string{"DB_PASSWORD": "hunter2", "PORT": "8080"} {
+		if value, found := p.Lookup(name); !found || value != want {
+			t.Errorf("Lookup(%s) got = %q, %v, want %q", name, value, found, want)
+		}
+	}
+	info, err := os.Stat(fallback)
+	if err != nil {
+		t.Fatalf("fallback file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 && os.PathSeparator == '/' {
+		t.Errorf("fallback file mode = %v, want it private", perm)
+	}
+
+	bad := NewDopplerProvider(DopplerConfig{APIURL: server.URL, Token: "wrong"}, HTTPClient(server.Client()))
+	if err := bad.Refresh(t.Context()); err == nil {
+		t.Error("Refresh() expected an error for a rejected token")
+	}
+}
+
+func TestDopplerProviderFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	fallback := filepath.Join(t.TempDir(), "doppler.json")
+	cfg := DopplerConfig{APIURL: server.URL, Token: "dp.st.token", FallbackFile: fallback}
+
+	p := NewDopplerProvider(cfg, HTTPClient(server.Client()))
+	if _, _, err := p.LookupContext(t.Context(), "DB_PASSWORD"); err == nil {
+		t.Error("LookupContext() expected an error without a fallback file")
+	}
+
+	if err := os.WriteFile(fallback, []byte(`{"DB_PASSWORD": "cached"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p = NewDopplerProvider(cfg, HTTPClient(server.Client()))
+	value, found, err := p.LookupContext(t.Context(), "DB_PASSWORD")
+	if err != nil || !found || value != "cached" {
+		t.Errorf("LookupContext() got = %q, %v, %v, want %q from the fallback file", value, found, err, "cached")
+	}
+	if got := p.Environ(); len(got) != 1 || got[0] != "DB_PASSWORD=cached" {
+		t.Errorf("Environ() got = %v", got)
+	}
+}
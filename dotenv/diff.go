@@ -0,0 +1,71 @@
+package dotenv
+
+import "sort"
+
+// ChangeKind describes how a variable differs between two environments
+type ChangeKind int
+
+const (
+	Added ChangeKind = iota
+	Removed
+	Changed
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Changed:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// Change is a difference between two environments
+type Change struct {
+	Key  string
+	Kind ChangeKind
+	// Old is the value in the first environment, empty if the key was added
+	Old string
+	// New is the value in the second environment, empty if the key was removed
+	New string
+}
+
+// Diff reports the differences between the .env files fileA and fileB,
+// sorted by key
+func Diff(fileA, fileB string) ([]Change, error) {
+	a, err := ReadFile(fileA)
+	if err != nil {
+		return nil, err
+	}
+	b, err := ReadFile(fileB)
+	if err != nil {
+		return nil, err
+	}
+	return DiffVars(a, b), nil
+}
+
+// DiffVars reports the differences between the variables of a and b, sorted by key
+func DiffVars(a, b map[string]string) []Change {
+	var changes []Change
+	for key, old := range a {
+		current, ok := b[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: key, Kind: Removed, Old: old})
+		case current != old:
+			changes = append(changes, Change{Key: key, Kind: Changed, Old: old, New: current})
+		}
+	}
+	for key, current := range b {
+		if _, ok := a[key]; !ok {
+			changes = append(changes, Change{Key: key, Kind: Added, New: current})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Key < changes[j].Key
+	})
+	return changes
+}
@@ -0,0 +1,33 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	fileA := filepath.Join(dir, "a.env")
+	fileB := filepath.Join(dir, "b.env")
+	os.WriteFile(fileA, []byte("SAME=1\nCHANGED=old\nREMOVED=x\n"), 0o600)
+	os.WriteFile(fileB, []byte("SAME=1\nCHANGED=new\nADDED=y\n"), 0o600)
+
+	got, err := Diff(fileA, fileB)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	want := []Change{
+		{Key: "ADDED", Kind: Added, New: "y"},
+		{Key: "CHANGED", Kind: Changed, Old: "old", New: "new"},
+		{Key: "REMOVED", Kind: Removed, Old: "x"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() got = %v, want %v", got, want)
+	}
+
+	if _, err := Diff(fileA, filepath.Join(dir, "missing.env")); err == nil {
+		t.Error("Diff() expected an error for a missing file")
+	}
+}
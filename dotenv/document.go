@@ -0,0 +1,202 @@
+package dotenv
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// Document is an editable model of a .env file. It keeps every line,
+// including comments, blank lines and lines it does not understand, so that
+// writing it back only changes the lines of the variables that were edited.
+type Document struct {
+	lines []docLine
+	// unterminated is set when the last line had no line break
+	unterminated bool
+}
+
+// docLine is a line of a Document. Lines without a variable only keep raw.
+type docLine struct {
+	raw   string
+	entry *entry
+}
+
+// ParseDocument reads a Document from r
+func ParseDocument(r io.Reader) (*Document, error) {
+	d := &Document{}
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadString('\n')
+		if line != "" {
+			d.lines = append(d.lines, newDocLine(strings.TrimSuffix(line, "\n")))
+		}
+		if err == io.EOF {
+			d.unterminated = line != ""
+			return d, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// ReadDocument reads the Document stored in the file with the given name
+func ReadDocument(filename string) (*Document, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseDocument(f)
+}
+
+func newDocLine(raw string) docLine {
+	if e, ok := splitLine(raw); ok {
+		return docLine{raw: raw, entry: &e}
+	}
+	return docLine{raw: raw}
+}
+
+// Get returns the value of key. If the key is declared several times, the
+// last declaration wins, as in Parse.
+func (d *Document) Get(key string) (string, bool) {
+	if i := d.find(key); i != -1 {
+		return d.lines[i].entry.value, true
+	}
+	return "", false
+}
+
+// Set changes the value of key, rewriting its last declaration in place and
+// keeping its "export " prefix and trailing comment. Keys that are not
+// declared yet are appended at the end of the document.
+func (d *Document) Set(key, value string) {
+	i := d.find(key)
+	if i == -1 {
+		e := entry{head: key + "=", key: key}
+		d.lines = append(d.lines, docLine{entry: &e})
+		d.unterminated = false
+		i = len(d.lines) - 1
+	}
+	e := *d.lines[i].entry
+	if e.value == value && d.lines[i].raw != "" {
+		return // keep the original quoting
+	}
+	e.value = value
+	d.lines[i] = docLine{raw: e.head + Quote(value) + e.tail, entry: &e}
+}
+
+// Unset removes every declaration of key
+func (d *Document) Unset(key string) {
+	lines := d.lines[:0]
+	for _, l := range d.lines {
+		if l.entry == nil || l.entry.key != key {
+			lines = append(lines, l)
+		}
+	}
+	d.lines = lines
+}
+
+// Keys returns the declared keys in the order of their first declaration
+func (d *Document) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, l := range d.lines {
+		if l.entry != nil && !seen[l.entry.key] {
+			seen[l.entry.key] = true
+			keys = append(keys, l.entry.key)
+		}
+	}
+	return keys
+}
+
+// Line describes a line of a Document
+type Line struct {
+	// Number is the 1-based line number
+	Number int
+	// Raw is the text of the line, without its line break
+	Raw string
+	// IsVar reports whether the line declares a variable
+	IsVar bool
+	// Key and Value are the declared variable, with the value unquoted
+	Key   string
+	Value string
+	// RawValue is the value as written, quotes included
+	RawValue string
+	// Err explains why a line that is neither blank nor a comment declares
+	// no variable
+	Err *ParseError
+}
+
+// Lines returns a description of every line of the document
+func (d *Document) Lines() []Line {
+	lines := make([]Line, len(d.lines))
+	for i, l := range d.lines {
+		lines[i] = Line{Number: i + 1, Raw: l.raw}
+		if e := l.entry; e != nil {
+			lines[i].IsVar = true
+			lines[i].Key = e.key
+			lines[i].Value = e.value
+			lines[i].RawValue = strings.TrimSpace(l.raw[len(e.head) : len(l.raw)-len(e.tail)])
+		} else if err := diagnose(l.raw); err != nil {
+			err.Line = i + 1
+			lines[i].Err = err
+		}
+	}
+	return lines
+}
+
+// Vars returns the variables of the document, like Parse would
+func (d *Document) Vars() map[string]string {
+	vars := make(map[string]string)
+	for _, l := range d.lines {
+		if l.entry != nil {
+			vars[l.entry.key] = l.entry.value
+		}
+	}
+	return vars
+}
+
+// WriteTo writes the document to w
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, d.String())
+	return int64(n), err
+}
+
+// String returns the contents of the document
+func (d *Document) String() string {
+	var b strings.Builder
+	for i, l := range d.lines {
+		b.WriteString(l.raw)
+		if i < len(d.lines)-1 || !d.unterminated {
+			b.WriteByte('\n')
+		}
+	}
+	return b.String()
+}
+
+// find returns the index of the last declaration of key, or -1
+func (d *Document) find(key string) int {
+	for i := len(d.lines) - 1; i >= 0; i-- {
+		if e := d.lines[i].entry; e != nil && e.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// Quote returns value in the form it is written in a .env file: unquoted if
+// it can be, single-quoted if it has no single quote or line break, and
+// double-quoted with escapes otherwise
+func Quote(value string) string {
+	if value == "" {
+		return ""
+	}
+	if !strings.ContainsAny(value, " \t\r\n#'\"\\$`") {
+		return value
+	}
+	if !strings.ContainsAny(value, "'\r\n") {
+		return "'" + value + "'"
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(value) + `"`
+}
@@ -0,0 +1,107 @@
+package dotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	input := `# database settings
+export DB_HOST=localhost # local only
+DB_PORT="5432"
+
+not a variable
+DB_USER=admin
+DB_USER=root
+OLD=remove me
+`
+	d, err := ParseDocument(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseDocument() error = %v", err)
+	}
+
+	if got := d.String(); got != input {
+		t.Errorf("unmodified document got = %q, want %q", got, input)
+	}
+	if got, _ := d.Get("DB_USER"); got != "root" {
+		t.Errorf("Get() got = %v, want the last declaration", got)
+	}
+
+	d.Set("DB_HOST", "db.internal")
+	d.Set("DB_PORT", "5432")
+	d.Set("DB_USER", "it's me")
+	d.Set("NEW_KEY", "line1\nline2")
+	d.Unset("OLD")
+
+	want := `# database settings
+export DB_HOST=db.internal # local only
+DB_PORT="5432"
+
+not a variable
+DB_USER=admin
+DB_USER="it's me"
+NEW_KEY="line1\nline2"
+`
+	if got := d.String(); got != want {
+		t.Errorf("edited document got:\n%s\nwant:\n%s", got, want)
+	}
+
+	reparsed, err := Parse(strings.NewReader(d.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, value := range d.Vars() {
+		if reparsed[key] != value {
+			t.Errorf("round trip of %s got = %q, want %q", key, reparsed[key], value)
+		}
+	}
+
+	if keys := strings.Join(d.Keys(), ","); keys != "DB_HOST,DB_PORT,DB_USER,NEW_KEY" {
+		t.Errorf("Keys() got = %v", keys)
+	}
+}
+
+func TestDocumentLines(t *testing.T) {
+	d, err := ParseDocument(strings.NewReader("# comment\nexport A = 'quoted value' # note\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := d.Lines()
+	if len(lines) != 2 || lines[0].IsVar {
+		t.Fatalf("Lines() got = %+v", lines)
+	}
+	want := Line{Number: 2, Raw: "export A = 'quoted value' # note", IsVar: true, Key: "A", Value: "quoted value", RawValue: "'quoted value'"}
+	if lines[1] != want {
+		t.Errorf("Lines() got = %+v, want %+v", lines[1], want)
+	}
+}
+
+func TestDocumentWithoutFinalNewline(t *testing.T) {
+	d, err := ParseDocument(strings.NewReader("A=1\r\nB=2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := d.String(); got != "A=1\r\nB=2" {
+		t.Errorf("String() got = %q", got)
+	}
+	d.Set("A", "3")
+	if got := d.String(); got != "A=3\r\nB=2" {
+		t.Errorf("String() after Set got = %q", got)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for value, want := range map[string]string{
+		"":           "",
+		"plain":      "plain",
+		"with space": "'with space'",
+		"$literal":   "'$literal'",
+		`it's`:       `"it's"`,
+		"a\nb":       `"a\nb"`,
+		`back\slash`: `'back\slash'`,
+	} {
+		if got := Quote(value); got != want {
+			t.Errorf("Quote(%q) got = %v, want %v", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+// Package dotenv reads variables from .env files.
+package dotenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Parse reads KEY=VALUE pairs from r in the .env format:
+// - blank lines and lines starting with '#' are ignored
+// - an optional "export " prefix before the key is ignored
+// - single-quoted values are taken literally
+// - double-quoted values support the \n, \r, \t, \" and \\ escapes
+// - unquoted values are trimmed and end at a " #" comment
+// Lines that are not in the KEY=VALUE form are skipped, unless Strict is given.
+func Parse(r io.Reader, opts ...ParseOption) (map[string]string, error) {
+	return parse(r, "", opts)
+}
+
+// ReadFile parses the .env file with the given name
+func ReadFile(filename string, opts ...ParseOption) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parse(f, filename, opts)
+}
+
+// ParseOption configures Parse and ReadFile
+type ParseOption func(*parseConfig)
+
+// parseConfig holds the settings of a parse
+type parseConfig struct {
+	strict     bool
+	warnings   *[]*ParseError
+	duplicates DuplicatePolicy
+}
+
+// Strict rejects malformed lines instead of skipping them: parsing fails
+// with the *ParseError of every malformed line, joined
+func Strict() ParseOption {
+	return func(c *parseConfig) {
+		c.strict = true
+	}
+}
+
+// CollectWarnings appends to warnings a *ParseError for every malformed line
+// skipped while parsing
+func CollectWarnings(warnings *[]*ParseError) ParseOption {
+	return func(c *parseConfig) {
+		c.warnings = warnings
+	}
+}
+
+// ParseError describes a malformed line of a .env file
+type ParseError struct {
+	// File is the name of the file, empty when parsing a reader
+	File string
+	// Line and Column are 1-based, the column counting bytes
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *ParseError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Msg)
+}
+
+// ParseStream reads KEY=VALUE pairs from r like Parse, calling fn for each
+// of them in order instead of collecting them, so that files of any size are
+// read with constant memory. Keys declared several times are passed to fn
+// every time, regardless of OnDuplicate. Parsing stops at the first error
+// returned by fn, which is returned as is.
+func ParseStream(r io.Reader, fn func(key, value string) error, opts ...ParseOption) error {
+	return stream(r, "", newParseConfig(opts), func(key, value string, _, _ int) error {
+		return fn(key, value)
+	})
+}
+
+func parse(r io.Reader, filename string, opts []ParseOption) (map[string]string, error) {
+	m, err := parseOrdered(r, filename, opts)
+	if err != nil {
+		return nil, err
+	}
+	return m.Map(), nil
+}
+
+func newParseConfig(opts []ParseOption) *parseConfig {
+	c := &parseConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// maxLineSize is the length above which a line fails to parse, generous
+// enough for certificates and JSON documents held in a value
+const maxLineSize = 16 << 20
+
+// stream parses r line by line, passing the variables to fn with the line and
+// column of their key
+func stream(r io.Reader, filename string, c *parseConfig, fn func(key, value string, line, column int) error) error {
+	var errs []error
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxLineSize)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if key, value, ok := parseLine(line); ok {
+			if err := fn(key, value, n, strings.Index(line, key)+1); err != nil {
+				return err
+			}
+			continue
+		}
+		perr := diagnose(line)
+		if perr == nil {
+			continue
+		}
+		perr.File, perr.Line = filename, n
+		switch {
+		case c.strict:
+			errs = append(errs, perr)
+		case c.warnings != nil:
+			*c.warnings = append(*c.warnings, perr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// parseLine parses a single line, reporting false if it holds no variable
+func parseLine(line string) (string, string, bool) {
+	e, ok := splitLine(line)
+	return e.key, e.value, ok
+}
+
+// entry is a line holding a variable, split so it can be rewritten in place
+type entry struct {
+	head  string // everything up to the value: indentation, "export ", key and '='
+	key   string
+	value string // the unquoted value
+	tail  string // everything after the value, such as a trailing comment
+}
+
+// splitLine splits a line holding a variable into its parts
+func splitLine(line string) (entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' {
+		return entry{}, false
+	}
+
+	idx := strings.IndexByte(line, '=')
+	if idx == -1 {
+		return entry{}, false
+	}
+	key := strings.TrimSpace(line[:idx])
+	key = strings.TrimSpace(strings.TrimPrefix(key, "export "))
+	if !isValidKey(key) {
+		return entry{}, false
+	}
+
+	rest := line[idx+1:]
+	start := len(rest) - len(strings.TrimLeft(rest, " \t"))
+	value, n, ok := parseValue(rest[start:])
+	if !ok {
+		return entry{}, false
+	}
+	return entry{
+		head:  line[:idx+1+start],
+		key:   key,
+		value: value,
+		tail:  rest[start+n:],
+	}, true
+}
+
+// parseValue unquotes the value at the start of raw, returning it with the
+// number of bytes of raw it spans
+func parseValue(raw string) (string, int, bool) {
+	if raw == "" {
+		return "", 0, true
+	}
+
+	switch raw[0] {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end == -1 {
+			return "", 0, false
+		}
+		return raw[1 : end+1], end + 2, true
+
+	case '"':
+		var value strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch c := raw[i]; c {
+			case '"':
+				return value.String(), i + 1, true
+			case '\\':
+				if i+1 >= len(raw) {
+					return "", 0, false
+				}
+				i++
+				switch raw[i] {
+				case 'n':
+					value.WriteByte('\n')
+				case 'r':
+					value.WriteByte('\r')
+				case 't':
+					value.WriteByte('\t')
+				case '"', '\\':
+					value.WriteByte(raw[i])
+				default:
+					value.WriteByte('\\')
+					value.WriteByte(raw[i])
+				}
+			default:
+				value.WriteByte(c)
+			}
+		}
+		return "", 0, false // unterminated quote
+	}
+
+	end := len(raw)
+	if idx := strings.Index(raw, " #"); idx != -1 {
+		end = idx
+	}
+	value := strings.TrimRight(raw[:end], " \t\r")
+	return value, len(value), true
+}
+
+// diagnose explains why line holds no variable, returning nil for blank
+// lines and comments. The line number is left to the caller.
+func diagnose(line string) *ParseError {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || trimmed[0] == '#' {
+		return nil
+	}
+
+	keyStart := len(line) - len(strings.TrimLeft(line, " \t"))
+	if rest, ok := strings.CutPrefix(line[keyStart:], "export "); ok {
+		keyStart = len(line) - len(strings.TrimLeft(rest, " \t"))
+	}
+	idx := strings.IndexByte(line, '=')
+	if idx == -1 {
+		return &ParseError{Column: len(strings.TrimRight(line, " \t\r")) + 1, Msg: "missing '=' after key"}
+	}
+	if key := strings.TrimSpace(line[keyStart:idx]); !isValidKey(key) {
+		if idx < keyStart {
+			keyStart = idx
+		}
+		return &ParseError{Column: keyStart + 1, Msg: fmt.Sprintf("invalid variable name %q", key)}
+	}
+	rest := line[idx+1:]
+	start := idx + 1 + len(rest) - len(strings.TrimLeft(rest, " \t"))
+	return &ParseError{Column: start + 1, Msg: "unterminated quoted value"}
+}
+
+// isValidKey reports whether key is a valid variable name:
+// a letter or underscore followed by letters, digits, underscores or dots
+func isValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case i > 0 && (c >= '0' && c <= '9' || c == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
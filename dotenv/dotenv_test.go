@@ -0,0 +1,122 @@
+package dotenv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	input := `# comment line
+PLAIN=value
+export EXPORTED=yes
+  SPACED = trimmed value  
+COMMENTED=value # trailing comment
+SINGLE='literal $VAR \n'
+DOUBLE="line\nbreak \"quoted\""
+EMPTY=
+invalid line
+1INVALID=value
+UNTERMINATED="oops
+`
+	want := map[string]string{
+		"PLAIN":     "value",
+		"EXPORTED":  "yes",
+		"SPACED":    "trimmed value",
+		"COMMENTED": "value",
+		"SINGLE":    `literal $VAR \n`,
+		"DOUBLE":    "line\nbreak \"quoted\"",
+		"EMPTY":     "",
+	}
+
+	got, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() got = %v, want %v", got, want)
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	input := "OK=1\ninvalid line\n1INVALID=value\n  export 2X=y\nUNTERMINATED=  \"oops\n# comment\n"
+	want := []string{
+		".env:2:13: missing '=' after key",
+		`.env:3:1: invalid variable name "1INVALID"`,
+		`.env:4:10: invalid variable name "2X"`,
+		".env:5:16: unterminated quoted value",
+	}
+
+	_, err := parse(strings.NewReader(input), ".env", []ParseOption{Strict()})
+	if err == nil {
+		t.Fatal("Parse() expected an error in strict mode")
+	}
+	if got := strings.Split(err.Error(), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() error = %q, want %q", got, want)
+	}
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 || perr.Column != 13 {
+		t.Errorf("Parse() error = %#v, want a *ParseError for line 2", perr)
+	}
+
+	var warnings []*ParseError
+	got, err := Parse(strings.NewReader(input), CollectWarnings(&warnings))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, map[string]string{"OK": "1"}) {
+		t.Errorf("Parse() got = %v", got)
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Parse() warnings = %v, want %d", warnings, len(want))
+	}
+	for i, w := range warnings {
+		if w.Error() != strings.TrimPrefix(want[i], ".env:") {
+			t.Errorf("warning %d = %q, want %q", i, w.Error(), strings.TrimPrefix(want[i], ".env:"))
+		}
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	input := "A=1\n# comment\nB='two'\nA=3\nbad\nC=4\n"
+	var got []string
+	err := ParseStream(strings.NewReader(input), func(key, value string) error {
+		got = append(got, key+"="+value)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ParseStream() error = %v", err)
+	}
+	if want := []string{"A=1", "B=two", "A=3", "C=4"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStream() got = %q, want %q", got, want)
+	}
+
+	stop := errors.New("stop")
+	got = nil
+	err = ParseStream(strings.NewReader(input), func(key, value string) error {
+		got = append(got, key)
+		if key == "B" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop || len(got) != 2 {
+		t.Errorf("ParseStream() error = %v after %q, want the error of fn after B", err, got)
+	}
+
+	if err := ParseStream(strings.NewReader(input), func(string, string) error { return nil }, Strict()); err == nil {
+		t.Error("ParseStream() expected an error in strict mode")
+	}
+}
+
+func TestParseLongLine(t *testing.T) {
+	long := strings.Repeat("QUJD", 100<<10)
+	got, err := Parse(strings.NewReader("CERT=" + long + "\nNEXT=1\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got["CERT"] != long || got["NEXT"] != "1" {
+		t.Errorf("Parse() got CERT of length %d and NEXT = %q", len(got["CERT"]), got["NEXT"])
+	}
+}
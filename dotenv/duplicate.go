@@ -0,0 +1,112 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// DuplicatePolicy decides the value of a key declared more than once, within
+// a file or across the files given to ReadFiles
+type DuplicatePolicy int
+
+const (
+	// LastWins keeps the value of the last declaration. It is the default.
+	LastWins DuplicatePolicy = iota
+	// FirstWins keeps the value of the first declaration
+	FirstWins
+	// ErrorOnDuplicate fails with a *ParseError for every redeclaration
+	ErrorOnDuplicate
+	// CollectAll keeps the value of the last declaration like LastWins, and
+	// records every value, returned by OrderedMap.Values
+	CollectAll
+)
+
+// OnDuplicate sets the policy applied to keys declared more than once
+func OnDuplicate(policy DuplicatePolicy) ParseOption {
+	return func(c *parseConfig) {
+		c.duplicates = policy
+	}
+}
+
+// ReadFiles parses the .env files with the given names into a single map, in
+// order, applying the OnDuplicate policy to the keys declared in several files
+// as well as to the ones repeated within a file. Errors of all files are joined.
+func ReadFiles(filenames []string, opts ...ParseOption) (*OrderedMap, error) {
+	c := newParseConfig(opts)
+	m := &OrderedMap{}
+	var errs []error
+	for _, filename := range filenames {
+		if err := m.readFile(filename, c); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return m, nil
+}
+
+// readFile adds the variables of the file with the given name to m
+func (m *OrderedMap) readFile(filename string, c *parseConfig) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return m.parse(f, filename, c)
+}
+
+// declaration is the position of the first declaration of a key
+type declaration struct {
+	file string
+	line int
+}
+
+func (d declaration) String() string {
+	if d.file == "" {
+		return fmt.Sprintf("line %d", d.line)
+	}
+	return fmt.Sprintf("%s:%d", d.file, d.line)
+}
+
+// add sets key to value as declared at the given position, applying the
+// duplicate policy of c
+func (m *OrderedMap) add(c *parseConfig, key, value, file string, line, column int) error {
+	if m.declared == nil {
+		m.declared = make(map[string]declaration)
+	}
+	first, dup := m.declared[key]
+	if !dup {
+		m.declared[key] = declaration{file: file, line: line}
+	}
+	switch {
+	case !dup:
+	case c.duplicates == FirstWins:
+		return nil
+	case c.duplicates == ErrorOnDuplicate:
+		return &ParseError{File: file, Line: line, Column: column, Msg: fmt.Sprintf("key %s is already declared at %s", key, first)}
+	case c.duplicates == CollectAll:
+		if m.all == nil {
+			m.all = make(map[string][]string)
+		}
+		if _, ok := m.all[key]; !ok {
+			m.all[key] = []string{m.values[key]}
+		}
+		m.all[key] = append(m.all[key], value)
+	}
+	m.Set(key, value)
+	return nil
+}
+
+// Values returns every value declared for key, in order, if parsed with the
+// CollectAll policy, and its current value otherwise
+func (m *OrderedMap) Values(key string) []string {
+	if values, ok := m.all[key]; ok {
+		return append([]string(nil), values...)
+	}
+	if value, ok := m.values[key]; ok {
+		return []string{value}
+	}
+	return nil
+}
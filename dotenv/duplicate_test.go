@@ -0,0 +1,87 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOnDuplicate(t *testing.T) {
+	input := "A=1\nB=2\nA=3\n"
+	tests := []struct {
+		name    string
+		policy  DuplicatePolicy
+		want    map[string]string
+		wantErr string
+	}{
+		{"last wins", LastWins, map[string]string{"A": "3", "B": "2"}, ""},
+		{"first wins", FirstWins, map[string]string{"A": "1", "B": "2"}, ""},
+		{"error", ErrorOnDuplicate, nil, "3:1: key A is already declared at line 1"},
+		{"collect all", CollectAll, map[string]string{"A": "3", "B": "2"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(input), OnDuplicate(tt.policy))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("Parse() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	m, err := ParseOrdered(strings.NewReader(input), OnDuplicate(CollectAll))
+	if err != nil {
+		t.Fatalf("ParseOrdered() error = %v", err)
+	}
+	if want := []string{"1", "3"}; !reflect.DeepEqual(m.Values("A"), want) {
+		t.Errorf("Values(A) got = %v, want %v", m.Values("A"), want)
+	}
+	if want := []string{"2"}; !reflect.DeepEqual(m.Values("B"), want) {
+		t.Errorf("Values(B) got = %v, want %v", m.Values("B"), want)
+	}
+	if got := m.Values("C"); got != nil {
+		t.Errorf("Values(C) got = %v, want nil", got)
+	}
+}
+
+func TestReadFiles(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.env"), filepath.Join(dir, "b.env")
+	os.WriteFile(a, []byte("HOST=a\nPORT=1\n"), 0o600)
+	os.WriteFile(b, []byte("NAME=b\nHOST=b\n"), 0o600)
+
+	m, err := ReadFiles([]string{a, b})
+	if err != nil {
+		t.Fatalf("ReadFiles() error = %v", err)
+	}
+	if want := []string{"HOST=b", "PORT=1", "NAME=b"}; !reflect.DeepEqual(m.Environ(), want) {
+		t.Errorf("ReadFiles() got = %q, want %q", m.Environ(), want)
+	}
+
+	m, err = ReadFiles([]string{a, b}, OnDuplicate(FirstWins))
+	if err != nil {
+		t.Fatalf("ReadFiles() error = %v", err)
+	}
+	if value, _ := m.Get("HOST"); value != "a" {
+		t.Errorf("ReadFiles() with FirstWins got HOST = %q, want a", value)
+	}
+
+	_, err = ReadFiles([]string{a, b}, OnDuplicate(ErrorOnDuplicate))
+	if want := b + ":2:1: key HOST is already declared at " + a + ":1"; err == nil || err.Error() != want {
+		t.Errorf("ReadFiles() error = %v, want %q", err, want)
+	}
+
+	if _, err := ReadFiles([]string{a, filepath.Join(dir, "missing.env")}); err == nil {
+		t.Error("ReadFiles() expected an error for a missing file")
+	}
+}
@@ -0,0 +1,129 @@
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FormatOption configures Format
+type FormatOption func(*formatConfig)
+
+type formatConfig struct {
+	sortKeys    bool
+	stripExport bool
+}
+
+// SortKeys orders variables alphabetically. Comment lines directly above a
+// variable move along with it.
+func SortKeys() FormatOption {
+	return func(c *formatConfig) {
+		c.sortKeys = true
+	}
+}
+
+// StripExport removes "export " prefixes
+func StripExport() FormatOption {
+	return func(c *formatConfig) {
+		c.stripExport = true
+	}
+}
+
+// formatBlock is a variable with the comment lines directly above it
+type formatBlock struct {
+	key   string
+	lines []string
+}
+
+// Format returns src in canonical form: variables are written as KEY=value
+// with canonical quoting (see Quote), surrounding whitespace is removed and
+// runs of blank lines are collapsed. Lines that are neither variables,
+// comments nor blank are reported as errors instead of being dropped.
+func Format(src []byte, opts ...FormatOption) ([]byte, error) {
+	cfg := &formatConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	d, err := ParseDocument(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+
+	var header []string // lines before the first variable, when sorting
+	var blocks []formatBlock
+	var pending []string // comment lines not attached to a variable yet
+	for i, l := range d.lines {
+		trimmed := strings.TrimSpace(l.raw)
+		switch {
+		case l.entry != nil:
+			blocks = append(blocks, formatBlock{
+				key:   l.entry.key,
+				lines: append(pending, cfg.formatEntry(l.entry)),
+			})
+			pending = nil
+		case trimmed == "":
+			if cfg.sortKeys {
+				if len(blocks) == 0 {
+					header = append(header, pending...)
+					header = append(header, "")
+				} else {
+					blocks[len(blocks)-1].lines = append(blocks[len(blocks)-1].lines, pending...)
+				}
+				pending = nil
+				continue
+			}
+			pending = append(pending, "")
+		case trimmed[0] == '#':
+			pending = append(pending, trimmed)
+		default:
+			return nil, fmt.Errorf("line %d: invalid line %q", i+1, trimmed)
+		}
+	}
+
+	if cfg.sortKeys {
+		sort.SliceStable(blocks, func(i, j int) bool {
+			return blocks[i].key < blocks[j].key
+		})
+	}
+
+	var lines []string
+	lines = append(lines, header...)
+	for _, b := range blocks {
+		lines = append(lines, b.lines...)
+	}
+	lines = append(lines, pending...)
+
+	var out bytes.Buffer
+	blank := true // drop leading blank lines
+	for _, line := range lines {
+		if line == "" {
+			if blank {
+				continue
+			}
+			blank = true
+		} else {
+			blank = false
+		}
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	result := bytes.TrimRight(out.Bytes(), "\n")
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return append(result, '\n'), nil
+}
+
+// formatEntry writes a variable in canonical form
+func (c *formatConfig) formatEntry(e *entry) string {
+	line := e.key + "=" + Quote(e.value)
+	if !c.stripExport && strings.HasPrefix(strings.TrimSpace(e.head), "export ") {
+		line = "export " + line
+	}
+	if comment := strings.TrimSpace(e.tail); strings.HasPrefix(comment, "#") {
+		line += " " + comment
+	}
+	return line
+}
@@ -0,0 +1,68 @@
+package dotenv
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	src := `
+
+# service
+  export   ZETA = "plain"   # inline
+ALPHA='needs quoting'
+
+
+
+# database
+DB_URL="postgres://u:p@h/db"
+EMPTY=
+`
+	tests := []struct {
+		name string
+		opts []FormatOption
+		want string
+	}{
+		{
+			name: "default",
+			want: `# service
+export ZETA=plain # inline
+ALPHA='needs quoting'
+
+# database
+DB_URL=postgres://u:p@h/db
+EMPTY=
+`,
+		},
+		{
+			name: "sorted",
+			opts: []FormatOption{SortKeys(), StripExport()},
+			want: `ALPHA='needs quoting'
+# database
+DB_URL=postgres://u:p@h/db
+EMPTY=
+# service
+ZETA=plain # inline
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Format([]byte(src), tt.opts...)
+			if err != nil {
+				t.Fatalf("Format() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Format() got:\n%s\nwant:\n%s", got, tt.want)
+			}
+			again, err := Format(got, tt.opts...)
+			if err != nil || string(again) != string(got) {
+				t.Errorf("Format() is not idempotent:\n%s", again)
+			}
+		})
+	}
+}
+
+func TestFormatInvalidLine(t *testing.T) {
+	if _, err := Format([]byte("A=1\nnot a variable\n")); err == nil {
+		t.Error("Format() expected an error for an invalid line")
+	}
+}
@@ -0,0 +1,60 @@
+package dotenv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MergeStrategy decides the value of a key declared in both merged files.
+// It has the same type as env.MergeStrategy, so the strategies of the env
+// package, such as env.LastWins or env.AppendWith, can be used directly.
+type MergeStrategy = func(key, existing, incoming string) (string, error)
+
+// Merge merges the .env file overlay into the .env file base. The result keeps
+// the layout and comments of base: keys declared in both files are updated in
+// place with the value chosen by strategy, and keys only declared in overlay
+// are appended in the order they appear there.
+// If the strategy fails for any key, the errors of all such keys are returned.
+func Merge(base, overlay string, strategy MergeStrategy) (*Document, error) {
+	d, err := ReadDocument(base)
+	if err != nil {
+		return nil, err
+	}
+	o, err := ReadDocument(overlay)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.Merge(o, strategy); err != nil {
+		return nil, fmt.Errorf("merging %s into %s: %w", overlay, base, err)
+	}
+	return d, nil
+}
+
+// Merge merges the variables of other into d, see Merge. d is left unmodified
+// if the strategy fails.
+func (d *Document) Merge(other *Document, strategy MergeStrategy) error {
+	values := make(map[string]string)
+	var errs []error
+	for _, key := range other.Keys() {
+		incoming, _ := other.Get(key)
+		existing, ok := d.Get(key)
+		if !ok {
+			values[key] = incoming
+			continue
+		}
+		value, err := strategy(key, existing, incoming)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values[key] = value
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for _, key := range other.Keys() {
+		d.Set(key, values[key])
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package dotenv
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.env")
+	overlay := filepath.Join(dir, "prod.env")
+	os.WriteFile(base, []byte("# shared settings\nHOST=localhost # dev default\nPATH_LIST=/bin\n"), 0o600)
+	os.WriteFile(overlay, []byte("# production\nHOST=prod.internal\nPATH_LIST=/opt/bin\nREPLICAS=3\n"), 0o600)
+
+	lastWins := func(_, _, incoming string) (string, error) { return incoming, nil }
+	d, err := Merge(base, overlay, lastWins)
+	if err != nil {
+		t.Fatalf("Merge() error = %v", err)
+	}
+	want := "# shared settings\nHOST=prod.internal # dev default\nPATH_LIST=/opt/bin\nREPLICAS=3\n"
+	if got := d.String(); got != want {
+		t.Errorf("Merge() got:\n%s\nwant:\n%s", got, want)
+	}
+
+	conflict := func(key, existing, incoming string) (string, error) {
+		return "", os.ErrExist
+	}
+	if _, err := Merge(base, overlay, conflict); err == nil {
+		t.Error("Merge() expected the strategy error")
+	}
+}
@@ -0,0 +1,153 @@
+package dotenv
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// OrderedMap holds variables in the order they were declared. A key declared
+// several times keeps the position of its first declaration and the value of
+// its last one, like Document.Keys and Parse. Its Lookup method makes it
+// usable as a go-env Provider.
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []string
+	values map[string]string
+	// declared and all are filled while parsing, see OnDuplicate
+	declared map[string]declaration
+	all      map[string][]string
+}
+
+// ParseOrdered reads variables from r like Parse, keeping their order
+func ParseOrdered(r io.Reader, opts ...ParseOption) (*OrderedMap, error) {
+	return parseOrdered(r, "", opts)
+}
+
+// ReadFileOrdered parses the .env file with the given name, keeping the order
+// of its variables
+func ReadFileOrdered(filename string, opts ...ParseOption) (*OrderedMap, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseOrdered(f, filename, opts)
+}
+
+func parseOrdered(r io.Reader, filename string, opts []ParseOption) (*OrderedMap, error) {
+	m := &OrderedMap{}
+	if err := m.parse(r, filename, newParseConfig(opts)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parse adds the variables read from r to m
+func (m *OrderedMap) parse(r io.Reader, filename string, c *parseConfig) error {
+	var errs []error
+	err := stream(r, filename, c, func(key, value string, line, column int) error {
+		if err := m.add(c, key, value, filename, line, column); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	return errors.Join(append(errs, err)...)
+}
+
+// Get returns the value of key
+func (m *OrderedMap) Get(key string) (string, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+// Lookup returns the value of key, like Get
+func (m *OrderedMap) Lookup(key string) (string, bool) {
+	return m.Get(key)
+}
+
+// Set sets the value of key, appending it after the other keys if it is new
+func (m *OrderedMap) Set(key, value string) error {
+	if m.values == nil {
+		m.values = make(map[string]string)
+	}
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+	return nil
+}
+
+// Delete removes key
+func (m *OrderedMap) Delete(key string) {
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+	delete(m.values, key)
+	delete(m.all, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Len returns the number of variables
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Keys returns the keys in order
+func (m *OrderedMap) Keys() []string {
+	return append([]string(nil), m.keys...)
+}
+
+// Map returns the variables as a plain map
+func (m *OrderedMap) Map() map[string]string {
+	vars := make(map[string]string, len(m.values))
+	for key, value := range m.values {
+		vars[key] = value
+	}
+	return vars
+}
+
+// Environ returns the variables in "key=value" form, in order
+func (m *OrderedMap) Environ() []string {
+	environ := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		environ[i] = key + "=" + m.values[key]
+	}
+	return environ
+}
+
+// String returns the variables in the .env format, in order, with their
+// values quoted as needed
+func (m *OrderedMap) String() string {
+	var b strings.Builder
+	for _, key := range m.keys {
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(Quote(m.values[key]))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// WriteTo writes the variables to w in the .env format
+func (m *OrderedMap) WriteTo(w io.Writer) (int64, error) {
+	n, err := io.WriteString(w, m.String())
+	return int64(n), err
+}
+
+// Ordered returns the variables of the document in order
+func (d *Document) Ordered() *OrderedMap {
+	m := &OrderedMap{}
+	for _, l := range d.lines {
+		if l.entry != nil {
+			m.Set(l.entry.key, l.entry.value)
+		}
+	}
+	return m
+}
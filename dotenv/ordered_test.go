@@ -0,0 +1,49 @@
+package dotenv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOrdered(t *testing.T) {
+	input := "ZED=1\nALPHA='a b'\nZED=2\nMID=\"x\\ny\"\n"
+	m, err := ParseOrdered(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseOrdered() error = %v", err)
+	}
+	if want := []string{"ZED", "ALPHA", "MID"}; !reflect.DeepEqual(m.Keys(), want) {
+		t.Errorf("Keys() got = %v, want %v", m.Keys(), want)
+	}
+	if value, ok := m.Get("ZED"); !ok || value != "2" {
+		t.Errorf("Get(ZED) got = %q, %v, want 2", value, ok)
+	}
+	if want := []string{"ZED=2", "ALPHA=a b", "MID=x\ny"}; !reflect.DeepEqual(m.Environ(), want) {
+		t.Errorf("Environ() got = %q, want %q", m.Environ(), want)
+	}
+
+	roundTrip, err := ParseOrdered(strings.NewReader(m.String()))
+	if err != nil {
+		t.Fatalf("ParseOrdered() error = %v", err)
+	}
+	if !reflect.DeepEqual(roundTrip.Environ(), m.Environ()) {
+		t.Errorf("round trip got = %q, want %q", roundTrip.Environ(), m.Environ())
+	}
+
+	m.Delete("ZED")
+	m.Set("NEW", "n")
+	if want := []string{"ALPHA", "MID", "NEW"}; !reflect.DeepEqual(m.Keys(), want) || m.Len() != 3 {
+		t.Errorf("Keys() got = %v after Delete and Set, want %v", m.Keys(), want)
+	}
+	if want := map[string]string{"ALPHA": "a b", "MID": "x\ny", "NEW": "n"}; !reflect.DeepEqual(m.Map(), want) {
+		t.Errorf("Map() got = %v, want %v", m.Map(), want)
+	}
+
+	doc, err := ParseDocument(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"ZED=2", "ALPHA=a b", "MID=x\ny"}; !reflect.DeepEqual(doc.Ordered().Environ(), want) {
+		t.Errorf("Document.Ordered() got = %q, want %q", doc.Ordered().Environ(), want)
+	}
+}
@@ -2,8 +2,10 @@ package env
 
 import (
 	"fmt"
-	"os"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // ExpandEnv expands environment variables in the input string without using regex
@@ -14,175 +16,566 @@ import (
 // - ${var:+alt}      (use alt if var is set and non-empty)
 // - ${var:?error}    (error if var is unset or empty)
 // - ${var:=default}  (set var to default if unset or empty, then use it)
-func ExpandEnv(input string) (string, error) {
-	var result strings.Builder
+//
+// Options configure the expansion like they configure an Expander, e.g.
+// ExpandEnv(input, WithStrictSyntax(), WithProvider(vars)), and are applied
+// after the ones set with SetDefault.
+func ExpandEnv(input string, opts ...Option) (string, error) {
+	if len(opts) == 0 && plain(input) {
+		return input, nil
+	}
+	cfg := newConfig(opts...)
+	if cfg.literal(input) {
+		return input, nil
+	}
+	return expandWith(cfg, input)
+}
+
+// Expand expands variables in the input string like ExpandEnv, resolving them
+// from p instead of the process environment or the provider set with
+// SetDefault. Assignments performed by ${var:=default} are written back to p
+// if it implements Setter, unless WithSetter is given.
+func Expand(input string, p Provider, opts ...Option) (string, error) {
+	if len(opts) == 0 && plain(input) {
+		return input, nil
+	}
+	cfg := newConfig(append([]Option{WithProvider(p)}, opts...)...)
+	if cfg.literal(input) {
+		return input, nil
+	}
+	return expandWith(cfg, input)
+}
+
+// expandWith expands input with its own copy of cfg
+func expandWith(cfg config, input string) (string, error) {
+	e := &expander{cfg: &cfg, pure: cfg.pure}
+	return e.expandCommit(input)
+}
+
+// plain reports whether input holds nothing to expand with the built-in
+// defaults, saving the configuration from being built
+func plain(input string) bool {
+	var c config
+	return c.refSyntax().next(input) == -1 && !hasDefaults()
+}
+
+// literal reports whether input holds nothing to expand, in which case it is
+// its own expansion. The output is still counted by WithStats.
+func (c *config) literal(input string) bool {
+	if c.refSyntax().next(input) != -1 {
+		return false
+	}
+	if c.stats != nil {
+		c.stats.add(0, 0, len(input), "")
+	}
+	return true
+}
+
+// expander holds the state shared by a single expansion
+type expander struct {
+	cfg *config
+	// pure records assignments in assigned instead of the provider
+	pure     bool
+	assigned map[string]string
+	// deferred lists, in order, the assignments held in assigned until commit
+	// with WithTransactionalAssignments
+	deferred []string
+	// failed drops the deferred assignments of a stream that hit an error
+	failed bool
+	// result collects the details of the expansion if not nil
+	result *Result
+	// secret redacts every value logged or traced
+	secret bool
+	// batch holds the variables fetched ahead by prefetch
+	batch map[string]cacheEntry
+	// deadline bounds the expansion if WithTimeout is set, zero until it starts
+	deadline time.Time
+	// quote is the quote enclosing the text being expanded with
+	// WithShellQuotes, 0 outside quotes
+	quote byte
+}
+
+// lookup resolves a variable from the expander's provider, running the
+// configured hooks and filters around it
+func (e *expander) lookup(name string) (string, bool, error) {
+	for _, hook := range e.cfg.lookupHooks {
+		hook(name)
+	}
+
+	value, found := "", e.allowed(name)
+	if found {
+		if e.timedOut() {
+			return "", false, e.timeoutError(name)
+		}
+		var err error
+		start := time.Now()
+		value, found, err = e.providerLookup(name)
+		if e.cfg.metrics != nil {
+			e.cfg.metrics.ObserveProviderLatency(providerName(e.cfg.provider), time.Since(start))
+		}
+		if err == nil && !found && e.cfg.caseFallback {
+			value, found, err = e.fallbackLookup(name)
+		}
+		if err == nil && !found {
+			value, found, err = e.aliasLookup(name)
+		}
+		if err == nil && !found && e.cfg.knownFolders {
+			value, found = knownFolder(name)
+		}
+		if err == nil && !found && e.cfg.xdg {
+			value, found, err = e.xdgLookup(name)
+		}
+		if err == nil && !found {
+			value, found = RegisteredDefaults.Lookup(name)
+		}
+		if err != nil {
+			e.logError(name, err)
+			if e.timedOut() {
+				return "", false, e.timeoutError(name)
+			}
+			return "", false, fmt.Errorf("failed to look up variable '%s': %w", name, err)
+		}
+	}
+	if e.cfg.metrics != nil {
+		e.cfg.metrics.ObserveLookup(name, found)
+	}
+	if found {
+		e.recordResolved(name)
+		if e.cfg.detectSecrets {
+			e.checkSecret(name, value)
+		}
+	}
+
+	for _, hook := range e.cfg.resolveHooks {
+		hook(name, value, found)
+	}
+	return value, found, nil
+}
+
+// allowed reports whether the lookup filters let name be looked up
+func (e *expander) allowed(name string) bool {
+	for _, allow := range e.cfg.lookupFilter {
+		if !allow(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// providerLookup queries the provider, using its context-aware form if available
+func (e *expander) providerLookup(name string) (string, bool, error) {
+	if value, ok := e.assigned[name]; ok {
+		return value, true, nil
+	}
+	if entry, ok := e.batch[name]; ok {
+		return entry.value, entry.found, nil
+	}
+	if cp, ok := e.cfg.provider.(ContextProvider); ok {
+		ctx, cancel := e.ctx()
+		defer cancel()
+		return cp.LookupContext(ctx, name)
+	}
+	value, found := e.cfg.provider.Lookup(name)
+	return value, found, nil
+}
+
+// fallbackLookup looks name up in upper case, then in lower case
+func (e *expander) fallbackLookup(name string) (string, bool, error) {
+	for _, alt := range []string{strings.ToUpper(name), strings.ToLower(name)} {
+		if alt == name {
+			continue
+		}
+		value, found, err := e.providerLookup(alt)
+		if err != nil || found {
+			return value, found, err
+		}
+	}
+	return "", false, nil
+}
+
+// normalize translates a variable name written in braces if the provider
+// supports it
+func (e *expander) normalize(name string) string {
+	if n, ok := e.cfg.provider.(Normalizer); ok {
+		return n.NormalizeName(name)
+	}
+	return name
+}
+
+// assign stores a variable in the expander's provider if it supports it
+func (e *expander) assign(name, value string) error {
+	if e.pure || e.cfg.transactional {
+		if e.assigned == nil {
+			e.assigned = make(map[string]string)
+		}
+		if _, ok := e.assigned[name]; !ok && !e.pure {
+			e.deferred = append(e.deferred, name)
+		}
+		e.assigned[name] = value
+		return nil
+	}
+	return e.set(name, value)
+}
+
+// set writes a variable with the setter given by WithSetter, or to the
+// expander's provider if it supports it. Values written with a setter are kept
+// in assigned, as the provider does not see them.
+func (e *expander) set(name, value string) error {
+	if e.cfg.setter != nil {
+		if err := e.cfg.setter(name, value); err != nil {
+			return err
+		}
+		if e.assigned == nil {
+			e.assigned = make(map[string]string)
+		}
+		e.assigned[name] = value
+		return nil
+	}
+	if s, ok := e.cfg.provider.(Setter); ok {
+		if err := s.Set(name, value); err != nil {
+			return err
+		}
+		if _, ok := e.batch[name]; ok {
+			e.batch[name] = cacheEntry{value: value, found: true}
+		}
+	}
+	return nil
+}
+
+// commit writes the assignments deferred by WithTransactionalAssignments to
+// the provider, in the order they were made
+func (e *expander) commit() error {
+	if e.failed {
+		return nil
+	}
+	for _, name := range e.deferred {
+		if err := e.set(name, e.assigned[name]); err != nil {
+			return fmt.Errorf("failed to assign variable '%s': %w", name, err)
+		}
+	}
+	e.deferred = nil
+	return nil
+}
+
+// expandCommit expands the whole input string, then commits the deferred
+// assignments if it succeeded
+func (e *expander) expandCommit(input string) (string, error) {
+	output, err := e.expand(input)
+	if err != nil {
+		return "", err
+	}
+	if err := e.commit(); err != nil {
+		return "", err
+	}
+	return output, nil
+}
+
+// expand performs the expansion of the whole input string
+func (e *expander) expand(input string) (string, error) {
+	e.startDeadline()
+	if err := e.prefetch(input); err != nil {
+		return "", err
+	}
+
+	if e.quote == 0 && e.cfg.literal(input) {
+		return input, nil
+	}
+
+	result := getBuffer(sizeHint(input))
+	defer putBuffer(result)
+	if err := e.write(result, input); err != nil {
+		return "", err
+	}
+	return result.String(), nil
+}
+
+// sink is what the expansion is written to
+type sink interface {
+	WriteString(s string)
+	Len() int
+}
+
+// write expands input to result
+func (e *expander) write(result sink, input string) error {
+	syn := e.cfg.refSyntax()
 	i := 0
 
 	for i < len(input) {
-		if input[i] == '$' {
-			// Found a potential variable
-			expanded, newPos, err := parseVariable(input, i)
-			if err != nil {
-				return "", err
+		if e.quote == '\'' {
+			// Single-quoted text is kept as is
+			end := strings.IndexByte(input[i:], '\'')
+			if end == -1 {
+				result.WriteString(input[i:])
+				break
 			}
-			result.WriteString(expanded)
-			i = newPos
-		} else {
-			// Regular character
-			result.WriteByte(input[i])
-			i++
+			result.WriteString(input[i : i+end+1])
+			i += end + 1
+			e.quote = 0
+			continue
 		}
+
+		// Copy the literal run up to the next potential reference in one go
+		next := syn.next(input[i:])
+		if next == -1 {
+			result.WriteString(input[i:])
+			break
+		}
+		result.WriteString(input[i : i+next])
+		i += next
+
+		if n := e.quoting(syn, input, i); n > 0 {
+			result.WriteString(input[i : i+n])
+			i += n
+			continue
+		}
+
+		// Found a potential variable
+		expanded, newPos, err := e.parseVariable(input, i)
+		if err != nil {
+			return err
+		}
+		result.WriteString(expanded)
+		i = newPos
 	}
 
-	return result.String(), nil
+	if e.cfg.stats != nil {
+		e.cfg.stats.add(0, 0, result.Len(), "")
+	}
+	return nil
+}
+
+// quoting returns the length of the quote or backslash escape at pos to copy
+// as is, updating the quote state, or 0 if there is none
+func (e *expander) quoting(syn syntax, input string, pos int) int {
+	if !syn.quotes {
+		return 0
+	}
+	switch c := input[pos]; {
+	case c == '\'' && e.quote == 0:
+		e.quote = '\''
+	case c == '"':
+		if e.quote == '"' {
+			e.quote = 0
+		} else {
+			e.quote = '"'
+		}
+	case c == '\\' && syn.escape != '\\':
+		return min(2, len(input)-pos)
+	default:
+		return 0
+	}
+	return 1
 }
 
 // parseVariable parses a variable starting at position pos in the input string
 // Returns the expanded value, the new position after the variable, and any error
-func parseVariable(input string, pos int) (string, int, error) {
-	if pos >= len(input) || input[pos] != '$' {
-		return "", pos, fmt.Errorf("expected '$' at position %d", pos)
+func (e *expander) parseVariable(input string, pos int) (string, int, error) {
+	syn := e.cfg.refSyntax()
+	if syn.escaped(input, pos) {
+		return input[pos+1 : pos+2], pos + 2, nil
+	}
+	if syn.percent && input[pos] == '%' {
+		return e.parsePercentVariable(input, pos)
+	}
+	if strings.HasPrefix(input[pos:], syn.open) {
+		// Handle ${...} format
+		return e.parseBracedVariable(input, pos)
+	}
+	if syn.sigil == 0 || input[pos] != syn.sigil {
+		// Not a reference, keep the character as literal
+		return input[pos : pos+1], pos + 1, nil
 	}
 
-	pos++ // Skip the '$'
+	pos++ // Skip the sigil
 
 	if pos >= len(input) {
-		// Just a '$' at the end
-		return "$", pos, nil
+		// Just a sigil at the end
+		return string(syn.sigil), pos, nil
 	}
 
-	if input[pos] == '{' {
-		// Handle ${...} format
-		return parseBracedVariable(input, pos)
-	} else {
-		// Handle $var format
-		return parseSimpleVariable(input, pos)
-	}
+	// Handle $var format
+	return e.parseSimpleVariable(input, pos)
 }
 
 // parseSimpleVariable parses a simple $var format
-func parseSimpleVariable(input string, pos int) (string, int, error) {
+func (e *expander) parseSimpleVariable(input string, pos int) (string, int, error) {
 	start := pos
-
-	// Variable name must start with letter or underscore
-	if pos >= len(input) || (!isLetter(input[pos]) && input[pos] != '_') {
-		// Not a valid variable name, return the $ as literal
-		return "$", pos, nil
-	}
+	sigil := string(e.cfg.refSyntax().sigil)
 
 	// Continue while we have valid variable name characters (up to 64 chars max)
-	for pos < len(input) && (isAlphaNum(input[pos]) || input[pos] == '_') && (pos-start) < 64 {
-		pos++
-	}
+	pos = scanName(input, start, e.cfg.unicodeNames)
 
 	varName := input[start:pos]
-	if len(varName) == 0 || len(varName) > 64 {
-		// Invalid variable name, return $ as literal
-		return "$", start, nil
+	if len(varName) == 0 {
+		// Not a valid variable name, return the sigil as literal
+		return sigil, start, nil
 	}
 
-	return os.Getenv(varName), pos, nil
+	value, found, err := e.lookup(varName)
+	if err != nil {
+		return "", 0, err
+	}
+	e.recordSubstitution(varName, found)
+	e.traceValue(varName, found, value)
+	return value, pos, nil
 }
 
-// parseBracedVariable parses a ${...} format variable
-func parseBracedVariable(input string, pos int) (string, int, error) {
-	if pos >= len(input) || input[pos] != '{' {
-		return "", pos, fmt.Errorf("expected '{' at position %d", pos)
+// parsePercentVariable parses a %var% format variable or a %% escape
+func (e *expander) parsePercentVariable(input string, pos int) (string, int, error) {
+	end := matchPercent(input, pos)
+	if end == -1 {
+		// Not a reference, keep the '%' as literal
+		return "%", pos + 1, nil
+	}
+	if end == pos+1 {
+		return "%", end + 1, nil
 	}
 
-	pos++ // Skip the '{'
-	start := pos
+	value, found, err := e.lookup(input[pos+1 : end])
+	if err != nil {
+		return "", 0, err
+	}
+	if !found {
+		// Like cmd.exe, keep references to unset variables
+		e.recordUnresolved(input[pos+1 : end])
+		e.traceStep(input[pos+1:end], "%", false, "literal", input[pos:end+1])
+		return input[pos : end+1], end + 1, nil
+	}
+	e.recordSubstitution(input[pos+1:end], true)
+	e.traceStep(input[pos+1:end], "%", true, "value", value)
+	return value, end + 1, nil
+}
+
+// parseBracedVariable parses a ${...} format variable starting at pos
+func (e *expander) parseBracedVariable(input string, pos int) (string, int, error) {
+	syn := e.cfg.refSyntax()
 
 	// Find the closing brace
-	braceCount := 1
-	for pos < len(input) && braceCount > 0 {
-		if input[pos] == '{' {
-			braceCount++
-		} else if input[pos] == '}' {
-			braceCount--
-		}
-		if braceCount > 0 {
-			pos++
+	end := syn.match(input, pos)
+	if end == -1 {
+		if e.cfg.strictSyntax {
+			return "", pos, &SyntaxError{Offset: pos, Msg: "unclosed brace in variable expression"}
 		}
-	}
-
-	if braceCount > 0 {
 		return "", pos, fmt.Errorf("unclosed brace in variable expression")
 	}
 
-	content := input[start:pos]
-	pos++ // Skip the closing '}'
-
-	expanded, err := expandBracedContent(content)
+	content := input[pos+len(syn.open) : end]
+	expanded, err := e.expandBracedContent(content, pos)
 	if err != nil {
 		return "", 0, err
 	}
-	return expanded, pos, nil
+	return expanded, end + len(syn.close), nil
 }
 
-// expandBracedContent handles the expansion of content within braces
-func expandBracedContent(content string) (string, error) {
+// splitBraced splits the content of a ${...} expression written in the
+// default syntax, see syntax.split
+func splitBraced(content string) (name, op, word string) {
+	return defaultSyntax.split(content)
+}
+
+// expandBracedContent handles the expansion of content within braces of the
+// reference at offset pos
+func (e *expander) expandBracedContent(content string, pos int) (string, error) {
+	name, op, word := e.cfg.refSyntax().split(content)
+
 	// Validate variable name in braced content
-	var varName string
+	varName := e.normalize(name)
+	if !e.isValidName(varName) {
+		if e.cfg.strictSyntax {
+			return "", &SyntaxError{Offset: pos, Msg: fmt.Sprintf("invalid variable name %q", name)}
+		}
+		syn := e.cfg.refSyntax()
+		return syn.open + content + syn.close, nil // Return as literal if invalid
+	}
 
-	// Look for parameter expansion operators
-	if idx := strings.Index(content, ":-"); idx != -1 {
-		// ${var:-default} - use default if var is unset or empty
-		varName = content[:idx]
-		if !isValidVarName(varName) {
-			return fmt.Sprintf("${%s}", content), nil // Return as literal if invalid
+	if op == "@" && !isTransform(word) {
+		if e.cfg.strictSyntax {
+			return "", &SyntaxError{Offset: pos, Msg: fmt.Sprintf("unknown transformation %q", op+word)}
 		}
-		defaultValue := content[idx+2:]
-		if value := os.Getenv(varName); value != "" {
+		syn := e.cfg.refSyntax()
+		return syn.open + content + syn.close, nil
+	}
+
+	value, found, err := e.lookup(varName)
+	if err != nil {
+		return "", err
+	}
+	// Operators without a colon only test whether the variable is unset
+	set := found && (value != "" || len(op) == 1)
+	if op == "" {
+		e.recordSubstitution(varName, found)
+	} else {
+		e.recordOperator()
+	}
+
+	switch op {
+	case ":-", "-":
+		// ${var:-default} - use default if var is unset or empty
+		if set {
+			e.traceStep(varName, op, found, "value", value)
 			return value, nil
 		}
-		return defaultValue, nil
+		e.traceStep(varName, op, found, "default", word)
+		return word, nil
 
-	} else if idx := strings.Index(content, ":+"); idx != -1 {
+	case ":+", "+":
 		// ${var:+alt} - use alt if var is set and non-empty
-		varName = content[:idx]
-		if !isValidVarName(varName) {
-			return fmt.Sprintf("${%s}", content), nil // Return as literal if invalid
-		}
-		altValue := content[idx+2:]
-		if value := os.Getenv(varName); value != "" {
-			return altValue, nil
+		if set {
+			e.traceStep(varName, op, found, "alternative", word)
+			return word, nil
 		}
+		e.traceStep(varName, op, found, "empty", "")
 		return "", nil
 
-	} else if idx := strings.Index(content, ":?"); idx != -1 {
+	case ":?", "?":
 		// ${var:?error} - error if var is unset or empty
-		varName = content[:idx]
-		if !isValidVarName(varName) {
-			return fmt.Sprintf("${%s}", content), nil // Return as literal if invalid
-		}
-		errorMsg := content[idx+2:]
-		if value := os.Getenv(varName); value != "" {
+		if set {
+			e.traceStep(varName, op, found, "value", value)
 			return value, nil
 		}
-		return "", fmt.Errorf("variable '%s' is unset or empty: %s", varName, errorMsg)
+		e.traceStep(varName, op, found, "error", "")
+		e.logRequired(varName, word)
+		if op == "?" {
+			return "", fmt.Errorf("variable '%s' is unset: %s", varName, word)
+		}
+		return "", fmt.Errorf("variable '%s' is unset or empty: %s", varName, word)
 
-	} else if idx := strings.Index(content, ":="); idx != -1 {
+	case ":=", "=":
 		// ${var:=default} - set var to default if unset or empty, then use it
-		varName = content[:idx]
-		if !isValidVarName(varName) {
-			return fmt.Sprintf("${%s}", content), nil // Return as literal if invalid
-		}
-		defaultValue := content[idx+2:]
-		if value := os.Getenv(varName); value != "" {
+		if set {
+			e.traceStep(varName, op, found, "value", value)
 			return value, nil
 		}
+		e.traceStep(varName, op, found, "assign", word)
 		// Set the environment variable to the default value
-		os.Setenv(varName, defaultValue)
-		return defaultValue, nil
+		if err := e.assign(varName, word); err != nil {
+			e.logError(varName, err)
+			return "", fmt.Errorf("failed to assign variable '%s': %w", varName, err)
+		}
+		e.logAssignment(varName, word)
+		if e.result != nil {
+			e.result.Assignments[varName] = word
+		}
+		return word, nil
+
+	case "@":
+		// ${var@op} - transform the value like bash
+		if found {
+			value = transform(varName, value, word[0])
+		}
+		e.traceStep(varName, op+word, found, "transform", value)
+		return value, nil
 	}
 
 	// Simple ${var} format
-	varName = content
-	if !isValidVarName(varName) {
-		return fmt.Sprintf("${%s}", content), nil // Return as literal if invalid
-	}
-	return os.Getenv(content), nil
+	e.traceValue(varName, found, value)
+	return value, nil
+}
+
+// sizeHint estimates the size of the expansion of input, leaving room for
+// values longer than the references they replace
+func sizeHint(input string) int {
+	return len(input) + len(input)/4 + 16
 }
 
 // Helper functions for character classification
@@ -198,6 +591,37 @@ func isAlphaNum(c byte) bool {
 	return isLetter(c) || isDigit(c)
 }
 
+// scanName returns the end of the variable name starting at pos, which is pos
+// if there is none. A name starts with a letter or underscore, continues with
+// letters, digits and underscores and is cut after 64 characters. With
+// unicodeNames, letters and digits include non-ASCII ones.
+func scanName(input string, pos int, unicodeNames bool) int {
+	i := pos
+	for n := 0; i < len(input) && n < 64; n++ {
+		c, size := input[i], 1
+		ok := isLetter(c) || c == '_' || n > 0 && isDigit(c)
+		if !ok && unicodeNames && c >= utf8.RuneSelf {
+			var r rune
+			r, size = utf8.DecodeRuneInString(input[i:])
+			ok = unicode.IsLetter(r) || n > 0 && (unicode.IsDigit(r) || unicode.Is(unicode.Mn, r))
+		}
+		if !ok {
+			break
+		}
+		i += size
+	}
+	return i
+}
+
+// isValidName validates a variable name written in braces, allowing Unicode
+// letters and digits if the expander is configured for it
+func (e *expander) isValidName(name string) bool {
+	if !e.cfg.unicodeNames {
+		return isValidVarName(name)
+	}
+	return name != "" && scanName(name, 0, true) == len(name)
+}
+
 // isValidVarName validates environment variable name according to the rules:
 // - Must be 1-64 characters long
 // - Must start with a letter [A-Za-z] or underscore [_]
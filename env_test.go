@@ -2,6 +2,7 @@ package env
 
 import (
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -205,6 +206,18 @@ func TestExpandEnvVars(t *testing.T) {
 			want:    "${VAR-WITH-HYPHENS:-default}",
 			wantErr: false,
 		},
+		{
+			name:    "leftmost operator wins",
+			args:    args{input: "${USER:+a:-b}"},
+			want:    "a:-b",
+			wantErr: false,
+		},
+		{
+			name:    "operator characters in default word",
+			args:    args{input: "${NONEXISTENT:-a:+b}"},
+			want:    "a:+b",
+			wantErr: false,
+		},
 		{
 			name:    "valid variable starting with underscore",
 			args:    args{input: "$_UNDERSCORE"},
@@ -351,6 +364,32 @@ func TestExpandEnvVarsAssignment(t *testing.T) {
 	}
 }
 
+func TestExpandEnvOptions(t *testing.T) {
+	vars := Map{"HOST": "db"}
+	tests := []struct {
+		name    string
+		input   string
+		opts    []Option
+		want    string
+		wantErr bool
+	}{
+		{"provider", "${HOST}:${PORT:-5432}", []Option{WithProvider(vars)}, "db:5432", false},
+		{"strict", "${BAD-NAME}", []Option{WithStrictSyntax(), WithProvider(vars)}, "", true},
+		{"pure", "${PORT:=5432}", []Option{WithProvider(vars), WithPure()}, "5432", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExpandEnv(tt.input, tt.opts...)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("ExpandEnv() got = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+	if _, ok := vars["PORT"]; ok {
+		t.Error("ExpandEnv() with WithPure() assigned PORT")
+	}
+}
+
 // BenchmarkExpandEnvVars provides performance benchmarks
 func BenchmarkExpandEnvVars(b *testing.B) {
 	os.Setenv("BENCH_VAR", "benchmark_value")
@@ -364,6 +403,8 @@ func BenchmarkExpandEnvVars(b *testing.B) {
 		{"braced", "${BENCH_VAR}"},
 		{"default", "${MISSING:-default}"},
 		{"complex", "$BENCH_VAR uses ${HOME:-/tmp} and ${SHELL:-/bin/sh}"},
+		{"large", strings.Repeat("listen = 0.0.0.0:8080\nlog_level = info\nuser = $BENCH_VAR\n", 30000)},
+		{"operators", strings.Repeat("${BENCH_VAR:+set:-x} ${MISSING:-a:?b} ${MISSING:-long default value with words} ", 20)},
 	}
 
 	for _, tc := range testCases {
@@ -374,3 +415,71 @@ func BenchmarkExpandEnvVars(b *testing.B) {
 		})
 	}
 }
+
+func TestExpandEnvNoReferencesAllocs(t *testing.T) {
+	input := "listen = 0.0.0.0:8080\nlog_level = info\n"
+	vars := Map{}
+	x := NewExpander(WithProvider(vars))
+	tests := []struct {
+		name   string
+		expand func() (string, error)
+	}{
+		{"ExpandEnv", func() (string, error) { return ExpandEnv(input) }},
+		{"Expand", func() (string, error) { return Expand(input, vars) }},
+		{"Expander", func() (string, error) { return x.Expand(input) }},
+	}
+	for _, tt := range tests {
+		got, err := tt.expand()
+		if err != nil || got != input {
+			t.Errorf("%s() got = %q, %v, want the input", tt.name, got, err)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { tt.expand() }); allocs != 0 {
+			t.Errorf("%s() allocated %v times without references, want 0", tt.name, allocs)
+		}
+	}
+}
+
+func BenchmarkExpandEnvAllocs(b *testing.B) {
+	os.Setenv("BENCH_VAR", "benchmark_value")
+	defer os.Unsetenv("BENCH_VAR")
+
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"no variables", "listen = 0.0.0.0:8080\nlog_level = info\n"},
+		{"one variable", "listen = 0.0.0.0:8080\nuser = $BENCH_VAR\n"},
+		{"many variables", strings.Repeat("user = $BENCH_VAR\n", 100)},
+	}
+
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _ = ExpandEnv(tc.input)
+			}
+		})
+	}
+}
+
+func BenchmarkExpanderParallel(b *testing.B) {
+	x := NewExpander(WithProvider(Map{"HOST": "db.internal", "PORT": "5432"}))
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"small", "postgres://${HOST}:${PORT}/app"},
+		{"medium", strings.Repeat("host=$HOST port=${PORT:-5432}\n", 50)},
+	}
+
+	for _, tc := range testCases {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					_, _ = x.Expand(tc.input)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoadEnvrc reads the direnv .envrc file at path without executing it. Files
+// may use the shell subset of ParseShellExports and the following direnv
+// commands, whose relative paths are resolved against the directory of the
+// file using them:
+//   - PATH_add dir...: prepends the directories to PATH
+//   - path_add NAME dir...: prepends the directories to the list variable NAME
+//   - source_env path: loads another .envrc file, or the .envrc file of a
+//     directory, sharing the variables assigned so far
+//   - source_env_if_exists path: like source_env, ignoring missing files
+//
+// Variables that are not assigned by the files are resolved from fallback,
+// which may be nil, so that PATH_add extends the PATH of the process when
+// given OS. The result holds the variables assigned or exported by the files.
+func LoadEnvrc(path string, fallback Provider) (map[string]string, error) {
+	l := &envrcLoader{vars: make(Map), fallback: fallback, loading: make(map[string]bool)}
+	if err := l.load(path); err != nil {
+		return nil, err
+	}
+	return l.vars, nil
+}
+
+// envrcLoader holds the state shared by the files loaded by LoadEnvrc
+type envrcLoader struct {
+	vars     Map
+	fallback Provider
+	loading  map[string]bool // files being loaded, to detect cycles
+}
+
+// load applies the file at path to the variables
+func (l *envrcLoader) load(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if l.loading[abs] {
+		return fmt.Errorf("%s: sourced recursively", path)
+	}
+	l.loading[abs] = true
+	defer delete(l.loading, abs)
+
+	src, err := os.ReadFile(abs)
+	if err != nil {
+		return err
+	}
+	p := &shellParser{src: string(src), line: 1, vars: l.vars, fallback: l.fallback}
+	dir := filepath.Dir(abs)
+	p.builtins = map[string]func(args []string) error{
+		"PATH_add": func(args []string) error {
+			return l.pathAdd(p, dir, "PATH", args)
+		},
+		"path_add": func(args []string) error {
+			if len(args) == 0 {
+				return errors.New("path_add needs a variable name")
+			}
+			return l.pathAdd(p, dir, args[0], args[1:])
+		},
+		"source_env": func(args []string) error {
+			return l.source(dir, args, false)
+		},
+		"source_env_if_exists": func(args []string) error {
+			return l.source(dir, args, true)
+		},
+	}
+	if err := p.parse(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// pathAdd prepends dirs, keeping their order, to the list variable name
+func (l *envrcLoader) pathAdd(p *shellParser, dir, name string, dirs []string) error {
+	if !isShellName(name) {
+		return fmt.Errorf("invalid variable name %q", name)
+	}
+	list := make([]string, 0, len(dirs)+1)
+	for _, d := range dirs {
+		list = append(list, resolvePath(dir, d))
+	}
+	if value, ok := (shellScope{p}).Lookup(name); ok && value != "" {
+		list = append(list, value)
+	}
+	l.vars[name] = strings.Join(list, string(os.PathListSeparator))
+	return nil
+}
+
+// source loads the file named by the single argument of source_env
+func (l *envrcLoader) source(dir string, args []string, ifExists bool) error {
+	if len(args) != 1 {
+		return errors.New("source_env needs exactly one path")
+	}
+	path := resolvePath(dir, args[0])
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, ".envrc")
+	}
+	if _, err := os.Stat(path); ifExists && errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return l.load(path)
+}
+
+// resolvePath resolves path against dir unless it is absolute
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,74 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadEnvrc(t *testing.T) {
+	dir := t.TempDir()
+	sep := string(os.PathListSeparator)
+	files := map[string]string{
+		".envrc": strings.Join([]string{
+			"source_env shared",
+			"source_env_if_exists .envrc.local",
+			"export APP_ENV=dev",
+			"PATH_add bin node_modules/.bin",
+			"path_add GOPATH /opt/go",
+			"export DB_URL=postgres://$DB_HOST/app",
+		}, "\n"),
+		"shared/.envrc": "export DB_HOST=db\nexport HOME\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0o700)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := LoadEnvrc(filepath.Join(dir, ".envrc"), Map{"PATH": "/usr/bin", "HOME": "/home/app"})
+	if err != nil {
+		t.Fatalf("LoadEnvrc() error = %v", err)
+	}
+	want := map[string]string{
+		"APP_ENV": "dev",
+		"DB_HOST": "db",
+		"HOME":    "/home/app",
+		"DB_URL":  "postgres://db/app",
+		"PATH":    filepath.Join(dir, "bin") + sep + filepath.Join(dir, "node_modules/.bin") + sep + "/usr/bin",
+		"GOPATH":  "/opt/go",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadEnvrc() got = %v, want %v", got, want)
+	}
+}
+
+func TestLoadEnvrcErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"command", "use nix", `line 1: unsupported command "use"`},
+		{"missing", "source_env missing", "no such file"},
+		{"cycle", "source_env .", "sourced recursively"},
+		{"substitution", "export A=$(pwd)", "unsupported command substitution"},
+		{"path_add", "path_add", "path_add needs a variable name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".envrc")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadEnvrc(path, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadEnvrc() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+// Package envtest isolates the process environment of tests and checks the
+// results of expansions.
+package envtest
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+// Env changes the process environment for the duration of a test. Every
+// change is undone when the test and its subtests complete. Like t.Setenv, it
+// cannot be used in parallel tests.
+type Env struct {
+	t testing.TB
+}
+
+// New returns an Env bound to t
+func New(t testing.TB) *Env {
+	return &Env{t: t}
+}
+
+// Set sets the variable name to value
+func (e *Env) Set(name, value string) *Env {
+	e.t.Helper()
+	e.t.Setenv(name, value)
+	return e
+}
+
+// SetMap sets every variable of vars
+func (e *Env) SetMap(vars map[string]string) *Env {
+	e.t.Helper()
+	for name, value := range vars {
+		e.t.Setenv(name, value)
+	}
+	return e
+}
+
+// Unset removes the variable name
+func (e *Env) Unset(name string) *Env {
+	e.t.Helper()
+	// Let t.Setenv record the value to restore
+	e.t.Setenv(name, "")
+	if err := os.Unsetenv(name); err != nil {
+		e.t.Fatalf("failed to unset variable '%s': %v", name, err)
+	}
+	return e
+}
+
+// Clear removes every variable, so the test only sees the ones it sets
+func (e *Env) Clear() *Env {
+	e.t.Helper()
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); name != "" {
+			e.Unset(name)
+		}
+	}
+	return e
+}
+
+// RequireExpanded expands tmpl with an expander configured by opts, which
+// defaults to the process environment, and stops the test unless the
+// expansion succeeds with want
+func RequireExpanded(t testing.TB, tmpl, want string, opts ...env.Option) {
+	t.Helper()
+	got, err := env.NewExpander(opts...).Expand(tmpl)
+	if err != nil {
+		t.Fatalf("expanding %q: unexpected error: %v", tmpl, err)
+		return
+	}
+	if got != want {
+		t.Fatalf("expanding %q: got %q, want %q", tmpl, got, want)
+	}
+}
+
+// RequireExpandError expands tmpl like RequireExpanded and stops the test
+// unless the expansion fails with an error containing msg
+func RequireExpandError(t testing.TB, tmpl, msg string, opts ...env.Option) {
+	t.Helper()
+	got, err := env.NewExpander(opts...).Expand(tmpl)
+	if err == nil {
+		t.Fatalf("expanding %q: got %q, want an error", tmpl, got)
+		return
+	}
+	if !strings.Contains(err.Error(), msg) {
+		t.Fatalf("expanding %q: error %q does not contain %q", tmpl, err, msg)
+	}
+}
@@ -0,0 +1,75 @@
+package envtest
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+func TestEnv(t *testing.T) {
+	os.Setenv("ENVTEST_KEPT", "outer")
+	defer os.Unsetenv("ENVTEST_KEPT")
+
+	t.Run("isolated", func(t *testing.T) {
+		New(t).Set("ENVTEST_FOO", "bar").Unset("ENVTEST_KEPT")
+		RequireExpanded(t, "${ENVTEST_FOO}-${ENVTEST_KEPT:-unset}", "bar-unset")
+		RequireExpandError(t, "${ENVTEST_KEPT:?needed}", "needed")
+
+		New(t).Clear().SetMap(map[string]string{"ONLY": "1"})
+		if environ := os.Environ(); len(environ) != 1 || environ[0] != "ONLY=1" {
+			t.Errorf("Clear() left %v", environ)
+		}
+	})
+
+	if _, ok := os.LookupEnv("ENVTEST_FOO"); ok {
+		t.Error("ENVTEST_FOO was not removed after the test")
+	}
+	if value := os.Getenv("ENVTEST_KEPT"); value != "outer" {
+		t.Errorf("ENVTEST_KEPT got = %q after the test, want outer", value)
+	}
+	if os.Getenv("PATH") == "" {
+		t.Error("PATH was not restored after the test")
+	}
+}
+
+// recorder captures the failures of a test
+type recorder struct {
+	testing.TB
+	failures []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.failures = append(r.failures, fmt.Sprintf(format, args...))
+}
+
+func TestRequire(t *testing.T) {
+	opt := env.WithProvider(env.Map{"A": "1"})
+	tests := []struct {
+		name    string
+		check   func(t testing.TB)
+		failure string
+	}{
+		{"expanded", func(t testing.TB) { RequireExpanded(t, "$A", "1", opt) }, ""},
+		{"mismatch", func(t testing.TB) { RequireExpanded(t, "$A", "2", opt) }, `expanding "$A": got "1", want "2"`},
+		{"unexpected error", func(t testing.TB) { RequireExpanded(t, "${B:?}", "", opt) }, `expanding "${B:?}": unexpected error: variable 'B' is unset or empty: `},
+		{"error", func(t testing.TB) { RequireExpandError(t, "${B:?missing}", "missing", opt) }, ""},
+		{"no error", func(t testing.TB) { RequireExpandError(t, "$A", "missing", opt) }, `expanding "$A": got "1", want an error`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &recorder{TB: t}
+			tt.check(r)
+			var got string
+			if len(r.failures) > 0 {
+				got = r.failures[0]
+			}
+			if got != tt.failure {
+				t.Errorf("failure got = %q, want %q", got, tt.failure)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package envtest
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// updateVar is the variable making Golden rewrite the golden files. It is not
+// a flag, as importing envtest would then clash with the -update flag of
+// other golden tests.
+const updateVar = "ENVTEST_UPDATE"
+
+// Golden runs the test cases found in dir, each one a subtest comparing a
+// result to the contents of NAME.golden:
+//   - NAME.tmpl is expanded with an expander configured by opts, resolving
+//     variables from NAME.env if it exists and from no variables otherwise.
+//     The result is the expanded text, or "error: " followed by the error
+//     message and a newline if the expansion fails.
+//   - NAME.env without a NAME.tmpl is parsed, and the result is its
+//     variables sorted by name, one KEY=value line each with values quoted
+//     like dotenv.Quote.
+//
+// Mismatches are reported with a line diff. Running the tests with
+// ENVTEST_UPDATE=1 in the environment rewrites the golden files with the
+// actual results instead.
+func Golden(t *testing.T, dir string, opts ...env.Option) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("failed to read golden cases: %v", err)
+	}
+	cases := make(map[string]bool)
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && (ext == ".tmpl" || ext == ".env") {
+			cases[strings.TrimSuffix(entry.Name(), ext)] = true
+		}
+	}
+	names := make([]string, 0, len(cases))
+	for name := range cases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			base := filepath.Join(dir, name)
+			got, err := goldenResult(base, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if os.Getenv(updateVar) == "1" {
+				if err := os.WriteFile(base+".golden", []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(base + ".golden")
+			if err != nil {
+				t.Fatalf("%v (run with ENVTEST_UPDATE=1 to create it)", err)
+			}
+			if got != string(want) {
+				t.Errorf("%s.golden mismatch (-want +got):\n%s", name, diffLines(string(want), got))
+			}
+		})
+	}
+}
+
+// goldenResult computes the result of the case whose files start with base
+func goldenResult(base string, opts []env.Option) (string, error) {
+	vars := make(map[string]string)
+	varsData, err := os.ReadFile(base + ".env")
+	switch {
+	case err == nil:
+		if vars, err = dotenv.Parse(bytes.NewReader(varsData)); err != nil {
+			return "", err
+		}
+	case !os.IsNotExist(err):
+		return "", err
+	}
+
+	tmpl, err := os.ReadFile(base + ".tmpl")
+	if os.IsNotExist(err) {
+		var b strings.Builder
+		for _, kv := range env.Map(vars).Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			b.WriteString(name + "=" + dotenv.Quote(value) + "\n")
+		}
+		return b.String(), nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	x := env.NewExpander(append([]env.Option{env.WithProvider(env.Map(vars))}, opts...)...)
+	got, err := x.Expand(string(tmpl))
+	if err != nil {
+		return "error: " + err.Error() + "\n", nil
+	}
+	return got, nil
+}
+
+// diffLines returns a line diff turning want into got, prefixing removed
+// lines with '-', added lines with '+' and common lines with ' '
+func diffLines(want, got string) string {
+	a, b := strings.SplitAfter(want, "\n"), strings.SplitAfter(got, "\n")
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	line := func(prefix byte, s string) {
+		if s == "" {
+			return
+		}
+		out.WriteByte(prefix)
+		out.WriteString(s)
+		if !strings.HasSuffix(s, "\n") {
+			out.WriteString("\n\\ no newline at end\n")
+		}
+	}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			line(' ', a[i])
+			i, j = i+1, j+1
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			line('-', a[i])
+			i++
+		default:
+			line('+', b[j])
+			j++
+		}
+	}
+	return out.String()
+}
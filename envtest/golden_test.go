@@ -0,0 +1,57 @@
+package envtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name      string
+		want, got string
+		diff      string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", " a\n b\n"},
+		{"changed", "a\nb\nc\n", "a\nx\nc\n", " a\n-b\n+x\n c\n"},
+		{"added", "a\n", "a\nb\n", " a\n+b\n"},
+		{"no newline", "a\n", "a", "-a\n+a\n\\ no newline at end\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffLines(tt.want, tt.got); got != tt.diff {
+				t.Errorf("diffLines() got = %q, want %q", got, tt.diff)
+			}
+		})
+	}
+}
+
+func TestGoldenResult(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"vars.env":    "B='x y'\nA=1\n",
+		"greet.tmpl":  "${NAME:-world} $A\n",
+		"greet.env":   "A=1\n",
+		"broken.tmpl": "${A:?missing}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"vars", "A=1\nB='x y'\n"},
+		{"greet", "world 1\n"},
+		{"broken", "error: variable 'A' is unset or empty: missing\n"},
+	}
+	for _, tt := range tests {
+		got, err := goldenResult(filepath.Join(dir, tt.name), nil)
+		if err != nil || got != tt.want {
+			t.Errorf("goldenResult(%s) got = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Expander expands variables with a fixed set of options.
+// It is safe for concurrent use if its provider and hooks are.
+type Expander struct {
+	cfg config
+
+	// state of the stream fed through Write
+	mu      sync.Mutex
+	pending []byte
+	stream  *expander
+}
+
+// NewExpander returns an Expander configured by the options set with
+// SetDefault, then by opts. Without a WithProvider option, variables are
+// resolved from the process environment.
+func NewExpander(opts ...Option) *Expander {
+	return &Expander{cfg: newConfig(opts...)}
+}
+
+// Expand expands variables in the input string, supporting the same formats
+// as ExpandEnv
+func (x *Expander) Expand(input string) (string, error) {
+	e := &expander{cfg: &x.cfg, pure: x.cfg.pure}
+	return e.expandCommit(input)
+}
+
+// Lookup resolves a single variable the way expansion does, running the
+// configured hooks and filters. The boolean reports whether the variable is
+// set, which distinguishes variables set to an empty value from unset ones.
+func (x *Expander) Lookup(name string) (string, bool, error) {
+	e := &expander{cfg: &x.cfg}
+	return e.lookup(name)
+}
+
+// Warm preloads the variables referenced by templates if the provider is a
+// Prefetcher, such as a CachedProvider, so the first expansion after startup
+// does not wait for a remote provider. Only references written in the default
+// syntax are found.
+func (x *Expander) Warm(templates ...string) error {
+	p, ok := x.cfg.provider.(Prefetcher)
+	if !ok {
+		return nil
+	}
+	e := &expander{cfg: &x.cfg}
+	names := e.referencedNames(templates...)
+	if len(names) == 0 {
+		return nil
+	}
+	ctx, cancel := e.ctx()
+	defer cancel()
+	if err := p.Prefetch(ctx, names); err != nil {
+		return fmt.Errorf("failed to warm up provider: %w", err)
+	}
+	return nil
+}
+
+// ExpandPure expands the input string without writing to the provider.
+// The assignments performed by ${var:=default} are visible to the rest of the
+// expansion and returned in a map instead.
+func (x *Expander) ExpandPure(input string) (string, map[string]string, error) {
+	e := &expander{cfg: &x.cfg, pure: true}
+	result, err := e.expand(input)
+	if err != nil {
+		return "", nil, err
+	}
+	assigned := e.assigned
+	if assigned == nil {
+		assigned = make(map[string]string)
+	}
+	return result, assigned, nil
+}
+
+// ExpandPure expands environment variables in the input string like
+// ExpandEnv, but returns the assignments performed by ${var:=default} instead
+// of setting them in the process environment
+func ExpandPure(input string) (string, map[string]string, error) {
+	return NewExpander().ExpandPure(input)
+}
+
+// ExpandAll expands every element of inputs, stopping at the first error.
+// Assignments performed by ${var:=default} are visible to the following elements;
+// with WithTransactionalAssignments they are applied once all elements succeeded.
+func (x *Expander) ExpandAll(inputs []string) ([]string, error) {
+	e := &expander{cfg: &x.cfg, pure: x.cfg.pure}
+	results := make([]string, len(inputs))
+	for i, input := range inputs {
+		result, err := e.expand(input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to expand element %d: %w", i, err)
+		}
+		results[i] = result
+	}
+	if err := e.commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ExpandAllStrict expands every element of inputs like ExpandAll, but does not
+// stop at errors. The errors of all failing elements are joined, each
+// mentioning the index of its element, and the failing elements are left empty.
+// With WithTransactionalAssignments no assignment is applied if any element fails.
+func (x *Expander) ExpandAllStrict(inputs []string) ([]string, error) {
+	e := &expander{cfg: &x.cfg, pure: x.cfg.pure}
+	results := make([]string, len(inputs))
+	var errs []error
+	for i, input := range inputs {
+		result, err := e.expand(input)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to expand element %d: %w", i, err))
+			continue
+		}
+		results[i] = result
+	}
+	if len(errs) > 0 {
+		return results, errors.Join(errs...)
+	}
+	if err := e.commit(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ExpandAll expands environment variables in every element of inputs like
+// ExpandEnv, stopping at the first error
+func ExpandAll(inputs []string) ([]string, error) {
+	return NewExpander().ExpandAll(inputs)
+}
+
+// ExpandAllStrict expands environment variables in every element of inputs
+// like ExpandEnv, joining the errors of all failing elements
+func ExpandAllStrict(inputs []string) ([]string, error) {
+	return NewExpander().ExpandAllStrict(inputs)
+}
@@ -0,0 +1,206 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestExpandPure(t *testing.T) {
+	os.Unsetenv("PURE_VAR")
+	os.Setenv("PURE_SET", "set")
+	defer os.Unsetenv("PURE_SET")
+
+	got, assigned, err := ExpandPure("${PURE_VAR:=default} $PURE_VAR ${PURE_SET:=ignored}")
+	if err != nil {
+		t.Fatalf("ExpandPure() error = %v", err)
+	}
+	if want := "default default set"; got != want {
+		t.Errorf("ExpandPure() got = %v, want %v", got, want)
+	}
+	if want := map[string]string{"PURE_VAR": "default"}; !reflect.DeepEqual(assigned, want) {
+		t.Errorf("ExpandPure() assigned = %v, want %v", assigned, want)
+	}
+	if _, ok := os.LookupEnv("PURE_VAR"); ok {
+		t.Errorf("ExpandPure() modified the process environment")
+	}
+}
+
+func TestWithPure(t *testing.T) {
+	vars := Map{}
+	got, err := NewExpander(WithProvider(vars), WithPure()).Expand("${A:=1}$A")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got != "11" {
+		t.Errorf("Expand() got = %v, want 11", got)
+	}
+	if len(vars) != 0 {
+		t.Errorf("WithPure() wrote to the provider: %v", vars)
+	}
+}
+
+func TestWithTransactionalAssignments(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+		wantSet Map
+	}{
+		{"success", "${A:=1}$A ${B:=2}", "11 2", false, Map{"A": "1", "B": "2"}},
+		{"later failure", "${A:=1}$A ${MISSING:?required}", "", true, Map{}},
+		{"reassigned", "${A:=1}${A:=2}$A", "111", false, Map{"A": "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vars := Map{}
+			got, err := NewExpander(WithProvider(vars), WithTransactionalAssignments()).Expand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() got = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(vars, tt.wantSet) {
+				t.Errorf("provider got = %v, want %v", vars, tt.wantSet)
+			}
+		})
+	}
+
+	t.Run("all", func(t *testing.T) {
+		vars := Map{}
+		x := NewExpander(WithProvider(vars), WithTransactionalAssignments())
+		if _, err := x.ExpandAllStrict([]string{"${A:=1}", "${B:?}"}); err == nil {
+			t.Fatal("ExpandAllStrict() expected an error")
+		}
+		if len(vars) != 0 {
+			t.Errorf("ExpandAllStrict() wrote to the provider: %v", vars)
+		}
+		if _, err := x.ExpandAll([]string{"${A:=1}", "${B:=2}"}); err != nil {
+			t.Fatalf("ExpandAll() error = %v", err)
+		}
+		if want := (Map{"A": "1", "B": "2"}); !reflect.DeepEqual(vars, want) {
+			t.Errorf("provider got = %v, want %v", vars, want)
+		}
+	})
+
+	t.Run("detailed", func(t *testing.T) {
+		vars := Map{}
+		x := NewExpander(WithProvider(vars), WithTransactionalAssignments())
+		result, err := x.ExpandDetailed("${A:=1}")
+		if err != nil {
+			t.Fatalf("ExpandDetailed() error = %v", err)
+		}
+		if want := map[string]string{"A": "1"}; !reflect.DeepEqual(result.Assignments, want) {
+			t.Errorf("ExpandDetailed() assignments = %v, want %v", result.Assignments, want)
+		}
+		if vars["A"] != "1" {
+			t.Errorf("ExpandDetailed() did not apply the assignment: %v", vars)
+		}
+	})
+}
+
+func TestExpanderLookup(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"EMPTY": ""}))
+
+	tests := []struct {
+		name      string
+		wantValue string
+		wantFound bool
+	}{
+		{name: "EMPTY", wantValue: "", wantFound: true},
+		{name: "UNSET", wantValue: "", wantFound: false},
+	}
+	for _, tt := range tests {
+		value, found, err := x.Lookup(tt.name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) error = %v", tt.name, err)
+		}
+		if value != tt.wantValue || found != tt.wantFound {
+			t.Errorf("Lookup(%q) got = %q, %v, want %q, %v", tt.name, value, found, tt.wantValue, tt.wantFound)
+		}
+	}
+}
+
+func TestExpandAll(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"A": "1"}), WithPure())
+
+	got, err := x.ExpandAll([]string{"$A", "${B:=2}", "$A$B"})
+	if err != nil {
+		t.Fatalf("ExpandAll() error = %v", err)
+	}
+	if want := []string{"1", "2", "12"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandAll() got = %q, want %q", got, want)
+	}
+
+	if _, err := x.ExpandAll([]string{"$A", "${C:?needed}", "${D:?needed}"}); err == nil || !strings.Contains(err.Error(), "element 1") {
+		t.Errorf("ExpandAll() error = %v, want an error for element 1", err)
+	}
+}
+
+func TestExpandAllStrict(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"A": "1"}))
+
+	got, err := x.ExpandAllStrict([]string{"${C:?needed}", "$A", "${D:?needed}"})
+	if want := []string{"", "1", ""}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandAllStrict() got = %q, want %q", got, want)
+	}
+	if err == nil {
+		t.Fatal("ExpandAllStrict() expected an error")
+	}
+	for _, index := range []string{"element 0", "element 2"} {
+		if !strings.Contains(err.Error(), index) {
+			t.Errorf("ExpandAllStrict() error = %v, want it to mention %s", err, index)
+		}
+	}
+}
+
+func TestExpanderWarm(t *testing.T) {
+	b := &batchMap{Map: Map{"HOST": "db", "PORT": "5432"}}
+	x := NewExpander(WithProvider(Cached(b, 0)))
+	if err := x.Warm("$HOST:${PORT}", "${HOST}/${NAME:-app}"); err != nil {
+		t.Fatalf("Warm() error = %v", err)
+	}
+	if want := [][]string{{"HOST", "PORT", "NAME"}}; !reflect.DeepEqual(b.batches, want) {
+		t.Errorf("LookupMany() calls = %v, want %v", b.batches, want)
+	}
+
+	if got, err := x.Expand("$HOST:$PORT/${NAME:-app}"); err != nil || got != "db:5432/app" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+	if len(b.batches) != 1 || len(b.lookups) != 0 {
+		t.Errorf("Expand() reached the provider: %v, %v", b.batches, b.lookups)
+	}
+
+	if err := NewExpander(WithProvider(Map{})).Warm("$HOST"); err != nil {
+		t.Errorf("Warm() error = %v without a Prefetcher", err)
+	}
+}
+
+func TestExpanderConcurrent(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"A": "1", "B": strings.Repeat("b", 100<<10)}))
+	inputs := map[string]string{
+		"$A-$A":       "1-1",
+		"${A}${B:+x}": "1x",
+		"[$B]":        "[" + strings.Repeat("b", 100<<10) + "]",
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				for input, want := range inputs {
+					if got, err := x.Expand(input); err != nil || got != want {
+						t.Errorf("Expand(%q) got = %.20q, %v", input, got, err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package env
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExportOption configures ExportJSON and ExportYAML
+type ExportOption func(*exportConfig)
+
+// exportConfig holds the settings of an export
+type exportConfig struct {
+	isSecret func(name string) bool
+	nestSep  string
+}
+
+// ExportRedaction decides which variables hold secrets whose values are
+// replaced with a placeholder, replacing the IsSecretName default. A nil
+// isSecret exports every value as it is.
+func ExportRedaction(isSecret func(name string) bool) ExportOption {
+	return func(c *exportConfig) {
+		c.isSecret = isSecret
+	}
+}
+
+// ExportNested writes the variables as a tree of objects, see Nest
+func ExportNested(sep string) ExportOption {
+	return func(c *exportConfig) {
+		c.nestSep = sep
+	}
+}
+
+// ExportJSON writes vars to w as an indented JSON object with sorted keys,
+// redacting the values of secrets as decided by IsSecretName unless
+// ExportRedaction is given
+func ExportJSON(w io.Writer, vars map[string]string, opts ...ExportOption) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(exportTree(vars, opts))
+}
+
+// ExportYAML writes vars to w as a YAML mapping with sorted keys, redacting
+// secrets like ExportJSON. Values are double-quoted so they are never read
+// back as numbers or booleans.
+func ExportYAML(w io.Writer, vars map[string]string, opts ...ExportOption) error {
+	bw := bufio.NewWriter(w)
+	tree := exportTree(vars, opts)
+	if len(tree) == 0 {
+		bw.WriteString("{}\n")
+	}
+	writeYAML(bw, tree, 0)
+	return bw.Flush()
+}
+
+// ExportJSON writes the variables of e to w, see the ExportJSON function
+func (e *Env) ExportJSON(w io.Writer, opts ...ExportOption) error {
+	return ExportJSON(w, e.Clone().vars, opts...)
+}
+
+// ExportYAML writes the variables of e to w, see the ExportYAML function
+func (e *Env) ExportYAML(w io.Writer, opts ...ExportOption) error {
+	return ExportYAML(w, e.Clone().vars, opts...)
+}
+
+// exportTree redacts vars and nests them if requested
+func exportTree(vars map[string]string, opts []ExportOption) map[string]any {
+	c := exportConfig{isSecret: IsSecretName}
+	for _, opt := range opts {
+		opt(&c)
+	}
+	safe := make(map[string]string, len(vars))
+	for name, value := range vars {
+		if c.isSecret != nil && c.isSecret(name) {
+			value = redacted
+		}
+		safe[name] = value
+	}
+	if c.nestSep != "" {
+		return Nest(safe, c.nestSep)
+	}
+	tree := make(map[string]any, len(safe))
+	for name, value := range safe {
+		tree[name] = value
+	}
+	return tree
+}
+
+// writeYAML writes the mapping node indented by indent spaces
+func writeYAML(w *bufio.Writer, node map[string]any, indent int) {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		w.WriteString(strings.Repeat(" ", indent))
+		w.WriteString(yamlKey(key))
+		w.WriteByte(':')
+		switch v := node[key].(type) {
+		case map[string]any:
+			w.WriteByte('\n')
+			writeYAML(w, v, indent+2)
+		case string:
+			w.WriteByte(' ')
+			w.WriteString(strconv.Quote(v))
+			w.WriteByte('\n')
+		}
+	}
+}
+
+// yamlKey quotes key unless it is a plain word YAML reads as a string
+func yamlKey(key string) string {
+	switch strings.ToLower(key) {
+	case "", "true", "false", "yes", "no", "on", "off", "y", "n", "null", "~":
+		return strconv.Quote(key)
+	}
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; !isAlphaNum(c) && c != '_' && c != '.' && c != '-' {
+			return strconv.Quote(key)
+		}
+	}
+	return key
+}
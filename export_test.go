@@ -0,0 +1,69 @@
+package env
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hadi77ir/go-env/internal/yaml"
+)
+
+func TestExportJSON(t *testing.T) {
+	vars := map[string]string{"APP_HOST": "db", "APP_PASSWORD": "hunter2"}
+	tests := []struct {
+		name string
+		opts []ExportOption
+		want string
+	}{
+		{"redacted", nil, "{\n  \"APP_HOST\": \"db\",\n  \"APP_PASSWORD\": \"****\"\n}\n"},
+		{"revealed", []ExportOption{ExportRedaction(nil)}, "{\n  \"APP_HOST\": \"db\",\n  \"APP_PASSWORD\": \"hunter2\"\n}\n"},
+		{"custom rule", []ExportOption{ExportRedaction(func(name string) bool { return name == "APP_HOST" })}, "{\n  \"APP_HOST\": \"****\",\n  \"APP_PASSWORD\": \"hunter2\"\n}\n"},
+		{"nested", []ExportOption{ExportNested("_")}, "{\n  \"app\": {\n    \"host\": \"db\",\n    \"password\": \"****\"\n  }\n}\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := ExportJSON(&buf, vars, tt.opts...); err != nil {
+				t.Fatalf("ExportJSON() error = %v", err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("ExportJSON() got = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExportYAML(t *testing.T) {
+	vars := map[string]string{"PORT": "8080", "DEBUG": "true", "QUOTE": "a \"b\"\n", "yes": "y", "API_TOKEN": "t"}
+
+	var buf bytes.Buffer
+	if err := NewEnv(vars).ExportYAML(&buf, ExportRedaction(nil)); err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+	want := "API_TOKEN: \"t\"\nDEBUG: \"true\"\nPORT: \"8080\"\nQUOTE: \"a \\\"b\\\"\\n\"\n\"yes\": \"y\"\n"
+	if buf.String() != want {
+		t.Errorf("ExportYAML() got = %q, want %q", buf.String(), want)
+	}
+	var decoded map[string]string
+	if err := yaml.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("yaml.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, vars) {
+		t.Errorf("ExportYAML() read back = %v, want %v", decoded, vars)
+	}
+
+	buf.Reset()
+	if err := ExportYAML(&buf, map[string]string{"APP_DB_HOST": "db", "APP_SECRET": "s"}, ExportNested("_")); err != nil {
+		t.Fatalf("ExportYAML() error = %v", err)
+	}
+	if want := "app:\n  db:\n    host: \"db\"\n  secret: \"****\"\n"; buf.String() != want {
+		t.Errorf("ExportYAML() got = %q, want %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	ExportYAML(&buf, nil)
+	if strings.TrimSpace(buf.String()) != "{}" {
+		t.Errorf("ExportYAML() of no variables got = %q", buf.String())
+	}
+}
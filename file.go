@@ -0,0 +1,96 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ExpandFile expands environment variables in the file src and writes the
+// result to dst. If mode is zero, dst gets the permissions of src.
+// dst is replaced atomically: it is written to a temporary file that is
+// renamed over dst once complete.
+func ExpandFile(src, dst string, mode os.FileMode) error {
+	return NewExpander().ExpandFile(src, dst, mode)
+}
+
+// ExpandGlob expands environment variables in every file matching pattern,
+// writing each result under outDir with the base name of its source
+func ExpandGlob(pattern, outDir string) error {
+	return NewExpander().ExpandGlob(pattern, outDir)
+}
+
+// ExpandFile is like the package-level ExpandFile, using the configuration of x
+func (x *Expander) ExpandFile(src, dst string, mode os.FileMode) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if mode == 0 {
+		mode = info.Mode().Perm()
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+	expanded, err := x.Expand(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	if err := writeFileAtomic(dst, []byte(expanded), mode); err != nil {
+		return err
+	}
+	if x.cfg.stats != nil {
+		x.cfg.stats.add(1, 0, 0, "")
+	}
+	return nil
+}
+
+// ExpandGlob is like the package-level ExpandGlob, using the configuration of x
+func (x *Expander) ExpandGlob(pattern, outDir string) error {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return err
+	}
+	for _, src := range matches {
+		info, err := os.Stat(src)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			continue
+		}
+		if err := x.ExpandFile(src, filepath.Join(outDir, filepath.Base(src)), 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file next to name and renames it
+// to name, so readers never observe a partially written file
+func writeFileAtomic(name string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
@@ -0,0 +1,72 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestExpandFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "config.tmpl")
+	dst := filepath.Join(dir, "config")
+	if err := os.WriteFile(src, []byte("host=${FILE_HOST:-localhost}\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ExpandFile(src, dst, 0); err != nil {
+		t.Fatalf("ExpandFile() error = %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "host=localhost\n" {
+		t.Errorf("ExpandFile() wrote %q, want %q", got, "host=localhost\n")
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != 0o640 {
+			t.Errorf("ExpandFile() mode got = %v, want 0640", info.Mode().Perm())
+		}
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("ExpandFile() left temporary files behind: %v", entries)
+	}
+}
+
+func TestExpandGlob(t *testing.T) {
+	src := t.TempDir()
+	out := t.TempDir()
+	x := NewExpander(WithProvider(Map{"NAME": "glob"}))
+
+	for _, name := range []string{"a.conf", "b.conf", "c.txt"} {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(name+"=$NAME"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := x.ExpandGlob(filepath.Join(src, "*.conf"), out); err != nil {
+		t.Fatalf("ExpandGlob() error = %v", err)
+	}
+
+	for _, name := range []string{"a.conf", "b.conf"} {
+		got, err := os.ReadFile(filepath.Join(out, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := name + "=glob"; string(got) != want {
+			t.Errorf("%s got = %q, want %q", name, got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "c.txt")); !os.IsNotExist(err) {
+		t.Errorf("ExpandGlob() rendered a file not matching the pattern")
+	}
+}
@@ -0,0 +1,157 @@
+package env
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Generational is implemented by providers that count the changes made to
+// their variables, so that results derived from them can be cached until the
+// next change. Providers whose variables never change may always return 0.
+type Generational interface {
+	Generation() uint64
+}
+
+// FileServer is an http.Handler serving the files of a file system with the
+// variables of text files, such as a config.js holding runtime settings of a
+// frontend, expanded. Other files are served as they are.
+//
+// Expanded files are cached until they are modified or, if the provider is
+// Generational, until its variables change. With other providers, such as the
+// process environment, files are expanded on every request.
+type FileServer struct {
+	fsys  fs.FS
+	x     *Expander
+	files http.Handler
+
+	mu    sync.Mutex
+	cache map[string]*servedFile
+}
+
+// servedFile is an expanded file kept by a FileServer
+type servedFile struct {
+	generation uint64
+	modTime    time.Time
+	size       int64
+	body       []byte
+	etag       string
+}
+
+// ExpandingFileServer returns a FileServer for fsys, expanding files with
+// the given options. Text files are selected by the type their extension maps
+// to: text/*, JavaScript, JSON, XML and SVG. WithInclude selects the files to
+// expand by name instead, and WithExclude leaves files out; patterns are
+// matched against the slash-separated path relative to the root of fsys.
+func ExpandingFileServer(fsys fs.FS, opts ...Option) *FileServer {
+	return &FileServer{
+		fsys:  fsys,
+		x:     NewExpander(opts...),
+		files: http.FileServerFS(fsys),
+		cache: make(map[string]*servedFile),
+	}
+}
+
+// ServeHTTP serves the file named by the path of r
+func (s *FileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "."
+	}
+	info, err := fs.Stat(s.fsys, name)
+	if err == nil && info.IsDir() && strings.HasSuffix(r.URL.Path, "/") {
+		name = path.Join(name, "index.html")
+		info, err = fs.Stat(s.fsys, name)
+	}
+	if err != nil || info.IsDir() || !s.expands(name) {
+		s.files.ServeHTTP(w, r)
+		return
+	}
+
+	f, err := s.expanded(name, info)
+	if err != nil {
+		http.Error(w, "failed to expand file", http.StatusInternalServerError)
+		return
+	}
+	if ctype := mime.TypeByExtension(path.Ext(name)); ctype != "" {
+		w.Header().Set("Content-Type", ctype)
+	}
+	w.Header().Set("ETag", f.etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(f.body))
+}
+
+// expands reports whether the file at name is expanded
+func (s *FileServer) expands(name string) bool {
+	c := &s.x.cfg
+	if matchAny(c.exclude, name) {
+		return false
+	}
+	if len(c.include) > 0 {
+		return matchAny(c.include, name)
+	}
+	return isTextType(mime.TypeByExtension(path.Ext(name)))
+}
+
+// isTextType reports whether files of the MIME type ctype hold text
+func isTextType(ctype string) bool {
+	ctype, _, _ = strings.Cut(ctype, ";")
+	switch {
+	case strings.HasPrefix(ctype, "text/"):
+		return true
+	case strings.HasSuffix(ctype, "+json"), strings.HasSuffix(ctype, "+xml"):
+		return true
+	}
+	switch ctype {
+	case "application/javascript", "application/json", "application/xml":
+		return true
+	}
+	return false
+}
+
+// expanded returns the expansion of the file at name, from the cache if it
+// is still valid
+func (s *FileServer) expanded(name string, info fs.FileInfo) (*servedFile, error) {
+	g, cacheable := s.x.cfg.provider.(Generational)
+	var generation uint64
+	if cacheable {
+		generation = g.Generation()
+		s.mu.Lock()
+		f, ok := s.cache[name]
+		s.mu.Unlock()
+		if ok && f.generation == generation && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+			return f, nil
+		}
+	}
+
+	data, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	expanded, err := s.x.Expand(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand %s: %w", name, err)
+	}
+	h := fnv.New64a()
+	h.Write([]byte(expanded))
+	f := &servedFile{
+		generation: generation,
+		modTime:    info.ModTime(),
+		size:       info.Size(),
+		body:       []byte(expanded),
+		etag:       fmt.Sprintf(`"%x"`, h.Sum64()),
+	}
+	if cacheable {
+		s.mu.Lock()
+		s.cache[name] = f
+		s.mu.Unlock()
+	}
+	return f, nil
+}
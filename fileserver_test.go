@@ -0,0 +1,84 @@
+package env
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExpandingFileServer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config.js":        {Data: []byte(`window.API_URL = "${API_URL}";`)},
+		"index.html":       {Data: []byte("<title>$TITLE</title>")},
+		"logo.png":         {Data: []byte("$NOT_EXPANDED")},
+		"raw/notes.txt":    {Data: []byte("$TITLE")},
+		"broken/error.txt": {Data: []byte("${MISSING:?required}")},
+	}
+	vars := NewEnv(map[string]string{"API_URL": "https://api.example.com", "TITLE": "App"})
+	s := ExpandingFileServer(fsys, WithProvider(vars), WithExclude("raw/*"))
+
+	tests := []struct {
+		path      string
+		status    int
+		body      string
+		mediaType string
+	}{
+		{"/config.js", http.StatusOK, `window.API_URL = "https://api.example.com";`, "text/javascript; charset=utf-8"},
+		{"/", http.StatusOK, "<title>App</title>", "text/html; charset=utf-8"},
+		{"/logo.png", http.StatusOK, "$NOT_EXPANDED", "image/png"},
+		{"/raw/notes.txt", http.StatusOK, "$TITLE", "text/plain; charset=utf-8"},
+		{"/broken/error.txt", http.StatusInternalServerError, "failed to expand file\n", ""},
+		{"/missing.js", http.StatusNotFound, "404 page not found\n", ""},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if rec.Code != tt.status || rec.Body.String() != tt.body {
+			t.Errorf("GET %s got = %d %q, want %d %q", tt.path, rec.Code, rec.Body.String(), tt.status, tt.body)
+		}
+		if tt.mediaType != "" && rec.Header().Get("Content-Type") != tt.mediaType {
+			t.Errorf("GET %s Content-Type = %q, want %q", tt.path, rec.Header().Get("Content-Type"), tt.mediaType)
+		}
+	}
+
+	get := func(header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/config.js", nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		s.ServeHTTP(rec, req)
+		return rec
+	}
+	etag := get(nil).Header().Get("ETag")
+	if rec := get(http.Header{"If-None-Match": {etag}}); rec.Code != http.StatusNotModified {
+		t.Errorf("GET with the ETag got = %d, want 304", rec.Code)
+	}
+
+	vars.Set("API_URL", "https://staging.example.com")
+	rec := get(http.Header{"If-None-Match": {etag}})
+	if want := `window.API_URL = "https://staging.example.com";`; rec.Code != http.StatusOK || rec.Body.String() != want {
+		t.Errorf("GET after a change got = %d %q, want %q", rec.Code, rec.Body.String(), want)
+	}
+}
+
+func TestExpandingFileServerCache(t *testing.T) {
+	fsys := fstest.MapFS{"a.txt": {Data: []byte("$A")}}
+	vars := NewEnv(map[string]string{"A": "1"})
+	lookups := 0
+	s := ExpandingFileServer(fsys, WithProvider(vars), WithLookupHook(func(string) { lookups++ }))
+
+	for i := 0; i < 3; i++ {
+		s.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/a.txt", nil))
+	}
+	if lookups != 1 {
+		t.Errorf("file expanded %d times, want 1", lookups)
+	}
+	vars.Set("A", "2")
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/a.txt", nil))
+	if lookups != 2 || rec.Body.String() != "2" {
+		t.Errorf("GET after a change got = %q after %d expansions", rec.Body.String(), lookups)
+	}
+}
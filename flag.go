@@ -0,0 +1,84 @@
+package env
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// BindFlags fills the flags of fs that were not set on the command line from
+// environment variables, see (*Expander).BindFlags
+func BindFlags(fs *flag.FlagSet, prefix string) error {
+	return NewExpander().BindFlags(fs, prefix)
+}
+
+// BindFlags fills the flags of fs that were not set on the command line from
+// variables resolved by x. It must be called after fs.Parse.
+// Variables are named after flags with FlagEnvName, so my-flag is read from
+// PREFIX_MY_FLAG, and their values are expanded before being set.
+func (x *Expander) BindFlags(fs *flag.FlagSet, prefix string) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		explicit[f.Name] = true
+	})
+
+	var bindings []FlagBinding
+	fs.VisitAll(func(f *flag.Flag) {
+		bindings = append(bindings, FlagBinding{
+			Name:    f.Name,
+			Changed: explicit[f.Name],
+			Set:     f.Value.Set,
+		})
+	})
+	return x.Bind(prefix, bindings)
+}
+
+// FlagBinding describes a command-line flag of any flag library for Bind
+type FlagBinding struct {
+	// Name is the name of the flag, e.g. "listen-addr"
+	Name string
+	// Changed reports whether the flag was set explicitly on the command line
+	Changed bool
+	// Set assigns a value to the flag
+	Set func(value string) error
+}
+
+// Bind assigns the flags that were not set explicitly from variables resolved
+// by x, giving the precedence: explicit flag, then variable, then default.
+// Variables are named after flags with FlagEnvName and their values are
+// expanded before being set.
+//
+// Bind is the building block for flag libraries other than the standard one;
+// the cobraenv module binds a cobra command tree with it.
+func (x *Expander) Bind(prefix string, flags []FlagBinding) error {
+	for _, f := range flags {
+		if f.Changed {
+			continue
+		}
+		name := FlagEnvName(prefix, f.Name)
+		raw, found, err := x.Lookup(name)
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+		value, err := x.Expand(raw)
+		if err != nil {
+			return fmt.Errorf("flag -%s from %s: %w", f.Name, name, err)
+		}
+		if err := f.Set(value); err != nil {
+			return fmt.Errorf("flag -%s from %s: %w", f.Name, name, err)
+		}
+	}
+	return nil
+}
+
+// FlagEnvName returns the name of the variable bound to the flag named flagName
+func FlagEnvName(prefix, flagName string) string {
+	name := ToEnvName(flagName)
+	if prefix == "" {
+		return name
+	}
+	return strings.TrimSuffix(prefix, "_") + "_" + name
+}
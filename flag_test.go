@@ -0,0 +1,93 @@
+package env
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	addr := fs.String("listen-addr", ":80", "")
+	timeout := fs.Duration("timeout", time.Second, "")
+	verbose := fs.Bool("verbose", false, "")
+	name := fs.String("name", "default", "")
+
+	if err := fs.Parse([]string{"-name", "explicit"}); err != nil {
+		t.Fatal(err)
+	}
+
+	x := NewExpander(WithProvider(Map{
+		"APP_LISTEN_ADDR": "${HOST:-0.0.0.0}:8080",
+		"APP_TIMEOUT":     "5s",
+		"APP_NAME":        "from_env",
+	}))
+	if err := x.BindFlags(fs, "APP"); err != nil {
+		t.Fatalf("BindFlags() error = %v", err)
+	}
+
+	if *addr != "0.0.0.0:8080" {
+		t.Errorf("listen-addr got = %v, want 0.0.0.0:8080", *addr)
+	}
+	if *timeout != 5*time.Second {
+		t.Errorf("timeout got = %v, want 5s", *timeout)
+	}
+	if *verbose {
+		t.Errorf("verbose got = true, want the default")
+	}
+	if *name != "explicit" {
+		t.Errorf("name got = %v, want the explicit value", *name)
+	}
+}
+
+func TestBindFlagsInvalidValue(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("workers", 1, "")
+	_ = fs.Parse(nil)
+
+	if err := NewExpander(WithProvider(Map{"WORKERS": "many"})).BindFlags(fs, ""); err == nil {
+		t.Error("BindFlags() expected an error for an invalid value")
+	}
+}
+
+func TestFlagEnvName(t *testing.T) {
+	for _, tt := range []struct{ prefix, flag, want string }{
+		{"", "my-flag", "MY_FLAG"},
+		{"APP", "my-flag", "APP_MY_FLAG"},
+		{"APP_", "db.host", "APP_DB_HOST"},
+		{"APP", "maxConns", "APP_MAX_CONNS"},
+	} {
+		if got := FlagEnvName(tt.prefix, tt.flag); got != tt.want {
+			t.Errorf("FlagEnvName(%q, %q) got = %v, want %v", tt.prefix, tt.flag, got, tt.want)
+		}
+	}
+}
+
+func TestBind(t *testing.T) {
+	values := map[string]string{}
+	set := func(name string) func(string) error {
+		return func(value string) error {
+			values[name] = value
+			return nil
+		}
+	}
+
+	x := NewExpander(WithProvider(Map{
+		"CLI_CONFIG":    "${HOME_DIR:-/etc}/cli.yaml",
+		"CLI_LOG_LEVEL": "debug",
+	}))
+	err := x.Bind("CLI", []FlagBinding{
+		{Name: "config", Set: set("config")},
+		{Name: "log-level", Changed: true, Set: set("log-level")},
+		{Name: "unset", Set: set("unset")},
+	})
+	if err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if want := map[string]string{"config": "/etc/cli.yaml"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("Bind() set = %v, want %v", values, want)
+	}
+}
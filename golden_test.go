@@ -0,0 +1,28 @@
+package env_test
+
+import (
+	"testing"
+
+	env "github.com/hadi77ir/go-env"
+	"github.com/hadi77ir/go-env/envtest"
+)
+
+func TestGolden(t *testing.T) {
+	envtest.Golden(t, "testdata/golden")
+}
+
+// TestModes runs the conformance suite of every mode, see testdata/modes
+func TestModes(t *testing.T) {
+	modes := map[string]env.Mode{
+		"default":  env.ModeDefault,
+		"posix":    env.ModePOSIX,
+		"bash":     env.ModeBash,
+		"compose":  env.ModeCompose,
+		"envsubst": env.ModeEnvsubst,
+	}
+	for name, mode := range modes {
+		t.Run(name, func(t *testing.T) {
+			envtest.Golden(t, "testdata/modes/"+name, env.WithMode(mode))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// HealthChecker is implemented by providers backed by a service that may be
+// unavailable, such as a secrets manager, to verify that lookups can be served.
+// Providers wrapping another one delegate to it.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
+
+// CheckAll pings the providers implementing HealthChecker concurrently,
+// joining the errors of the failing ones; other providers are taken to be
+// healthy. Without providers, the provider set with SetDefault is checked.
+// Services call it at startup, before rendering anything, and from readiness
+// probes, see HealthHandler.
+func CheckAll(ctx context.Context, providers ...Provider) error {
+	if len(providers) == 0 {
+		cfg := newConfig()
+		providers = []Provider{cfg.provider}
+	}
+	errs := make([]error, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		hc, ok := p.(HealthChecker)
+		if !ok {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := hc.Ping(ctx); err != nil {
+				errs[i] = fmt.Errorf("provider %s is unhealthy: %w", providerName(p), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// HealthHandler returns an http.Handler for readiness probes, responding
+// with 200 OK if CheckAll succeeds for providers and with 503 Service
+// Unavailable otherwise. Errors are not included in the response, as they
+// may reveal the layout of the backends.
+func HealthHandler(providers ...Provider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := CheckAll(r.Context(), providers...); err != nil {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte("ok\n"))
+	})
+}
+
+// ping checks p if it is a HealthChecker, for the providers wrapping it
+func ping(ctx context.Context, p Provider) error {
+	if hc, ok := p.(HealthChecker); ok {
+		return hc.Ping(ctx)
+	}
+	return nil
+}
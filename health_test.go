@@ -0,0 +1,85 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCheckAll(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "HOST=db\n")
+	}))
+	defer healthy.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	ctx := context.Background()
+	up := NewHTTPProvider(healthy.URL)
+	down := NewHTTPProvider(failing.URL)
+	if err := CheckAll(ctx, Map{}, up, Cached(up, 0), Prefixed(up, "APP_")); err != nil {
+		t.Errorf("CheckAll() error = %v", err)
+	}
+	err := CheckAll(ctx, up, Cached(down, 0), Overlay(Prefixed(down, "APP_")))
+	if err == nil || strings.Count(err.Error(), "unhealthy") != 2 || !strings.Contains(err.Error(), "502") {
+		t.Errorf("CheckAll() error = %v, want both wrappers of the failing provider reported", err)
+	}
+
+	defer SetDefault()
+	SetDefault(WithProvider(down))
+	if err := CheckAll(ctx); err == nil {
+		t.Error("CheckAll() expected an error for the default provider")
+	}
+}
+
+func TestCheckAllWrappers(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	down := NewHTTPProvider(failing.URL)
+	for _, p := range []Provider{
+		Retry(down, Backoff{Attempts: 1}),
+		RateLimit(down, 1, 1),
+		Normalized(down),
+		Retry(RateLimit(down, 1, 1), Backoff{}),
+	} {
+		if err := CheckAll(context.Background(), p); err == nil || !strings.Contains(err.Error(), "502") {
+			t.Errorf("CheckAll(%T) error = %v, want the failure of the wrapped provider", p, err)
+		}
+	}
+	if err := NewKoanfProvider(down, nil).Ping(context.Background()); err == nil {
+		t.Error("KoanfProvider.Ping() expected the failure of the wrapped provider")
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	tests := []struct {
+		providers []Provider
+		status    int
+	}{
+		{[]Provider{Map{}}, http.StatusOK},
+		{[]Provider{Map{}, NewHTTPProvider(failing.URL)}, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		HealthHandler(tt.providers...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		if rec.Code != tt.status {
+			t.Errorf("HealthHandler() status = %d, want %d", rec.Code, tt.status)
+		}
+		if strings.Contains(rec.Body.String(), failing.URL) {
+			t.Errorf("HealthHandler() revealed the error: %q", rec.Body.String())
+		}
+	}
+}
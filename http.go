@@ -0,0 +1,267 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// HTTPProvider resolves variables from a JSON object or a .env document served
+// over HTTP(S), for centralized configuration services.
+// The document is fetched on first use and refreshed once it is older than the
+// refresh interval, using its ETag to avoid transferring unchanged documents.
+// It is safe for concurrent use.
+type HTTPProvider struct {
+	url     string
+	client  *http.Client
+	header  http.Header
+	refresh time.Duration
+	now     func() time.Time
+	parse   func(body []byte, contentType string) (map[string]string, error)
+
+	fetchMu sync.Mutex // serializes fetches
+
+	mu      sync.RWMutex
+	vars    map[string]string
+	etag    string
+	fetched time.Time
+}
+
+// HTTPOption configures an HTTPProvider
+type HTTPOption func(*HTTPProvider)
+
+// HTTPClient sets the client used for requests, http.DefaultClient by default
+func HTTPClient(client *http.Client) HTTPOption {
+	return func(h *HTTPProvider) {
+		h.client = client
+	}
+}
+
+// HTTPHeader adds a header to every request, e.g. for authentication
+func HTTPHeader(key, value string) HTTPOption {
+	return func(h *HTTPProvider) {
+		h.header.Add(key, value)
+	}
+}
+
+// HTTPBearerToken authenticates requests with the given bearer token
+func HTTPBearerToken(token string) HTTPOption {
+	return HTTPHeader("Authorization", "Bearer "+token)
+}
+
+// HTTPRefresh sets the age after which the document is fetched again.
+// With zero, the default, the document is only fetched once.
+func HTTPRefresh(interval time.Duration) HTTPOption {
+	return func(h *HTTPProvider) {
+		h.refresh = interval
+	}
+}
+
+// NewHTTPProvider returns a provider serving variables from the document at url.
+// Documents served as application/json must hold a single object whose values
+// are strings, numbers or booleans; any other content type is parsed as .env.
+func NewHTTPProvider(url string, opts ...HTTPOption) *HTTPProvider {
+	h := &HTTPProvider{
+		url:    url,
+		client: http.DefaultClient,
+		header: make(http.Header),
+		now:    time.Now,
+		parse:  parseDocument,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Lookup returns the value of name, fetching the document if needed.
+// Fetch errors are reported as the variable being unset; use LookupContext to
+// observe them.
+func (h *HTTPProvider) Lookup(name string) (string, bool) {
+	value, found, _ := h.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext returns the value of name, fetching the document if it was
+// never fetched or is older than the refresh interval. If a refresh fails after
+// a successful fetch, the previously fetched values keep being served.
+func (h *HTTPProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	if err := h.ensureFresh(ctx); err != nil {
+		return "", false, err
+	}
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	value, found := h.vars[name]
+	return value, found, nil
+}
+
+// Prefetch fetches the document if it was never fetched or is stale, so the
+// next lookups are served without waiting. The document holds every variable,
+// so names are ignored.
+func (h *HTTPProvider) Prefetch(ctx context.Context, _ []string) error {
+	return h.ensureFresh(ctx)
+}
+
+// Environ returns the variables of the last fetched document in "key=value"
+// form, sorted by key
+func (h *HTTPProvider) Environ() []string {
+	_ = h.ensureFresh(context.Background())
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return Map(h.vars).Environ()
+}
+
+// Ping fetches the document to check that the server serves it, see
+// HealthChecker
+func (h *HTTPProvider) Ping(ctx context.Context) error {
+	return h.Refresh(ctx)
+}
+
+// ensureFresh fetches the document if it is missing or stale. Concurrent
+// callers wait for a single fetch.
+func (h *HTTPProvider) ensureFresh(ctx context.Context) error {
+	if h.fresh() {
+		return nil
+	}
+	h.fetchMu.Lock()
+	defer h.fetchMu.Unlock()
+	// Another caller may have fetched the document while this one waited
+	if h.fresh() {
+		return nil
+	}
+	h.mu.RLock()
+	loaded := h.vars != nil
+	h.mu.RUnlock()
+	err := h.fetch(ctx)
+	if err != nil && loaded {
+		return nil // keep serving the stale document
+	}
+	return err
+}
+
+// fresh reports whether the document was fetched and is not stale
+func (h *HTTPProvider) fresh() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.vars != nil && (h.refresh <= 0 || h.now().Sub(h.fetched) < h.refresh)
+}
+
+// Refresh fetches the document unconditionally, sending the ETag of the
+// previous response so an unchanged document is not transferred again
+func (h *HTTPProvider) Refresh(ctx context.Context) error {
+	h.fetchMu.Lock()
+	defer h.fetchMu.Unlock()
+	return h.fetch(ctx)
+}
+
+// maxDocumentSize is the size above which a document is rejected
+var maxDocumentSize = 16 << 20
+
+// fetch fetches the document, with fetchMu held
+func (h *HTTPProvider) fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return err
+	}
+	for key, values := range h.header {
+		req.Header[key] = values
+	}
+	h.mu.RLock()
+	if h.etag != "" {
+		req.Header.Set("If-None-Match", h.etag)
+	}
+	h.mu.RUnlock()
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return requestError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		h.mu.Lock()
+		h.fetched = h.now()
+		h.mu.Unlock()
+		return nil
+	case http.StatusOK:
+	default:
+		return statusError(fmt.Errorf("failed to fetch %s: unexpected status %s", h.url, resp.Status), resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(maxDocumentSize)+1))
+	if err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", h.url, err)
+	}
+	if len(body) > maxDocumentSize {
+		return fmt.Errorf("failed to fetch %s: document larger than %d bytes", h.url, maxDocumentSize)
+	}
+	vars, err := h.parse(body, resp.Header.Get("Content-Type"))
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", h.url, err)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.vars = vars
+	h.etag = resp.Header.Get("ETag")
+	h.fetched = h.now()
+	return nil
+}
+
+// requestError marks the failure of a request as transient unless ctx ended
+func requestError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+	return &UnavailableError{Err: err}
+}
+
+// statusError marks err, caused by an unexpected response status, as
+// transient if the status denotes a timeout, throttling or a server failure
+func statusError(err error, status int) error {
+	if status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500 {
+		return &UnavailableError{Err: err}
+	}
+	return err
+}
+
+// parseDocument decodes a JSON or .env document depending on its content type.
+// JSON null values leave their variable unset.
+func parseDocument(body []byte, contentType string) (map[string]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType != "application/json" {
+		return dotenv.Parse(bytes.NewReader(body))
+	}
+
+	var raw map[string]any
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	vars := make(map[string]string, len(raw))
+	for name, value := range raw {
+		switch v := value.(type) {
+		case nil:
+		case string:
+			vars[name] = v
+		case json.Number:
+			vars[name] = v.String()
+		case bool:
+			vars[name] = strconv.FormatBool(v)
+		default:
+			return nil, fmt.Errorf("value of '%s' is not a string, number or boolean", name)
+		}
+	}
+	return vars, nil
+}
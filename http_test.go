@@ -0,0 +1,133 @@
+package env
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestHTTPProviderJSON(t *testing.T) {
+	var requests, transfers atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		transfers.Add(1)
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte(`{"HOST": "db.internal", "PORT": 5432, "DEBUG": false}`))
+	}))
+	defer server.Close()
+
+	h := NewHTTPProvider(server.URL, HTTPBearerToken("secret"), HTTPRefresh(time.Minute))
+	now := time.Unix(0, 0)
+	h.now = func() time.Time { return now }
+
+	got, err := Expand("$HOST:$PORT debug=$DEBUG", h)
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "db.internal:5432 debug=false"; got != want {
+		t.Errorf("Expand() got = %v, want %v", got, want)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests got = %d, want 1 before the refresh interval", requests.Load())
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := h.Lookup("HOST"); !ok {
+		t.Errorf("Lookup() lost the values after a not-modified response")
+	}
+	if requests.Load() != 2 || transfers.Load() != 1 {
+		t.Errorf("requests/transfers got = %d/%d, want 2/1", requests.Load(), transfers.Load())
+	}
+}
+
+func TestHTTPProviderDotenv(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("# remote config\nHOST=remote\nexport PORT=80\n"))
+	}))
+	defer server.Close()
+
+	h := NewHTTPProvider(server.URL)
+	if want := []string{"HOST=remote", "PORT=80"}; !reflect.DeepEqual(h.Environ(), want) {
+		t.Errorf("Environ() got = %v, want %v", h.Environ(), want)
+	}
+}
+
+func TestHTTPProviderError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	h := NewHTTPProvider(server.URL)
+	if _, _, err := h.LookupContext(context.Background(), "HOST"); err == nil {
+		t.Error("LookupContext() expected an error for a failing server")
+	}
+	if _, err := Expand("$HOST", h); err == nil {
+		t.Error("Expand() expected the provider error to abort the expansion")
+	}
+}
+
+func TestHTTPProviderConcurrentFetch(t *testing.T) {
+	var fetches atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte("HOST=remote\n"))
+	}))
+	defer server.Close()
+
+	h := NewHTTPProvider(server.URL, HTTPRefresh(time.Hour))
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if value, found := h.Lookup("HOST"); !found || value != "remote" {
+				t.Errorf("Lookup(HOST) got = %q, %v", value, found)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("10 concurrent lookups fetched the document %d times, want 1", n)
+	}
+}
+
+func TestHTTPProviderDocumentTooLarge(t *testing.T) {
+	defer func(size int) { maxDocumentSize = size }(maxDocumentSize)
+	maxDocumentSize = 16
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("A=1\n", 10)))
+	}))
+	defer server.Close()
+
+	err := NewHTTPProvider(server.URL).Refresh(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "larger than 16 bytes") {
+		t.Errorf("Refresh() error = %v, want the document rejected", err)
+	}
+}
+
+func TestParseDocumentNull(t *testing.T) {
+	got, err := parseDocument([]byte(`{"HOST": "db.internal", "PASSWORD": null}`), "application/json")
+	if err != nil {
+		t.Fatalf("parseDocument() error = %v", err)
+	}
+	if _, ok := got["PASSWORD"]; ok || got["HOST"] != "db.internal" {
+		t.Errorf("parseDocument() got = %v, want PASSWORD unset", got)
+	}
+}
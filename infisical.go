@@ -0,0 +1,77 @@
+package env
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+// InfisicalConfig locates the secrets served by NewInfisicalProvider
+type InfisicalConfig struct {
+	// SiteURL is the address of the Infisical instance,
+	// https://app.infisical.com by default
+	SiteURL string
+	// Token is a service token with read access to the secrets
+	Token string
+	// WorkspaceID is the ID of the project
+	WorkspaceID string
+	// Environment is the slug of the environment, such as "dev" or "prod"
+	Environment string
+	// SecretPath is the folder holding the secrets, "/" by default
+	SecretPath string
+}
+
+// NewInfisicalProvider returns a provider serving the secrets of an Infisical
+// project environment, fetched from its API like any HTTPProvider document.
+// Secrets imported into the folder are served too, those of the folder itself
+// taking precedence.
+func NewInfisicalProvider(cfg InfisicalConfig, opts ...HTTPOption) *HTTPProvider {
+	siteURL := strings.TrimSuffix(cfg.SiteURL, "/")
+	if siteURL == "" {
+		siteURL = "https://app.infisical.com"
+	}
+	secretPath := cfg.SecretPath
+	if secretPath == "" {
+		secretPath = "/"
+	}
+	query := url.Values{
+		"workspaceId": {cfg.WorkspaceID},
+		"environment": {cfg.Environment},
+		"secretPath":  {secretPath},
+	}
+
+	h := NewHTTPProvider(siteURL+"/api/v3/secrets/raw?"+query.Encode(), append([]HTTPOption{HTTPBearerToken(cfg.Token)}, opts...)...)
+	h.parse = parseInfisicalSecrets
+	return h
+}
+
+type infisicalSecret struct {
+	Key   string `json:"secretKey"`
+	Value string `json:"secretValue"`
+}
+
+// parseInfisicalSecrets decodes the response of the raw secrets endpoint
+func parseInfisicalSecrets(body []byte, _ string) (map[string]string, error) {
+	var resp struct {
+		Secrets []infisicalSecret `json:"secrets"`
+		Imports []struct {
+			Secrets []infisicalSecret `json:"secrets"`
+		} `json:"imports"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]string, len(resp.Secrets))
+	for _, imported := range resp.Imports {
+		for _, secret := range imported.Secrets {
+			if _, ok := vars[secret.Key]; !ok {
+				vars[secret.Key] = secret.Value
+			}
+		}
+	}
+	for _, secret := range resp.Secrets {
+		vars[secret.Key] = secret.Value
+	}
+	return vars, nil
+}
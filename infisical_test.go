@@ -0,0 +1,43 @@
+package env
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInfisicalProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v3/secrets/raw" || r.Header.Get("Authorization") != "Bearer st.token" ||
+			q.Get("workspaceId") != "ws1" || q.Get("environment") != "prod" || q.Get("secretPath") != "/" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{
+			"secrets": [{"secretKey": "DB_PASSWORD", "secretValue": "hunter2"}, {"secretKey": "SHARED", "secretValue": "own"}],
+			"imports": [{"secrets": [{"secretKey": "SHARED", "secretValue": "imported"}, {"secretKey": "REGION", "secretValue": "eu"}]}]
+		}`)
+	}))
+	defer server.Close()
+
+	p := NewInfisicalProvider(InfisicalConfig{
+		SiteURL:     server.URL,
+		Token:       "st.token",
+		WorkspaceID: "ws1",
+		Environment: "prod",
+	}, HTTPClient(server.Client()))
+
+	for name, want := range map[string]string{"DB_PASSWORD": "hunter2", "SHARED": "own", "REGION": "eu"} {
+		if value, found := p.Lookup(name); !found || value != want {
+			t.Errorf("Lookup(%s) got = %q, %v, want %q", name, value, found, want)
+		}
+	}
+
+	bad := NewInfisicalProvider(InfisicalConfig{SiteURL: server.URL, Token: "wrong"}, HTTPClient(server.Client()))
+	if err := bad.Refresh(t.Context()); err == nil {
+		t.Error("Refresh() expected an error for a rejected token")
+	}
+}
@@ -0,0 +1,295 @@
+// Package yaml reads the subset of YAML used by go-env's configuration files:
+// block mappings and sequences, flow sequences of scalars, plain and quoted
+// scalars and comments. Anchors, tags, multi-line scalars and flow mappings
+// are not supported.
+package yaml
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Unmarshal parses data and stores the result in the value pointed to by v,
+// following the rules of encoding/json. JSON documents, which are valid YAML,
+// are decoded directly.
+func Unmarshal(data []byte, v any) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return json.Unmarshal(data, v)
+	}
+	value, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, v)
+}
+
+// Parse parses data into maps of type map[string]any, slices of type []any,
+// and scalars. Scalars are strings, except for true, false and null.
+func Parse(data []byte) (any, error) {
+	var lines []line
+	for i, text := range strings.Split(string(data), "\n") {
+		text = stripComment(strings.TrimRight(text, " \t\r"))
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, fmt.Errorf("line %d: tabs are not allowed in indentation", i+1)
+		}
+		lines = append(lines, line{number: i + 1, indent: len(text) - len(trimmed), text: trimmed})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{lines: lines}
+	value, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, p.errorf("unexpected indentation")
+	}
+	return value, nil
+}
+
+// line is a non-empty line of the document, without its indentation
+type line struct {
+	number int
+	indent int
+	text   string
+}
+
+type parser struct {
+	lines []line
+	pos   int
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.lines[p.pos].number, fmt.Sprintf(format, args...))
+}
+
+// block parses the mapping or sequence starting at the current line
+func (p *parser) block(indent int) (any, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *parser) mapping(indent int) (any, error) {
+	m := make(map[string]any)
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		l := p.lines[p.pos]
+		key, rest, ok := splitKey(l.text)
+		if !ok {
+			return nil, p.errorf("expected a key")
+		}
+		if _, dup := m[key]; dup {
+			return nil, p.errorf("duplicate key %q", key)
+		}
+		p.pos++
+
+		if rest != "" {
+			value, err := scalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", l.number, err)
+			}
+			m[key] = value
+			continue
+		}
+
+		// A nested block is indented, except for sequences which may start
+		// at the indentation of their key
+		switch {
+		case p.pos < len(p.lines) && p.lines[p.pos].indent > indent:
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		case p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text):
+			value, err := p.sequence(indent)
+			if err != nil {
+				return nil, err
+			}
+			m[key] = value
+		default:
+			m[key] = nil
+		}
+	}
+	return m, nil
+}
+
+func (p *parser) sequence(indent int) (any, error) {
+	s := []any{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		l := p.lines[p.pos]
+		item := strings.TrimLeft(l.text[1:], " ")
+		if item == "" {
+			p.pos++
+			if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+				s = append(s, nil)
+				continue
+			}
+			value, err := p.block(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+			continue
+		}
+
+		if _, _, ok := splitKey(item); ok && !strings.HasPrefix(item, "[") {
+			// "- key: value" starts a mapping indented past the dash
+			itemIndent := indent + len(l.text) - len(item)
+			p.lines[p.pos] = line{number: l.number, indent: itemIndent, text: item}
+			value, err := p.mapping(itemIndent)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, value)
+			continue
+		}
+
+		value, err := scalar(item)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", l.number, err)
+		}
+		s = append(s, value)
+		p.pos++
+	}
+	return s, nil
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitKey splits "key: value" into its key and value
+func splitKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end == -1 || end+1 >= len(text) || text[end+1] != ':' {
+			return "", "", false
+		}
+		key, err := scalar(text[:end+1])
+		if err != nil {
+			return "", "", false
+		}
+		return key.(string), strings.TrimSpace(text[end+2:]), true
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), i > 0
+		}
+	}
+	return "", "", false
+}
+
+// scalar parses a scalar or a flow sequence of scalars
+func scalar(text string) (any, error) {
+	switch text[0] {
+	case '[':
+		if !strings.HasSuffix(text, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence")
+		}
+		items := []any{}
+		for _, item := range splitFlow(text[1 : len(text)-1]) {
+			if item == "" {
+				continue
+			}
+			value, err := scalar(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		return items, nil
+	case '{':
+		if text == "{}" {
+			return map[string]any{}, nil
+		}
+		return nil, fmt.Errorf("flow mappings are not supported")
+	case '"':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("malformed quoted scalar %s", text)
+		}
+		return strconv.Unquote(text)
+	case '\'':
+		if closingQuote(text) != len(text)-1 {
+			return nil, fmt.Errorf("malformed quoted scalar %s", text)
+		}
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	case '&', '*', '!', '|', '>':
+		return nil, fmt.Errorf("unsupported syntax %q", text)
+	}
+
+	switch text {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "null", "Null", "NULL", "~":
+		return nil, nil
+	}
+	return text, nil
+}
+
+// splitFlow splits the contents of a flow sequence at the commas outside quotes
+func splitFlow(text string) []string {
+	var items []string
+	start := 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := closingQuote(text[i:]); end != -1 {
+				i += end
+			}
+		case ',':
+			items = append(items, strings.TrimSpace(text[start:i]))
+			start = i + 1
+		}
+	}
+	return append(items, strings.TrimSpace(text[start:]))
+}
+
+// closingQuote returns the index of the quote closing the one text starts with
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case quote == '\'' && text[i] == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing comment from a line
+func stripComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if end := closingQuote(text[i:]); end != -1 {
+				i += end
+			}
+		case '#':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '\t' {
+				return strings.TrimRight(text[:i], " \t")
+			}
+		}
+	}
+	return text
+}
@@ -0,0 +1,81 @@
+package yaml
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr bool
+	}{
+		{
+			name:  "nested mappings",
+			input: "a:\n  b: 1\n  c: 'it''s'\nd: \"x\\ty\"\n",
+			want:  map[string]any{"a": map[string]any{"b": "1", "c": "it's"}, "d": "x\ty"},
+		},
+		{
+			name:  "comments and booleans",
+			input: "# header\na: true # note\nb: a#b\nc: null\n",
+			want:  map[string]any{"a": true, "b": "a#b", "c": nil},
+		},
+		{
+			name:  "sequences",
+			input: "flow: [a, \"b, c\", 'd']\nblock:\n- x\n- y\nnested:\n  - name: n\n    value: v\n",
+			want: map[string]any{
+				"flow":   []any{"a", "b, c", "d"},
+				"block":  []any{"x", "y"},
+				"nested": []any{map[string]any{"name": "n", "value": "v"}},
+			},
+		},
+		{
+			name:  "empty document",
+			input: "# nothing\n",
+			want:  nil,
+		},
+		{
+			name:    "bad indentation",
+			input:   "a: 1\n    b: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "duplicate key",
+			input:   "a: 1\na: 2\n",
+			wantErr: true,
+		},
+		{
+			name:    "anchors",
+			input:   "a: &x 1\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() got = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var v struct {
+		Name  string   `json:"name"`
+		On    bool     `json:"on"`
+		Items []string `json:"items"`
+	}
+	if err := Unmarshal([]byte("name: x\non: true\nitems: [a, b]\n"), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "x" || !v.On || !reflect.DeepEqual(v.Items, []string{"a", "b"}) {
+		t.Errorf("Unmarshal() got = %+v", v)
+	}
+}
@@ -0,0 +1,66 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+)
+
+// KeyringProvider resolves variables from the credential store of the
+// operating system, so that the secrets of local development do not have to
+// be kept in plaintext .env files:
+//
+//   - on macOS, generic passwords of the login Keychain, read with the
+//     security tool, whose service is the service of the provider and whose
+//     account is the name of the variable
+//   - on Windows, generic credentials of the Credential Manager whose target
+//     is "service:name"
+//   - elsewhere, items of the Secret Service, such as GNOME Keyring or
+//     KWallet, read with secret-tool, with the attributes service and account
+//
+// For example, on macOS the value of ${DB_PASSWORD} for the service "myapp"
+// is stored with:
+//
+//	security add-generic-password -s myapp -a DB_PASSWORD -w
+//
+// Every lookup reads the store again; wrap the provider with Cached to avoid
+// repeated reads and prompts.
+type KeyringProvider struct {
+	service string
+	// tool is the command line tool the store is read with, if any
+	tool string
+}
+
+// Keyring returns a provider reading the secrets stored for service
+func Keyring(service string) *KeyringProvider {
+	return &KeyringProvider{service: service, tool: keyringTool}
+}
+
+// Lookup resolves name, reporting read errors as the variable being unset;
+// use LookupContext to observe them
+func (k *KeyringProvider) Lookup(name string) (string, bool) {
+	value, found, _ := k.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext reads the secret stored for name. A secret missing from the
+// store is reported as the variable being unset rather than as an error.
+func (k *KeyringProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	value, found, err := k.read(ctx, name)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %s from the keyring: %w", name, err)
+	}
+	return value, found, nil
+}
+
+// Ping checks that the tool the store is read with is installed, see
+// HealthChecker
+func (k *KeyringProvider) Ping(ctx context.Context) error {
+	if k.tool == "" {
+		return nil
+	}
+	if _, err := exec.LookPath(k.tool); err != nil {
+		return fmt.Errorf("failed to find the keyring tool: %w", err)
+	}
+	return nil
+}
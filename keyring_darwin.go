@@ -0,0 +1,35 @@
+//go:build darwin
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringTool reads the Keychain
+const keyringTool = "security"
+
+// errSecItemNotFound is the exit status of security for a missing item
+const errSecItemNotFound = 44
+
+func (k *KeyringProvider) read(ctx context.Context, name string) (string, bool, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, k.tool, "find-generic-password", "-s", k.service, "-a", name, "-w")
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+			return "", false, nil
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", false, fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", false, err
+	}
+	return strings.TrimSuffix(stdout.String(), "\n"), true, nil
+}
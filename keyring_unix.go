@@ -0,0 +1,34 @@
+//go:build !darwin && !windows
+
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keyringTool reads the Secret Service
+const keyringTool = "secret-tool"
+
+func (k *KeyringProvider) read(ctx context.Context, name string) (string, bool, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, k.tool, "lookup", "service", k.service, "account", name)
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		// secret-tool fails silently when no item matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && msg == "" && stdout.Len() == 0 {
+			return "", false, nil
+		}
+		if msg != "" {
+			return "", false, fmt.Errorf("%w: %s", err, msg)
+		}
+		return "", false, err
+	}
+	return stdout.String(), true, nil
+}
@@ -0,0 +1,56 @@
+//go:build !darwin && !windows
+
+package env
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestKeyringHelperProcess impersonates secret-tool
+func TestKeyringHelperProcess(t *testing.T) {
+	if os.Getenv("GO_ENV_KEYRING_HELPER") != "1" {
+		return
+	}
+	args := os.Args[len(os.Args)-5:]
+	switch strings.Join(args, " ") {
+	case "lookup service myapp account DB_PASSWORD":
+		fmt.Print("hunter2")
+	case "lookup service myapp account LOCKED":
+		fmt.Fprint(os.Stderr, "secret-tool: Cannot autolaunch D-Bus without X11 $DISPLAY")
+		os.Exit(1)
+	default:
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestKeyring(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	t.Setenv("GO_ENV_KEYRING_HELPER", "1")
+	script := t.TempDir() + "/secret-tool"
+	content := fmt.Sprintf("#!/bin/sh\nexec %q -test.run=TestKeyringHelperProcess -- \"$@\"\n", os.Args[0])
+	if err := os.WriteFile(script, []byte(content), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	k := Keyring("myapp")
+	k.tool = script
+
+	if value, found := k.Lookup("DB_PASSWORD"); !found || value != "hunter2" {
+		t.Errorf("Lookup(DB_PASSWORD) got = %q, %v", value, found)
+	}
+	if value, found, err := k.LookupContext(context.Background(), "MISSING"); err != nil || found {
+		t.Errorf("LookupContext(MISSING) got = %q, %v, %v, want unset", value, found, err)
+	}
+	if _, _, err := k.LookupContext(context.Background(), "LOCKED"); err == nil || !strings.Contains(err.Error(), "D-Bus") {
+		t.Errorf("LookupContext(LOCKED) error = %v, want the error of secret-tool", err)
+	}
+	if got, err := Expand("postgres://app:${DB_PASSWORD}@db", k); err != nil || got != "postgres://app:hunter2@db" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+}
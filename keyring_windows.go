@@ -0,0 +1,78 @@
+//go:build windows
+
+package env
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"unicode/utf16"
+	"unsafe"
+)
+
+// keyringTool is unused, as the Credential Manager is read through its API
+const keyringTool = ""
+
+var (
+	procCredReadW = modadvapi32.NewProc("CredReadW")
+	procCredFree  = modadvapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric      = 1
+	errorNotFound        = syscall.Errno(1168)
+	errorNoSuchLogonSess = syscall.Errno(1312)
+)
+
+// credential is the layout of CREDENTIALW
+type credential struct {
+	flags              uint32
+	typ                uint32
+	targetName         *uint16
+	comment            *uint16
+	lastWritten        syscall.Filetime
+	credentialBlobSize uint32
+	credentialBlob     *byte
+	persist            uint32
+	attributeCount     uint32
+	attributes         uintptr
+	targetAlias        *uint16
+	userName           *uint16
+}
+
+func (k *KeyringProvider) read(_ context.Context, name string) (string, bool, error) {
+	target, err := syscall.UTF16PtrFromString(k.service + ":" + name)
+	if err != nil {
+		return "", false, err
+	}
+	var cred *credential
+	r, _, err := procCredReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if r == 0 {
+		if errors.Is(err, errorNotFound) || errors.Is(err, errorNoSuchLogonSess) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.credentialBlobSize == 0 {
+		return "", true, nil
+	}
+	return decodeCredentialBlob(unsafe.Slice(cred.credentialBlob, cred.credentialBlobSize)), true, nil
+}
+
+// decodeCredentialBlob decodes a secret, which the Credential Manager and
+// cmdkey store as UTF-16 and other tools as UTF-8. Blobs are taken for
+// UTF-16 when every other byte is zero, as with ASCII text.
+func decodeCredentialBlob(blob []byte) string {
+	if len(blob)%2 != 0 {
+		return string(blob)
+	}
+	units := make([]uint16, len(blob)/2)
+	for i := range units {
+		if blob[2*i+1] != 0 {
+			return string(blob)
+		}
+		units[i] = uint16(blob[2*i])
+	}
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package env
+
+// knownFolder reports no folder outside Windows
+func knownFolder(string) (string, bool) {
+	return "", false
+}
@@ -0,0 +1,68 @@
+//go:build windows
+
+package env
+
+import (
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	modshell32               = syscall.NewLazyDLL("shell32.dll")
+	modole32                 = syscall.NewLazyDLL("ole32.dll")
+	procSHGetKnownFolderPath = modshell32.NewProc("SHGetKnownFolderPath")
+	procCoTaskMemFree        = modole32.NewProc("CoTaskMemFree")
+)
+
+// guid is the layout of a Windows GUID, such as a FOLDERID
+type guid struct {
+	data1 uint32
+	data2 uint16
+	data3 uint16
+	data4 [8]byte
+}
+
+// knownFolders maps the upper-cased names of the variables Windows sets for
+// well-known folders to the FOLDERID of each folder
+var knownFolders = map[string]guid{
+	"APPDATA":                 {0x3eb685db, 0x65f9, 0x4cf6, [8]byte{0xa0, 0x3a, 0xe3, 0xef, 0x65, 0x72, 0x9f, 0x3d}},
+	"LOCALAPPDATA":            {0xf1b32785, 0x6fba, 0x4fcf, [8]byte{0x9d, 0x55, 0x7b, 0x8e, 0x7f, 0x15, 0x70, 0x91}},
+	"PROGRAMDATA":             {0x62ab5d82, 0xfdc1, 0x4dc3, [8]byte{0xa9, 0xdd, 0x07, 0x0d, 0x1d, 0x49, 0x5d, 0x97}},
+	"ALLUSERSPROFILE":         {0x62ab5d82, 0xfdc1, 0x4dc3, [8]byte{0xa9, 0xdd, 0x07, 0x0d, 0x1d, 0x49, 0x5d, 0x97}},
+	"PROGRAMFILES":            {0x905e63b6, 0xc1bf, 0x494e, [8]byte{0xb2, 0xea, 0x6b, 0xa6, 0x56, 0x5b, 0xf5, 0xbc}},
+	"PROGRAMFILES(X86)":       {0x7c5a40ef, 0xa0fb, 0x4bfc, [8]byte{0x87, 0x4a, 0xc0, 0xf2, 0xe0, 0xb9, 0xfa, 0x8e}},
+	"COMMONPROGRAMFILES":      {0xf7f1ed05, 0x9f6d, 0x47a2, [8]byte{0xaa, 0xae, 0x29, 0xd3, 0x17, 0xc6, 0xf0, 0x66}},
+	"COMMONPROGRAMFILES(X86)": {0xde974d24, 0xd9c6, 0x4d3e, [8]byte{0xbf, 0x91, 0xf4, 0x45, 0x51, 0x20, 0xb9, 0x17}},
+	"USERPROFILE":             {0x5e6c858f, 0x0e22, 0x4760, [8]byte{0x9a, 0xfe, 0xea, 0x33, 0x17, 0xb6, 0x71, 0x73}},
+	"PUBLIC":                  {0xdfdf76a2, 0xc82a, 0x4d63, [8]byte{0x90, 0x6a, 0x56, 0x44, 0xac, 0x45, 0x73, 0x85}},
+	"SYSTEMROOT":              {0xf38bf404, 0x1d43, 0x42f2, [8]byte{0x93, 0x05, 0x67, 0xde, 0x0b, 0x28, 0xfc, 0x23}},
+	"WINDIR":                  {0xf38bf404, 0x1d43, 0x42f2, [8]byte{0x93, 0x05, 0x67, 0xde, 0x0b, 0x28, 0xfc, 0x23}},
+}
+
+// knownFolder returns the path of the well-known folder the variable name
+// stands for, if it is one
+func knownFolder(name string) (string, bool) {
+	id, ok := knownFolders[strings.ToUpper(name)]
+	if !ok {
+		return "", false
+	}
+	var path *uint16
+	hr, _, _ := procSHGetKnownFolderPath.Call(uintptr(unsafe.Pointer(&id)), 0, 0, uintptr(unsafe.Pointer(&path)))
+	if path != nil {
+		defer procCoTaskMemFree.Call(uintptr(unsafe.Pointer(path)))
+	}
+	if hr != 0 || path == nil {
+		return "", false
+	}
+	return utf16PtrToString(path), true
+}
+
+// utf16PtrToString converts a NUL-terminated UTF-16 string allocated by Windows
+func utf16PtrToString(p *uint16) string {
+	n := 0
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; n++ {
+		ptr = unsafe.Add(ptr, 2)
+	}
+	return syscall.UTF16ToString(unsafe.Slice(p, n))
+}
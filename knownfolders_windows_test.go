@@ -0,0 +1,23 @@
+//go:build windows
+
+package env
+
+import "testing"
+
+func TestWithKnownFolders(t *testing.T) {
+	for _, name := range []string{"APPDATA", "LocalAppData", "ProgramFiles", "SystemRoot"} {
+		got, err := Expand("${"+name+"}", Map{}, WithKnownFolders())
+		if err != nil || got == "" {
+			t.Errorf("Expand(%s) got = %q, %v, want the known folder", name, got, err)
+		}
+	}
+	if got, err := Expand("${APPDATA}", Map{"APPDATA": `C:\custom`}, WithKnownFolders()); err != nil || got != `C:\custom` {
+		t.Errorf("Expand(APPDATA) got = %q, %v, want the provider's value", got, err)
+	}
+	if got, err := Expand("[${APPDATA}]", Map{}); err != nil || got != "[]" {
+		t.Errorf("Expand(APPDATA) got = %q, %v without WithKnownFolders", got, err)
+	}
+	if _, found := knownFolder("NOT_A_FOLDER"); found {
+		t.Error("knownFolder() found an unknown name")
+	}
+}
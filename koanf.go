@@ -0,0 +1,133 @@
+package env
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// KoanfProvider exposes a Provider as a koanf provider: it implements the
+// koanf.Provider interface (Read and ReadBytes) without depending on koanf.
+// Variable names are turned into keys with keyName, so DB_HOST becomes db.host
+// by default, and the result is nested on '.'.
+//
+// With viper, ReadBytes can be fed to viper.ReadConfig after
+// SetConfigType("json"), or the viperenv module serves providers as viper
+// remote configuration.
+type KoanfProvider struct {
+	provider Provider
+	keyName  func(name string) string
+}
+
+// NewKoanfProvider wraps p, which must implement Lister to be read in full.
+// If keyName is nil, names are lower-cased and '_' is replaced with '.'.
+func NewKoanfProvider(p Provider, keyName func(name string) string) *KoanfProvider {
+	if keyName == nil {
+		keyName = defaultKeyName
+	}
+	return &KoanfProvider{provider: p, keyName: keyName}
+}
+
+// Read returns the variables of the provider as a nested map
+func (k *KoanfProvider) Read() (map[string]any, error) {
+	lister, ok := k.provider.(Lister)
+	if !ok {
+		return nil, errors.New("provider cannot enumerate its variables")
+	}
+	flat := make(map[string]string)
+	for _, kv := range lister.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if name != "" {
+			flat[k.keyName(name)] = value
+		}
+	}
+	return unflatten(flat, "."), nil
+}
+
+// ReadBytes returns the variables of the provider as a nested JSON object
+func (k *KoanfProvider) ReadBytes() ([]byte, error) {
+	nested, err := k.Read()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(nested)
+}
+
+// Ping checks the wrapped provider, see HealthChecker
+func (k *KoanfProvider) Ping(ctx context.Context) error {
+	return ping(ctx, k.provider)
+}
+
+// koanfStore is the subset of *koanf.Koanf used by FromKoanf
+type koanfStore interface {
+	Exists(path string) bool
+	String(path string) string
+}
+
+// viperStore is the subset of *viper.Viper used by FromViper
+type viperStore interface {
+	IsSet(key string) bool
+	GetString(key string) string
+}
+
+// keyedProvider resolves variables from a key/value configuration store
+type keyedProvider struct {
+	exists  func(key string) bool
+	get     func(key string) string
+	keyName func(name string) string
+}
+
+func (p *keyedProvider) Lookup(name string) (string, bool) {
+	key := p.keyName(name)
+	if !p.exists(key) {
+		return "", false
+	}
+	return p.get(key), true
+}
+
+// FromKoanf returns a Provider resolving variables from a *koanf.Koanf instance.
+// Names are turned into koanf paths with keyName, see NewKoanfProvider.
+func FromKoanf(k koanfStore, keyName func(name string) string) Provider {
+	if keyName == nil {
+		keyName = defaultKeyName
+	}
+	return &keyedProvider{exists: k.Exists, get: k.String, keyName: keyName}
+}
+
+// FromViper returns a Provider resolving variables from a *viper.Viper instance.
+// Names are turned into viper keys with keyName, see NewKoanfProvider.
+func FromViper(v viperStore, keyName func(name string) string) Provider {
+	if keyName == nil {
+		keyName = defaultKeyName
+	}
+	return &keyedProvider{exists: v.IsSet, get: v.GetString, keyName: keyName}
+}
+
+// defaultKeyName maps DB_HOST to db.host
+func defaultKeyName(name string) string {
+	return FromEnvName(name, ".")
+}
+
+// unflatten nests the keys of flat on sep. When a key is both a value and a
+// parent of other keys, the value is dropped in favor of the nested keys.
+func unflatten(flat map[string]string, sep string) map[string]any {
+	nested := make(map[string]any)
+	for key, value := range flat {
+		parts := strings.Split(key, sep)
+		node := nested
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := node[part].(map[string]any)
+			if !ok {
+				child = make(map[string]any)
+				node[part] = child
+			}
+			node = child
+		}
+		last := parts[len(parts)-1]
+		if _, isParent := node[last].(map[string]any); !isParent {
+			node[last] = value
+		}
+	}
+	return nested
+}
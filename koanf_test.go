@@ -0,0 +1,61 @@
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestKoanfProvider(t *testing.T) {
+	k := NewKoanfProvider(Map{"DB_HOST": "db.internal", "DB_PORT": "5432", "DEBUG": "true"}, nil)
+
+	got, err := k.Read()
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	want := map[string]any{
+		"db":    map[string]any{"host": "db.internal", "port": "5432"},
+		"debug": "true",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() got = %v, want %v", got, want)
+	}
+
+	data, err := k.ReadBytes()
+	if err != nil {
+		t.Fatalf("ReadBytes() error = %v", err)
+	}
+	var decoded map[string]any
+	if err := json.Unmarshal(data, &decoded); err != nil || !reflect.DeepEqual(decoded, want) {
+		t.Errorf("ReadBytes() got = %s, %v", data, err)
+	}
+
+	if _, err := NewKoanfProvider(lookupOnly{}, nil).Read(); err == nil {
+		t.Error("Read() expected an error for a provider that cannot list its variables")
+	}
+}
+
+// fakeStore mimics the key/value accessors of koanf and viper
+type fakeStore map[string]string
+
+func (f fakeStore) Exists(path string) bool     { _, ok := f[path]; return ok }
+func (f fakeStore) String(path string) string   { return f[path] }
+func (f fakeStore) IsSet(key string) bool       { return f.Exists(key) }
+func (f fakeStore) GetString(key string) string { return f[key] }
+
+func TestFromKoanfAndViper(t *testing.T) {
+	store := fakeStore{"db.host": "db.internal", "server.port": "8080"}
+
+	for name, p := range map[string]Provider{
+		"koanf": FromKoanf(store, nil),
+		"viper": FromViper(store, nil),
+	} {
+		got, err := Expand("${DB_HOST}:${SERVER_PORT}:${MISSING:-none}", p)
+		if err != nil {
+			t.Fatalf("%s: Expand() error = %v", name, err)
+		}
+		if want := "db.internal:8080:none"; got != want {
+			t.Errorf("%s: Expand() got = %v, want %v", name, got, want)
+		}
+	}
+}
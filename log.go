@@ -0,0 +1,43 @@
+package env
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger logs the assignments performed by ${var:=default}, the failures
+// of ${var:?message} and provider errors to logger. Values of secret
+// variables are redacted, see WithRedaction.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = logger
+	}
+}
+
+func (e *expander) logAssignment(name, value string) {
+	if e.cfg.logger == nil {
+		return
+	}
+	e.cfg.logger.LogAttrs(context.Background(), slog.LevelInfo, "assigned default value to variable",
+		slog.String("name", name),
+		slog.String("value", e.redact(name, value)))
+}
+
+func (e *expander) logRequired(name, message string) {
+	if e.cfg.logger == nil {
+		return
+	}
+	e.cfg.logger.LogAttrs(context.Background(), slog.LevelWarn, "required variable is unset or empty",
+		slog.String("name", name),
+		slog.String("message", message))
+}
+
+func (e *expander) logError(name string, err error) {
+	if e.cfg.logger == nil {
+		return
+	}
+	e.cfg.logger.LogAttrs(context.Background(), slog.LevelError, "provider error",
+		slog.String("name", name),
+		slog.String("provider", providerName(e.cfg.provider)),
+		slog.Any("error", err))
+}
@@ -0,0 +1,82 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// failingProvider is a ContextProvider whose lookups always fail
+type failingProvider struct{}
+
+func (failingProvider) Lookup(string) (string, bool) { return "", false }
+
+func (failingProvider) LookupContext(context.Context, string) (string, bool, error) {
+	return "", false, errors.New("backend unavailable")
+}
+
+func TestWithLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	x := NewExpander(WithProvider(Map{}), WithLogger(logger))
+
+	if _, err := x.Expand("${LEVEL:=debug} ${DB_PASSWORD:=hunter2}"); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if _, err := x.Expand("${REQUIRED:?must be set}"); err == nil {
+		t.Fatal("Expand() expected an error for a missing required variable")
+	}
+
+	_, err := NewExpander(WithProvider(failingProvider{}), WithLogger(logger)).Expand("$REMOTE")
+	if err == nil || !strings.Contains(err.Error(), "backend unavailable") {
+		t.Fatalf("Expand() error = %v, want the provider error", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		`level=INFO msg="assigned default value to variable" name=LEVEL value=debug`,
+		`level=INFO msg="assigned default value to variable" name=DB_PASSWORD value=****`,
+		`level=WARN msg="required variable is unset or empty" name=REQUIRED message="must be set"`,
+		`level=ERROR msg="provider error" name=REMOTE provider=env.failingProvider error="backend unavailable"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("log output leaks a secret value:\n%s", out)
+	}
+}
+
+func TestWithRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	x := NewExpander(
+		WithProvider(Map{}),
+		WithLogger(slog.New(slog.NewTextHandler(&buf, nil))),
+		WithRedaction(func(name string) bool { return name == "PRIVATE" }),
+	)
+	if _, err := x.Expand("${PRIVATE:=a} ${DB_PASSWORD:=b}"); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "name=PRIVATE value=****") || !strings.Contains(out, "name=DB_PASSWORD value=b") {
+		t.Errorf("custom redaction was not honored:\n%s", out)
+	}
+}
+
+func TestIsSecretName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"DB_PASSWORD":   true,
+		"github_token":  true,
+		"AWS_SECRET":    true,
+		"STRIPE_APIKEY": true,
+		"HOME":          false,
+		"AUTHOR":        false,
+	} {
+		if got := IsSecretName(name); got != want {
+			t.Errorf("IsSecretName(%q) got = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package env
+
+// Mapper returns a mapping function for os.Expand resolving variables like an
+// Expander configured by opts, so that code built on os.Expand gains the
+// operators and providers of this package:
+//
+//	os.Expand("${HOST}:${PORT:-5432}", env.Mapper(env.WithProvider(vars)))
+//
+// os.Expand passes the whole content of ${...} to the mapping function,
+// operators included. As the mapping cannot fail, a reference whose expansion
+// fails, such as ${var:?message} with var unset, is replaced by an empty
+// string; use Expand to get the error instead.
+func Mapper(opts ...Option) func(string) string {
+	x := NewExpander(opts...)
+	return func(ref string) string {
+		e := &expander{cfg: &x.cfg, pure: x.cfg.pure}
+		e.startDeadline()
+		value, err := e.expandBracedContent(ref, 0)
+		if err == nil {
+			err = e.commit()
+		}
+		if err != nil {
+			return ""
+		}
+		return value
+	}
+}
+
+// MapperProvider wraps a mapping function written for os.Expand as a Provider.
+// As such functions cannot tell unset variables from empty ones, a variable is
+// reported as present if its value is not empty.
+func MapperProvider(mapping func(string) string) Provider {
+	return mapperProvider(mapping)
+}
+
+type mapperProvider func(string) string
+
+func (m mapperProvider) Lookup(name string) (string, bool) {
+	value := m(name)
+	return value, value != ""
+}
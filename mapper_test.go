@@ -0,0 +1,45 @@
+package env
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMapper(t *testing.T) {
+	vars := Map{"HOST": "db", "NAME": "app"}
+	mapping := Mapper(WithProvider(vars))
+
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"$HOST:${PORT:-5432}", "db:5432"},
+		{"${NAME@U}", "APP"},
+		{"${NAME:+set}${MISSING:+set}", "set"},
+		{"[${MISSING:?required}]", "[]"},
+		{"${USER:=root}@$HOST $USER", "root@db root"},
+	}
+	for _, tt := range tests {
+		if got := os.Expand(tt.input, mapping); got != tt.want {
+			t.Errorf("os.Expand(%q) got = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+	if vars["USER"] != "root" {
+		t.Errorf("Mapper() did not assign USER: %v", vars)
+	}
+}
+
+func TestMapperProvider(t *testing.T) {
+	p := MapperProvider(strings.ToLower)
+	if value, found := p.Lookup("HOST"); !found || value != "host" {
+		t.Errorf("Lookup(HOST) got = %q, %v", value, found)
+	}
+	if _, found := p.Lookup(""); found {
+		t.Error("Lookup() reported an empty value as present")
+	}
+	mapping := func(name string) string { return Map{"HOST": "db", "EMPTY": ""}[name] }
+	if got, err := Expand("${HOST}:${EMPTY:-5432}", MapperProvider(mapping)); err != nil || got != "db:5432" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+}
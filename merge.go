@@ -0,0 +1,99 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// MergeStrategy decides the resulting value of a variable that is present in
+// both maps given to Merge
+type MergeStrategy func(name, existing, incoming string) (string, error)
+
+// ConflictError is returned by the ErrorOnConflict strategy when a variable
+// has different values in the merged maps
+type ConflictError struct {
+	Name string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("conflicting values for variable '%s'", e.Name)
+}
+
+var (
+	// FirstWins keeps the value already present in the destination
+	FirstWins MergeStrategy = func(_, existing, _ string) (string, error) {
+		return existing, nil
+	}
+
+	// LastWins replaces the value in the destination with the incoming one
+	LastWins MergeStrategy = func(_, _, incoming string) (string, error) {
+		return incoming, nil
+	}
+
+	// ErrorOnConflict fails with a *ConflictError if the values differ
+	ErrorOnConflict MergeStrategy = func(name, existing, incoming string) (string, error) {
+		if existing != incoming {
+			return "", &ConflictError{Name: name}
+		}
+		return existing, nil
+	}
+)
+
+// AppendWith returns a strategy joining the existing and incoming values with
+// sep, as done for list variables such as PATH. If names are given, only those
+// variables are joined and the others are merged with LastWins.
+// Empty and identical values are not joined, to avoid producing stray separators
+// and duplicated entries.
+func AppendWith(sep string, names ...string) MergeStrategy {
+	only := make(map[string]bool, len(names))
+	for _, name := range names {
+		only[name] = true
+	}
+	return func(name, existing, incoming string) (string, error) {
+		switch {
+		case len(only) > 0 && !only[name]:
+			return incoming, nil
+		case existing == "":
+			return incoming, nil
+		case incoming == "", incoming == existing:
+			return existing, nil
+		}
+		return existing + sep + incoming, nil
+	}
+}
+
+// Merge copies the variables of src into dst. Variables present in both maps
+// are resolved with strategy. If the strategy fails for any variable, dst is
+// left unmodified and the errors of all conflicting variables are returned.
+func Merge(dst, src map[string]string, strategy MergeStrategy) error {
+	names := make([]string, 0, len(src))
+	for name := range src {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	merged := make(map[string]string, len(src))
+	var errs []error
+	for _, name := range names {
+		existing, ok := dst[name]
+		if !ok {
+			merged[name] = src[name]
+			continue
+		}
+		value, err := strategy(name, existing, src[name])
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		merged[name] = value
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	for name, value := range merged {
+		dst[name] = value
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy MergeStrategy
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "first wins",
+			strategy: FirstWins,
+			want:     map[string]string{"PATH": "/bin", "HOME": "/root", "USER": "root", "SHELL": "sh"},
+		},
+		{
+			name:     "last wins",
+			strategy: LastWins,
+			want:     map[string]string{"PATH": "/usr/bin", "HOME": "/home/user", "USER": "root", "SHELL": "sh"},
+		},
+		{
+			name:     "error on conflict",
+			strategy: ErrorOnConflict,
+			want:     map[string]string{"PATH": "/bin", "HOME": "/root", "USER": "root"},
+			wantErr:  true,
+		},
+		{
+			name:     "append path only",
+			strategy: AppendWith(":", "PATH"),
+			want:     map[string]string{"PATH": "/bin:/usr/bin", "HOME": "/home/user", "USER": "root", "SHELL": "sh"},
+		},
+		{
+			name:     "append all",
+			strategy: AppendWith(","),
+			want:     map[string]string{"PATH": "/bin,/usr/bin", "HOME": "/root,/home/user", "USER": "root", "SHELL": "sh"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := map[string]string{"PATH": "/bin", "HOME": "/root", "USER": "root"}
+			src := map[string]string{"PATH": "/usr/bin", "HOME": "/home/user", "USER": "root", "SHELL": "sh"}
+			err := Merge(dst, src, tt.strategy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Merge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(dst, tt.want) {
+				t.Errorf("Merge() got = %v, want %v", dst, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeConflictError(t *testing.T) {
+	err := Merge(map[string]string{"A": "1", "B": "1"}, map[string]string{"A": "2", "B": "2"}, ErrorOnConflict)
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Merge() error = %v, want a *ConflictError", err)
+	}
+	if conflict.Name != "A" {
+		t.Errorf("ConflictError.Name got = %v, want A", conflict.Name)
+	}
+}
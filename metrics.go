@@ -0,0 +1,110 @@
+package env
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Metrics receives measurements about variable resolution.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveLookup is called after every lookup made during expansion
+	ObserveLookup(name string, found bool)
+	// ObserveProviderLatency is called with the time spent in a provider lookup.
+	// provider identifies the provider by its Go type.
+	ObserveProviderLatency(provider string, d time.Duration)
+	// ObserveCache is called by caching providers on every access
+	ObserveCache(hit bool)
+}
+
+// WithMetrics reports the lookups performed during expansion to m
+func WithMetrics(m Metrics) Option {
+	return func(c *config) {
+		c.metrics = m
+	}
+}
+
+// providerName identifies a provider in metrics
+func providerName(p Provider) string {
+	return fmt.Sprintf("%T", p)
+}
+
+// Counters is a Metrics implementation keeping totals in memory
+type Counters struct {
+	lookups    atomic.Int64
+	misses     atomic.Int64
+	cacheHits  atomic.Int64
+	cacheMiss  atomic.Int64
+	mu         sync.Mutex
+	latencies  map[string]time.Duration
+	latencyNum map[string]int64
+}
+
+// CountersSnapshot is a point-in-time copy of Counters
+type CountersSnapshot struct {
+	Lookups     int64
+	Misses      int64
+	CacheHits   int64
+	CacheMisses int64
+	// ProviderLatency holds the total time spent in each provider
+	ProviderLatency map[string]time.Duration
+	// ProviderCalls holds the number of lookups made to each provider
+	ProviderCalls map[string]int64
+}
+
+// CacheHitRatio returns the fraction of cache accesses that were hits,
+// or 0 if the cache was never accessed
+func (s CountersSnapshot) CacheHitRatio() float64 {
+	total := s.CacheHits + s.CacheMisses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.CacheHits) / float64(total)
+}
+
+func (c *Counters) ObserveLookup(_ string, found bool) {
+	c.lookups.Add(1)
+	if !found {
+		c.misses.Add(1)
+	}
+}
+
+func (c *Counters) ObserveProviderLatency(provider string, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.latencies == nil {
+		c.latencies = make(map[string]time.Duration)
+		c.latencyNum = make(map[string]int64)
+	}
+	c.latencies[provider] += d
+	c.latencyNum[provider]++
+}
+
+func (c *Counters) ObserveCache(hit bool) {
+	if hit {
+		c.cacheHits.Add(1)
+	} else {
+		c.cacheMiss.Add(1)
+	}
+}
+
+// Snapshot returns the current totals
+func (c *Counters) Snapshot() CountersSnapshot {
+	s := CountersSnapshot{
+		Lookups:         c.lookups.Load(),
+		Misses:          c.misses.Load(),
+		CacheHits:       c.cacheHits.Load(),
+		CacheMisses:     c.cacheMiss.Load(),
+		ProviderLatency: make(map[string]time.Duration),
+		ProviderCalls:   make(map[string]int64),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for provider, d := range c.latencies {
+		s.ProviderLatency[provider] = d
+		s.ProviderCalls[provider] = c.latencyNum[provider]
+	}
+	return s
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	var counters Counters
+	base := Map{"SET": "value"}
+	cache := Cached(base, 0)
+	cache.SetMetrics(&counters)
+
+	x := NewExpander(WithProvider(cache), WithMetrics(&counters))
+	for i := 0; i < 2; i++ {
+		if _, err := x.Expand("$SET $UNSET"); err != nil {
+			t.Fatalf("Expand() error = %v", err)
+		}
+	}
+
+	s := counters.Snapshot()
+	if s.Lookups != 4 || s.Misses != 2 {
+		t.Errorf("lookups/misses got = %d/%d, want 4/2", s.Lookups, s.Misses)
+	}
+	if s.CacheHits != 2 || s.CacheMisses != 2 || s.CacheHitRatio() != 0.5 {
+		t.Errorf("cache hits/misses got = %d/%d, want 2/2", s.CacheHits, s.CacheMisses)
+	}
+	if calls := s.ProviderCalls["*env.CachedProvider"]; calls != 4 {
+		t.Errorf("provider calls got = %d, want 4", calls)
+	}
+}
+
+func TestCachedProvider(t *testing.T) {
+	base := Map{"NAME": "first"}
+	cache := Cached(base, time.Minute)
+	now := time.Unix(0, 0)
+	cache.now = func() time.Time { return now }
+
+	if got, _ := cache.Lookup("NAME"); got != "first" {
+		t.Fatalf("Lookup() got = %v, want first", got)
+	}
+
+	base["NAME"] = "second"
+	if got, _ := cache.Lookup("NAME"); got != "first" {
+		t.Errorf("Lookup() got = %v, want the cached value first", got)
+	}
+
+	now = now.Add(time.Minute)
+	if got, _ := cache.Lookup("NAME"); got != "second" {
+		t.Errorf("Lookup() got = %v, want second after expiry", got)
+	}
+
+	base["NAME"] = "third"
+	cache.Invalidate("NAME")
+	if got, _ := cache.Lookup("NAME"); got != "third" {
+		t.Errorf("Lookup() got = %v, want third after invalidation", got)
+	}
+
+	if err := cache.Set("NAME", "fourth"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if got, _ := cache.Lookup("NAME"); got != "fourth" || base["NAME"] != "fourth" {
+		t.Errorf("Set() did not update both cache and base, got = %v, base = %v", got, base["NAME"])
+	}
+}
+
+func TestCachedProviderPrefetch(t *testing.T) {
+	b := &batchMap{Map: Map{"HOST": "db"}}
+	cache := Cached(b, 0)
+	if err := cache.Prefetch(context.Background(), []string{"HOST", "PORT"}); err != nil {
+		t.Fatalf("Prefetch() error = %v", err)
+	}
+	if err := cache.Prefetch(context.Background(), []string{"HOST", "USER"}); err != nil {
+		t.Fatalf("Prefetch() error = %v", err)
+	}
+	if want := [][]string{{"HOST", "PORT"}, {"USER"}}; !reflect.DeepEqual(b.batches, want) {
+		t.Errorf("LookupMany() calls = %v, want %v", b.batches, want)
+	}
+
+	b.Map["PORT"] = "5432"
+	if got, found := cache.Lookup("PORT"); found || len(b.lookups) != 0 {
+		t.Errorf("Lookup() got = %q, %v, want the cached miss", got, found)
+	}
+}
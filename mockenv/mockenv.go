@@ -0,0 +1,217 @@
+// Package mockenv provides a Provider whose responses are scripted per
+// variable, to test code built on go-env providers deterministically.
+package mockenv
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Response is a scripted result of a lookup
+type Response struct {
+	Value string
+	Found bool
+	Err   error
+	// Latency delays the response, unless the context of the lookup ends first
+	Latency time.Duration
+}
+
+// Value returns a response reporting the variable set to value
+func Value(value string) Response {
+	return Response{Value: value, Found: true}
+}
+
+// Unset returns a response reporting the variable as unset
+func Unset() Response {
+	return Response{}
+}
+
+// Fail returns a response failing with err
+func Fail(err error) Response {
+	return Response{Err: err}
+}
+
+// After returns r delayed by latency
+func (r Response) After(latency time.Duration) Response {
+	r.Latency = latency
+	return r
+}
+
+// Provider is a provider replaying scripted responses. Each variable has its
+// own script, consumed one response per lookup; the last response is repeated
+// once the script is exhausted, and unscripted variables are unset.
+// Every lookup is recorded. It is safe for concurrent use.
+type Provider struct {
+	mu      sync.Mutex
+	scripts map[string][]Response
+	calls   []string
+	batches [][]string
+}
+
+// New returns a provider without scripted variables
+func New() *Provider {
+	return &Provider{scripts: make(map[string][]Response)}
+}
+
+// Script appends responses to the script of name
+func (p *Provider) Script(name string, responses ...Response) *Provider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts[name] = append(p.scripts[name], responses...)
+	return p
+}
+
+// Set scripts name to always be set to value, replacing its script
+func (p *Provider) Set(name, value string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripts[name] = []Response{Value(value)}
+	return nil
+}
+
+// Lookup replays the next response of name, reporting errors as the variable
+// being unset
+func (p *Provider) Lookup(name string) (string, bool) {
+	value, found, _ := p.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext replays the next response of name, failing with the error of
+// ctx if it ends before the latency of the response has elapsed
+func (p *Provider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	p.mu.Lock()
+	p.calls = append(p.calls, name)
+	r := p.next(name)
+	p.mu.Unlock()
+
+	if err := wait(ctx, r.Latency); err != nil {
+		return "", false, err
+	}
+	return r.Value, r.Found, r.Err
+}
+
+// next consumes the next response of name
+func (p *Provider) next(name string) Response {
+	script := p.scripts[name]
+	if len(script) == 0 {
+		return Unset()
+	}
+	if len(script) > 1 {
+		p.scripts[name] = script[1:]
+	}
+	return script[0]
+}
+
+// Calls returns the names looked up one by one, in order
+func (p *Provider) Calls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.calls)
+}
+
+// Count returns the number of times name was looked up one by one
+func (p *Provider) Count(name string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, call := range p.calls {
+		if call == name {
+			n++
+		}
+	}
+	return n
+}
+
+// Batches returns the names of every batch lookup, in order
+func (p *Provider) Batches() [][]string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	batches := make([][]string, len(p.batches))
+	for i, batch := range p.batches {
+		batches[i] = slices.Clone(batch)
+	}
+	return batches
+}
+
+// Reset forgets the recorded lookups, keeping the scripts
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls, p.batches = nil, nil
+}
+
+// AssertCalls reports an error on t unless the names looked up one by one
+// are exactly names, in order
+func (p *Provider) AssertCalls(t testing.TB, names ...string) {
+	t.Helper()
+	if calls := p.Calls(); !slices.Equal(calls, names) {
+		t.Errorf("lookups got = %q, want %q", calls, names)
+	}
+}
+
+// AssertCount reports an error on t unless name was looked up n times one by one
+func (p *Provider) AssertCount(t testing.TB, name string, n int) {
+	t.Helper()
+	if count := p.Count(name); count != n {
+		t.Errorf("lookups of '%s' got = %d, want %d", name, count, n)
+	}
+}
+
+// BatchProvider is a Provider also answering batch lookups
+type BatchProvider struct {
+	*Provider
+	// Response scripts the outcome of LookupMany itself; only its Err and
+	// Latency are used
+	Response Response
+}
+
+// Batch returns p answering batch lookups, so expansions fetch the variables
+// they reference in one round trip
+func Batch(p *Provider) *BatchProvider {
+	return &BatchProvider{Provider: p}
+}
+
+// LookupMany replays the next response of every name, failing as scripted
+// by b.Response. Errors of individual responses fail the whole batch.
+func (b *BatchProvider) LookupMany(names []string) (map[string]string, error) {
+	b.mu.Lock()
+	b.batches = append(b.batches, slices.Clone(names))
+	responses := make([]Response, len(names))
+	for i, name := range names {
+		responses[i] = b.next(name)
+	}
+	b.mu.Unlock()
+
+	_ = wait(context.Background(), b.Response.Latency)
+	if b.Response.Err != nil {
+		return nil, b.Response.Err
+	}
+	values := make(map[string]string)
+	for i, r := range responses {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		if r.Found {
+			values[names[i]] = r.Value
+		}
+	}
+	return values, nil
+}
+
+// wait blocks for d or until ctx ends
+func wait(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,86 @@
+package mockenv
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	env "github.com/hadi77ir/go-env"
+)
+
+var (
+	_ env.ContextProvider = (*Provider)(nil)
+	_ env.Setter          = (*Provider)(nil)
+	_ env.BatchProvider   = (*BatchProvider)(nil)
+)
+
+func TestProvider(t *testing.T) {
+	p := New().Script("A", Value("1"), Unset(), Fail(errors.New("boom")))
+	p.Set("B", "2")
+
+	tests := []struct {
+		name      string
+		wantValue string
+		wantFound bool
+		wantErr   bool
+	}{
+		{"A", "1", true, false},
+		{"A", "", false, false},
+		{"A", "", false, true},
+		{"A", "", false, true},
+		{"B", "2", true, false},
+		{"C", "", false, false},
+	}
+	for _, tt := range tests {
+		value, found, err := p.LookupContext(context.Background(), tt.name)
+		if value != tt.wantValue || found != tt.wantFound || (err != nil) != tt.wantErr {
+			t.Errorf("LookupContext(%s) got = %q, %v, %v", tt.name, value, found, err)
+		}
+	}
+	p.AssertCalls(t, "A", "A", "A", "A", "B", "C")
+	p.AssertCount(t, "A", 4)
+
+	p.Reset()
+	p.AssertCalls(t)
+}
+
+func TestProviderLatency(t *testing.T) {
+	p := New().Script("SLOW", Value("x").After(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := p.LookupContext(ctx, "SLOW"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupContext() error = %v, want a deadline error", err)
+	}
+}
+
+func TestProviderRetry(t *testing.T) {
+	unavailable := &env.UnavailableError{Err: errors.New("down")}
+	p := New().Script("TOKEN", Fail(unavailable), Fail(unavailable), Value("t"))
+
+	r := env.Retry(p, env.Backoff{Initial: time.Millisecond})
+	if value, _, err := r.LookupContext(context.Background(), "TOKEN"); err != nil || value != "t" {
+		t.Errorf("LookupContext() got = %q, %v", value, err)
+	}
+	p.AssertCount(t, "TOKEN", 3)
+}
+
+func TestBatchProvider(t *testing.T) {
+	p := New().Script("HOST", Value("db"))
+	b := Batch(p)
+
+	got, err := env.NewExpander(env.WithProvider(b)).Expand("$HOST:${PORT:-5432}")
+	if err != nil || got != "db:5432" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+	if want := [][]string{{"HOST", "PORT"}}; !reflect.DeepEqual(p.Batches(), want) {
+		t.Errorf("Batches() got = %v, want %v", p.Batches(), want)
+	}
+	p.AssertCalls(t)
+
+	b.Response = Fail(errors.New("unreachable"))
+	if _, err := env.NewExpander(env.WithProvider(b)).Expand("$HOST"); err == nil {
+		t.Error("Expand() expected an error")
+	}
+}
@@ -0,0 +1,56 @@
+package env
+
+// Mode is a preset making expansion behave like the tool of an ecosystem
+type Mode int
+
+const (
+	// ModeDefault is the behavior of ExpandEnv: the ${var:-word}, ${var:+word},
+	// ${var:?word} and ${var:=word} operators, the ${var@op} transformations
+	// of bash, no escapes, and malformed references kept as literals
+	ModeDefault Mode = iota
+	// ModePOSIX follows the parameter expansion of POSIX shells: the
+	// operators of ModeDefault and their colon-less forms, which only test
+	// whether the variable is unset, \$ and \\ escapes, and malformed
+	// references rejected
+	ModePOSIX
+	// ModeBash follows bash, which extends ModePOSIX with the ${var@op}
+	// transformations
+	ModeBash
+	// ModeCompose follows the interpolation of Docker Compose files: the
+	// :-, -, :?, ?, :+ and + operators, $$ escapes, and malformed references
+	// rejected
+	ModeCompose
+	// ModeEnvsubst follows GNU gettext's envsubst: only $var and ${var} are
+	// expanded, anything else is kept as a literal
+	ModeEnvsubst
+)
+
+// modeSettings holds the syntax and strictness of a Mode
+type modeSettings struct {
+	colonOps   string
+	bareOps    string
+	transforms bool
+	escape     byte
+	strict     bool
+}
+
+var modes = map[Mode]modeSettings{
+	ModeDefault:  {colonOps: "-+?=", transforms: true},
+	ModePOSIX:    {colonOps: "-+?=", bareOps: "-+?=", escape: '\\', strict: true},
+	ModeBash:     {colonOps: "-+?=", bareOps: "-+?=", transforms: true, escape: '\\', strict: true},
+	ModeCompose:  {colonOps: "-?+", bareOps: "-?+", escape: '$', strict: true},
+	ModeEnvsubst: {},
+}
+
+// WithMode sets the operators, escapes and handling of malformed references
+// to the ones of mode, see the Mode constants. Modes rejecting malformed
+// references enable WithStrictSyntax. Unset variables expand to an empty
+// string in every mode.
+func WithMode(mode Mode) Option {
+	return func(c *config) {
+		c.mode = mode
+		if modes[mode].strict {
+			c.strictSyntax = true
+		}
+	}
+}
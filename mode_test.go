@@ -0,0 +1,45 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestModeWriteChunks(t *testing.T) {
+	vars := Map{"HOST": "db"}
+	tests := []struct {
+		mode  Mode
+		input string
+	}{
+		{ModePOSIX, `\$HOST $HOST \\${HOST-x} \x \`},
+		{ModeCompose, `$$HOST $HOST $${HOST} ${PORT-80} $`},
+	}
+	for _, tt := range tests {
+		want, err := NewExpander(WithProvider(vars), WithMode(tt.mode)).Expand(tt.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for size := 1; size <= len(tt.input); size++ {
+			var out strings.Builder
+			x := NewExpander(WithProvider(vars), WithMode(tt.mode), WithOutput(&out))
+			for i := 0; i < len(tt.input); i += size {
+				x.Write([]byte(tt.input[i:min(i+size, len(tt.input))]))
+			}
+			if err := x.Flush(); err != nil {
+				t.Fatalf("mode %d, chunk size %d: Flush() error = %v", tt.mode, size, err)
+			}
+			if out.String() != want {
+				t.Errorf("mode %d, chunk size %d: got = %q, want %q", tt.mode, size, out.String(), want)
+			}
+		}
+	}
+}
+
+func TestWithModeStrictness(t *testing.T) {
+	if _, err := Expand("${1BAD}", Map{}, WithMode(ModeEnvsubst), WithStrictSyntax()); err == nil {
+		t.Error("Expand() expected WithStrictSyntax to apply to ModeEnvsubst")
+	}
+	if got, err := Expand("${A=x}$A", Map{}, WithStrictSyntax(), WithMode(ModeBash)); err != nil || got != "xx" {
+		t.Errorf("Expand() got = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,46 @@
+package env
+
+import (
+	"strings"
+	"unicode"
+)
+
+// ToEnvName converts a configuration key into the conventional name of the
+// variable holding it: words are upper-cased and joined with '_', where words
+// are separated by '.', '-', '_', '/' or spaces and by camelCase boundaries.
+// For example "db.maxConnections" becomes DB_MAX_CONNECTIONS and
+// "HTTPServer" becomes HTTP_SERVER.
+func ToEnvName(key string) string {
+	runes := []rune(key)
+	var b strings.Builder
+	b.Grow(len(key) + 4)
+	pending := false // a separator is due before the next word
+	for i, r := range runes {
+		switch {
+		case r == '.' || r == '-' || r == '_' || r == '/' || unicode.IsSpace(r):
+			pending = b.Len() > 0
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) ||
+				unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+				pending = b.Len() > 0
+			}
+		}
+		if pending {
+			b.WriteByte('_')
+			pending = false
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// FromEnvName converts a variable name into a configuration key by
+// lower-casing it and replacing '_' with sep, so DB_MAX_CONNECTIONS becomes
+// "db.max.connections" with sep ".". It reverses ToEnvName except for the
+// camelCase boundaries, which cannot be recovered.
+func FromEnvName(name, sep string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool { return r == '_' })
+	return strings.Join(words, sep)
+}
@@ -0,0 +1,45 @@
+package env
+
+import "testing"
+
+func TestToEnvName(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"db.maxConnections", "DB_MAX_CONNECTIONS"},
+		{"HTTPServer", "HTTP_SERVER"},
+		{"server.http.port", "SERVER_HTTP_PORT"},
+		{"log-level", "LOG_LEVEL"},
+		{"apiV2Url", "API_V2_URL"},
+		{"s3Bucket", "S3_BUCKET"},
+		{"already_SNAKE", "ALREADY_SNAKE"},
+		{"..leading and trailing..", "LEADING_AND_TRAILING"},
+		{"ID", "ID"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := ToEnvName(tt.key); got != tt.want {
+				t.Errorf("ToEnvName(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromEnvName(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  string
+		want string
+	}{
+		{"DB_MAX_CONNECTIONS", ".", "db.max.connections"},
+		{"LOG__LEVEL_", "-", "log-level"},
+		{"PORT", ".", "port"},
+	}
+	for _, tt := range tests {
+		if got := FromEnvName(tt.name, tt.sep); got != tt.want {
+			t.Errorf("FromEnvName(%q, %q) = %q, want %q", tt.name, tt.sep, got, tt.want)
+		}
+	}
+}
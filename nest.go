@@ -0,0 +1,75 @@
+package env
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Nest turns variables into a tree of maps by splitting their names on sep and
+// lower-casing them, so that APP_DB_HOST=x becomes {"app": {"db": {"host": "x"}}}
+// with sep "_", ready to be encoded as JSON or YAML. Empty parts of names are
+// dropped. When a name is both a value and a parent of other names, such as
+// APP_DB and APP_DB_HOST, the value is dropped in favor of the nested names.
+func Nest(vars map[string]string, sep string) map[string]any {
+	flat := make(map[string]string, len(vars))
+	for name, value := range vars {
+		parts := strings.Split(strings.ToLower(name), sep)
+		keys := parts[:0]
+		for _, part := range parts {
+			if part != "" {
+				keys = append(keys, part)
+			}
+		}
+		if len(keys) > 0 {
+			flat[strings.Join(keys, sep)] = value
+		}
+	}
+	return unflatten(flat, sep)
+}
+
+// Flatten reverses Nest: the keys leading to each value are upper-cased and
+// joined with sep, so {"app": {"db": {"host": "x"}}} becomes APP_DB_HOST=x with
+// sep "_". Nested map[string]any and map[string]string are descended into,
+// slices are written as comma-separated lists like Decode reads them, nil is
+// written as an empty value and other values are formatted with fmt.Sprint.
+func Flatten(nested map[string]any, sep string) map[string]string {
+	vars := make(map[string]string)
+	flattenInto(vars, nested, "", sep)
+	return vars
+}
+
+// flattenInto adds the values of node to vars, prefixing their names with prefix
+func flattenInto(vars map[string]string, node map[string]any, prefix, sep string) {
+	for key, value := range node {
+		name := prefix + strings.ToUpper(key)
+		switch v := value.(type) {
+		case map[string]any:
+			flattenInto(vars, v, name+sep, sep)
+		case map[string]string:
+			for k, s := range v {
+				vars[name+sep+strings.ToUpper(k)] = s
+			}
+		default:
+			vars[name] = flatValue(v)
+		}
+	}
+}
+
+// flatValue formats a leaf of a tree given to Flatten
+func flatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ",")
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = flatValue(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNest(t *testing.T) {
+	tests := []struct {
+		name string
+		vars map[string]string
+		sep  string
+		want string
+	}{
+		{"nested", map[string]string{"APP_DB_HOST": "x", "APP_DB_PORT": "5432", "APP_NAME": "svc"}, "_", `{"app":{"db":{"host":"x","port":"5432"},"name":"svc"}}`},
+		{"parent dropped", map[string]string{"APP_DB": "x", "APP_DB_HOST": "y"}, "_", `{"app":{"db":{"host":"y"}}}`},
+		{"empty parts", map[string]string{"_APP__NAME": "svc", "_": "ignored"}, "_", `{"app":{"name":"svc"}}`},
+		{"double separator", map[string]string{"APP__DB_HOST": "x"}, "__", `{"app":{"db_host":"x"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(Nest(tt.vars, tt.sep))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Nest() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	var nested map[string]any
+	input := `{"app": {"db": {"host": "x", "port": 5432}, "debug": true, "hosts": ["a", "b"], "none": null}}`
+	if err := json.Unmarshal([]byte(input), &nested); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"APP_DB_HOST": "x",
+		"APP_DB_PORT": "5432",
+		"APP_DEBUG":   "true",
+		"APP_HOSTS":   "a,b",
+		"APP_NONE":    "",
+	}
+	if got := Flatten(nested, "_"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Flatten() got = %v, want %v", got, want)
+	}
+
+	vars := map[string]string{"APP_DB_HOST": "x", "APP_NAME": "svc"}
+	if got := Flatten(Nest(vars, "_"), "_"); !reflect.DeepEqual(got, vars) {
+		t.Errorf("Flatten(Nest()) got = %v, want %v", got, vars)
+	}
+}
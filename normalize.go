@@ -0,0 +1,50 @@
+package env
+
+import (
+	"context"
+	"strings"
+)
+
+// Normalizer is implemented by providers that translate the names written in
+// templates into conventional variable names. The expander validates the
+// normalized name, so templates may use names like ${db.host} in braced form.
+type Normalizer interface {
+	NormalizeName(name string) string
+}
+
+// normalized is a Provider resolving normalized names from the wrapped provider
+type normalized struct {
+	base Provider
+}
+
+// Normalized wraps p so that names are upper-cased and have '-' and '.'
+// converted to '_' before being looked up, letting ${db.host} and ${db-host}
+// resolve DB_HOST
+func Normalized(p Provider) Provider {
+	return &normalized{base: p}
+}
+
+var nameReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// NormalizeName returns the conventional form of name
+func (n *normalized) NormalizeName(name string) string {
+	return strings.ToUpper(nameReplacer.Replace(name))
+}
+
+// Lookup resolves the normalized name from the wrapped provider
+func (n *normalized) Lookup(name string) (string, bool) {
+	return n.base.Lookup(n.NormalizeName(name))
+}
+
+// Set assigns the normalized name in the wrapped provider, if it is a Setter
+func (n *normalized) Set(name, value string) error {
+	if setter, ok := n.base.(Setter); ok {
+		return setter.Set(n.NormalizeName(name), value)
+	}
+	return nil
+}
+
+// Ping checks the wrapped provider, see HealthChecker
+func (n *normalized) Ping(ctx context.Context) error {
+	return ping(ctx, n.base)
+}
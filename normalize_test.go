@@ -0,0 +1,39 @@
+package env
+
+import "testing"
+
+func TestNormalized(t *testing.T) {
+	base := Map{"DB_HOST": "db.internal", "DB_PORT": "5432"}
+	p := Normalized(base)
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "dotted name", input: "${db.host}", want: "db.internal"},
+		{name: "hyphenated name", input: "${db-port}", want: "5432"},
+		{name: "lower case simple form", input: "$db_host", want: "db.internal"},
+		{name: "operator", input: "${db.user:-admin}", want: "admin"},
+		{name: "still invalid", input: "${1db.host}", want: "${1db.host}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input, p)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Expand("${log.level:=info}", p); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got := base["LOG_LEVEL"]; got != "info" {
+		t.Errorf("assignment got = %v, want info under LOG_LEVEL", got)
+	}
+}
@@ -0,0 +1,255 @@
+package env
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// OnePasswordProvider resolves secrets stored in 1Password through secret
+// references of the form op://vault/item/field or op://vault/item/section/field,
+// using either the op CLI or a 1Password Connect server.
+//
+// A variable is resolved from the reference mapped to its name with
+// OnePasswordRefs or, failing that, from the base provider: values of the base
+// provider holding a reference are replaced with the secret, other values are
+// returned as they are. This lets .env files hold references instead of secrets.
+//
+// Every lookup reads the secret again; wrap the provider with Cached to avoid
+// repeated round trips.
+type OnePasswordProvider struct {
+	base Provider
+	refs map[string]string
+	read func(ctx context.Context, ref string) (string, error)
+	ping func(ctx context.Context) error
+}
+
+// OnePasswordOption configures a OnePasswordProvider
+type OnePasswordOption func(*OnePasswordProvider)
+
+// OnePasswordBase sets the provider whose values may hold secret references
+func OnePasswordBase(p Provider) OnePasswordOption {
+	return func(o *OnePasswordProvider) {
+		o.base = p
+	}
+}
+
+// OnePasswordRefs maps variable names to secret references
+func OnePasswordRefs(refs map[string]string) OnePasswordOption {
+	return func(o *OnePasswordProvider) {
+		for name, ref := range refs {
+			o.refs[name] = ref
+		}
+	}
+}
+
+// OnePasswordCLI reads secrets with the op CLI at path, which must be signed
+// in. This is the default, with the op found in PATH.
+func OnePasswordCLI(path string) OnePasswordOption {
+	return func(o *OnePasswordProvider) {
+		o.read = func(ctx context.Context, ref string) (string, error) {
+			var stdout, stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, path, "read", "--no-newline", ref)
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+			if err := cmd.Run(); err != nil {
+				if msg := strings.TrimSpace(stderr.String()); msg != "" {
+					return "", fmt.Errorf("%w: %s", err, msg)
+				}
+				return "", err
+			}
+			return stdout.String(), nil
+		}
+		o.ping = func(ctx context.Context) error {
+			var stderr bytes.Buffer
+			cmd := exec.CommandContext(ctx, path, "whoami")
+			cmd.Stderr = &stderr
+			if err := cmd.Run(); err != nil {
+				if msg := strings.TrimSpace(stderr.String()); msg != "" {
+					return fmt.Errorf("%w: %s", err, msg)
+				}
+				return err
+			}
+			return nil
+		}
+	}
+}
+
+// OnePasswordConnect reads secrets from the 1Password Connect server at
+// serverURL, authenticating with token. A nil client uses http.DefaultClient.
+func OnePasswordConnect(serverURL, token string, client *http.Client) OnePasswordOption {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	c := &onePasswordConnect{url: strings.TrimSuffix(serverURL, "/"), token: token, client: client}
+	return func(o *OnePasswordProvider) {
+		o.read = c.read
+		o.ping = c.ping
+	}
+}
+
+// NewOnePasswordProvider returns a provider configured by opts
+func NewOnePasswordProvider(opts ...OnePasswordOption) *OnePasswordProvider {
+	o := &OnePasswordProvider{refs: make(map[string]string)}
+	OnePasswordCLI("op")(o)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Lookup resolves name, reporting read errors as the variable being unset;
+// use LookupContext to observe them
+func (o *OnePasswordProvider) Lookup(name string) (string, bool) {
+	value, found, _ := o.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext resolves name, reading the secret it refers to
+func (o *OnePasswordProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	ref, ok := o.refs[name]
+	if !ok {
+		if o.base == nil {
+			return "", false, nil
+		}
+		value, found := o.base.Lookup(name)
+		if !found || !IsOnePasswordRef(value) {
+			return value, found, nil
+		}
+		ref = value
+	}
+
+	secret, err := o.read(ctx, ref)
+	if err != nil {
+		return "", false, fmt.Errorf("reading %s: %w", ref, err)
+	}
+	return secret, true, nil
+}
+
+// Ping checks that secrets can be read: that the op CLI is signed in or that
+// the Connect server accepts the token, see HealthChecker
+func (o *OnePasswordProvider) Ping(ctx context.Context) error {
+	return o.ping(ctx)
+}
+
+// IsOnePasswordRef reports whether s is a 1Password secret reference
+func IsOnePasswordRef(s string) bool {
+	return strings.HasPrefix(s, "op://")
+}
+
+// parseOnePasswordRef splits a secret reference into its vault, item,
+// section and field
+func parseOnePasswordRef(ref string) (vault, item, section, field string, err error) {
+	path, _, _ := strings.Cut(strings.TrimPrefix(ref, "op://"), "?")
+	parts := strings.Split(path, "/")
+	switch len(parts) {
+	case 3:
+		vault, item, field = parts[0], parts[1], parts[2]
+	case 4:
+		vault, item, section, field = parts[0], parts[1], parts[2], parts[3]
+	default:
+		return "", "", "", "", errors.New("malformed secret reference")
+	}
+	return vault, item, section, field, nil
+}
+
+// onePasswordConnect reads secrets through the Connect server API
+type onePasswordConnect struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+type onePasswordItem struct {
+	Sections []struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+	} `json:"sections"`
+	Fields []struct {
+		ID      string `json:"id"`
+		Label   string `json:"label"`
+		Value   string `json:"value"`
+		Section *struct {
+			ID string `json:"id"`
+		} `json:"section"`
+	} `json:"fields"`
+}
+
+func (c *onePasswordConnect) read(ctx context.Context, ref string) (string, error) {
+	vault, item, section, field, err := parseOnePasswordRef(ref)
+	if err != nil {
+		return "", err
+	}
+	vaultID, err := c.findID(ctx, "/v1/vaults", vault)
+	if err != nil {
+		return "", err
+	}
+	itemID, err := c.findID(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items", item)
+	if err != nil {
+		return "", err
+	}
+
+	var it onePasswordItem
+	if err := c.get(ctx, "/v1/vaults/"+url.PathEscape(vaultID)+"/items/"+url.PathEscape(itemID), &it); err != nil {
+		return "", err
+	}
+	sectionID := ""
+	for _, s := range it.Sections {
+		if section != "" && (s.Label == section || s.ID == section) {
+			sectionID = s.ID
+		}
+	}
+	for _, f := range it.Fields {
+		if f.Label != field && f.ID != field {
+			continue
+		}
+		if section == "" || f.Section != nil && f.Section.ID == sectionID {
+			return f.Value, nil
+		}
+	}
+	return "", fmt.Errorf("field %q not found", field)
+}
+
+// ping lists the vaults, which requires a valid token
+func (c *onePasswordConnect) ping(ctx context.Context) error {
+	var vaults []struct{}
+	return c.get(ctx, "/v1/vaults", &vaults)
+}
+
+// findID returns the ID of the vault or item titled title in the collection
+// at path. Titles matching nothing are taken to be IDs.
+func (c *onePasswordConnect) findID(ctx context.Context, path, title string) (string, error) {
+	var found []struct {
+		ID string `json:"id"`
+	}
+	query := "?filter=" + url.QueryEscape(fmt.Sprintf("title eq %q", title))
+	if err := c.get(ctx, path+query, &found); err != nil {
+		return "", err
+	}
+	if len(found) == 0 {
+		return title, nil
+	}
+	return found[0].ID, nil
+}
+
+func (c *onePasswordConnect) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return requestError(ctx, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return statusError(fmt.Errorf("connect server: unexpected status %s", resp.Status), resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,119 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// TestOnePasswordHelperProcess impersonates the op CLI
+func TestOnePasswordHelperProcess(t *testing.T) {
+	if os.Getenv("GO_ENV_OP_HELPER") != "1" {
+		return
+	}
+	ref := os.Args[len(os.Args)-1]
+	if ref != "op://dev/db/password" {
+		fmt.Fprintf(os.Stderr, "[ERROR] could not read secret %s", ref)
+		os.Exit(1)
+	}
+	fmt.Print("hunter2")
+	os.Exit(0)
+}
+
+func TestOnePasswordCLI(t *testing.T) {
+	t.Setenv("GO_ENV_OP_HELPER", "1")
+	script := writeOpScript(t)
+
+	o := NewOnePasswordProvider(
+		OnePasswordCLI(script),
+		OnePasswordRefs(map[string]string{"DB_PASSWORD": "op://dev/db/password"}),
+		OnePasswordBase(Map{"DB_USER": "app", "API_KEY": "op://dev/api/key"}),
+	)
+
+	if value, found := o.Lookup("DB_PASSWORD"); !found || value != "hunter2" {
+		t.Errorf("Lookup(DB_PASSWORD) got = %q, %v", value, found)
+	}
+	if value, found := o.Lookup("DB_USER"); !found || value != "app" {
+		t.Errorf("Lookup(DB_USER) got = %q, %v", value, found)
+	}
+	if _, found := o.Lookup("MISSING"); found {
+		t.Error("Lookup(MISSING) found a value")
+	}
+	if _, _, err := o.LookupContext(context.Background(), "API_KEY"); err == nil {
+		t.Error("LookupContext(API_KEY) expected an error")
+	}
+}
+
+// writeOpScript writes a script running the test binary as the op CLI
+func writeOpScript(t *testing.T) string {
+	t.Helper()
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("no /bin/sh")
+	}
+	script := t.TempDir() + "/op"
+	content := fmt.Sprintf("#!/bin/sh\nexec %q -test.run=TestOnePasswordHelperProcess -- \"$@\"\n", os.Args[0])
+	if err := os.WriteFile(script, []byte(content), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return script
+}
+
+func TestOnePasswordConnect(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/vaults", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("filter") == `title eq "dev"` {
+			fmt.Fprint(w, `[{"id": "v1"}]`)
+			return
+		}
+		fmt.Fprint(w, `[]`)
+	})
+	mux.HandleFunc("GET /v1/vaults/v1/items", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": "i1"}]`)
+	})
+	mux.HandleFunc("GET /v1/vaults/v1/items/i1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{
+			"sections": [{"id": "s1", "label": "replica"}],
+			"fields": [
+				{"id": "password", "label": "password", "value": "primary-pass"},
+				{"id": "f2", "label": "password", "value": "replica-pass", "section": {"id": "s1"}}
+			]
+		}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	o := NewOnePasswordProvider(
+		OnePasswordConnect(server.URL, "token", server.Client()),
+		OnePasswordRefs(map[string]string{
+			"PRIMARY": "op://dev/db/password",
+			"REPLICA": "op://dev/db/replica/password",
+			"MISSING": "op://dev/db/username",
+			"BAD":     "op://dev",
+		}),
+	)
+
+	for name, want := range map[string]string{"PRIMARY": "primary-pass", "REPLICA": "replica-pass"} {
+		value, found, err := o.LookupContext(context.Background(), name)
+		if err != nil || !found || value != want {
+			t.Errorf("LookupContext(%s) got = %q, %v, %v, want %q", name, value, found, err, want)
+		}
+	}
+	for _, name := range []string{"MISSING", "BAD"} {
+		if _, _, err := o.LookupContext(context.Background(), name); err == nil {
+			t.Errorf("LookupContext(%s) expected an error", name)
+		}
+	}
+	if err := o.Ping(context.Background()); err != nil {
+		t.Errorf("Ping() error = %v", err)
+	}
+	if err := NewOnePasswordProvider(OnePasswordConnect(server.URL, "wrong", server.Client())).Ping(context.Background()); err == nil {
+		t.Error("Ping() expected an error for a wrong token")
+	}
+}
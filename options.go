@@ -0,0 +1,125 @@
+package env
+
+import (
+	"io"
+	"log/slog"
+	"time"
+)
+
+// Option configures an Expander
+type Option func(*config)
+
+// config holds the settings of an Expander
+type config struct {
+	provider      Provider
+	lookupHooks   []func(name string)
+	lookupFilter  []func(name string) bool
+	resolveHooks  []func(name, value string, found bool)
+	metrics       Metrics
+	logger        *slog.Logger
+	isSecret      func(name string) bool
+	pure          bool
+	include       []string
+	exclude       []string
+	trimSuffix    string
+	output        io.Writer
+	syntax        syntax
+	bracedOnly    bool
+	dual          bool
+	strictSyntax  bool
+	trace         []func(TraceStep)
+	unicodeNames  bool
+	caseFallback  bool
+	knownFolders  bool
+	xdg           bool
+	absPaths      bool
+	detectSecrets bool
+	secretHook    func(name, reason string)
+	timeout       time.Duration
+	mode          Mode
+	shellQuotes   bool
+	stats         *Stats
+	transactional bool
+	setter        func(name, value string) error
+}
+
+// WithProvider resolves variables from p instead of the process environment
+func WithProvider(p Provider) Option {
+	return func(c *config) {
+		c.provider = p
+	}
+}
+
+// WithPure keeps ${var:=default} from writing to the provider. The assigned
+// value is still used for the rest of the expansion; use ExpandPure to
+// retrieve the assignments.
+func WithPure() Option {
+	return func(c *config) {
+		c.pure = true
+	}
+}
+
+// WithSetter writes the assignments performed by ${var:=default} with set
+// instead of the provider's Set method, so they can go to a destination such
+// as an overlay map or a dotenv document while variables are still resolved
+// from the provider. Assignments are still skipped by WithPure.
+func WithSetter(set func(name, value string) error) Option {
+	return func(c *config) {
+		c.setter = set
+	}
+}
+
+// WithTransactionalAssignments holds the assignments performed by
+// ${var:=default} until the whole expansion succeeds, then writes them to the
+// provider, so that a later failure such as ${other:?} leaves the provider
+// untouched. The assigned values are used for the rest of the expansion in the
+// meantime. Use ExpandDetailed to also report them.
+func WithTransactionalAssignments() Option {
+	return func(c *config) {
+		c.transactional = true
+	}
+}
+
+// WithLookupHook calls hook with the name of every variable about to be looked
+// up. Multiple hooks are called in the order they were given.
+func WithLookupHook(hook func(name string)) Option {
+	return func(c *config) {
+		c.lookupHooks = append(c.lookupHooks, hook)
+	}
+}
+
+// WithLookupFilter calls allow before every lookup. If it returns false the
+// variable is treated as unset without consulting the provider.
+func WithLookupFilter(allow func(name string) bool) Option {
+	return func(c *config) {
+		c.lookupFilter = append(c.lookupFilter, allow)
+	}
+}
+
+// WithResolveHook calls hook with the outcome of every lookup: the name of
+// the variable, its value and whether it was found
+func WithResolveHook(hook func(name, value string, found bool)) Option {
+	return func(c *config) {
+		c.resolveHooks = append(c.resolveHooks, hook)
+	}
+}
+
+// WithCaseFallback retries lookups that miss with the name in upper case, then
+// in lower case, so that ${http_proxy} also finds HTTP_PROXY and
+// ${HTTP_PROXY} finds http_proxy, as proxy variables come in both forms
+func WithCaseFallback() Option {
+	return func(c *config) {
+		c.caseFallback = true
+	}
+}
+
+// WithKnownFolders resolves the variables naming well-known folders on
+// Windows, such as APPDATA, LOCALAPPDATA and ProgramFiles, with the
+// known-folder API when the provider does not set them, as happens in the
+// environment of services. Names are matched case-insensitively. It has no
+// effect on other platforms.
+func WithKnownFolders() Option {
+	return func(c *config) {
+		c.knownFolders = true
+	}
+}
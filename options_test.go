@@ -0,0 +1,107 @@
+package env
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewExpanderDefaultsToProcessEnv(t *testing.T) {
+	os.Setenv("EXPANDER_VAR", "from_os")
+	defer os.Unsetenv("EXPANDER_VAR")
+
+	got, err := NewExpander().Expand("${EXPANDER_VAR}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got != "from_os" {
+		t.Errorf("Expand() got = %v, want from_os", got)
+	}
+}
+
+func TestLookupHooks(t *testing.T) {
+	var looked []string
+	var resolved []string
+
+	x := NewExpander(
+		WithProvider(Map{"SET": "value", "SECRET": "hunter2"}),
+		WithLookupHook(func(name string) {
+			looked = append(looked, name)
+		}),
+		WithLookupFilter(func(name string) bool {
+			return !strings.HasPrefix(name, "SECRET")
+		}),
+		WithResolveHook(func(name, value string, found bool) {
+			if found {
+				resolved = append(resolved, name+"="+value)
+			} else {
+				resolved = append(resolved, name+" missing")
+			}
+		}),
+	)
+
+	got, err := x.Expand("$SET ${UNSET:-default} [$SECRET]")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "value default []"; got != want {
+		t.Errorf("Expand() got = %v, want %v", got, want)
+	}
+	if want := []string{"SET", "UNSET", "SECRET"}; !reflect.DeepEqual(looked, want) {
+		t.Errorf("lookup hook got = %v, want %v", looked, want)
+	}
+	if want := []string{"SET=value", "UNSET missing", "SECRET missing"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("resolve hook got = %v, want %v", resolved, want)
+	}
+}
+
+func TestWithCaseFallback(t *testing.T) {
+	vars := Map{"HTTP_PROXY": "http://proxy:3128", "no_proxy": "localhost", "Mixed": "m"}
+
+	got, err := NewExpander(WithProvider(vars), WithCaseFallback()).Expand("${http_proxy} $NO_PROXY ${mixed:-none}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://proxy:3128 localhost none"; got != want {
+		t.Errorf("Expand() got = %q, want %q", got, want)
+	}
+
+	got, err = NewExpander(WithProvider(vars)).Expand("${http_proxy:-none}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "none" {
+		t.Errorf("Expand() without fallback got = %q, want %q", got, "none")
+	}
+}
+
+func TestWithSetter(t *testing.T) {
+	vars := Map{"SET": "value"}
+	target := Map{}
+	x := NewExpander(WithProvider(vars), WithSetter(func(name, value string) error {
+		return target.Set(name, value)
+	}))
+
+	got, err := x.Expand("${A:=1}$A ${SET:=ignored}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "11 value"; got != want {
+		t.Errorf("Expand() got = %v, want %v", got, want)
+	}
+	if want := (Map{"A": "1"}); !reflect.DeepEqual(target, want) {
+		t.Errorf("setter got = %v, want %v", target, want)
+	}
+	if want := (Map{"SET": "value"}); !reflect.DeepEqual(vars, want) {
+		t.Errorf("Expand() wrote to the provider: %v", vars)
+	}
+
+	failing := NewExpander(WithProvider(vars), WithSetter(func(name, value string) error {
+		return errors.New("read-only")
+	}))
+	if _, err := failing.Expand("${B:=2}"); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Errorf("Expand() error = %v, want the setter error", err)
+	}
+}
@@ -0,0 +1,111 @@
+package env
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// OverlayEnv is a copy-on-write layer on top of a base Provider.
+// Lookups consult the overlay first and fall back to the base, while writes,
+// including the assignments performed by ${var:=default}, only ever land in the
+// overlay and leave the base untouched.
+// It is safe for concurrent use if the base is.
+type OverlayEnv struct {
+	base Provider
+
+	mu      sync.RWMutex
+	changes map[string]*string // nil marks a variable unset in the overlay
+}
+
+// Overlay returns an empty copy-on-write layer on top of base
+func Overlay(base Provider) *OverlayEnv {
+	return &OverlayEnv{
+		base:    base,
+		changes: make(map[string]*string),
+	}
+}
+
+// Lookup returns the value of name from the overlay, or from the base if the
+// overlay does not hold it
+func (o *OverlayEnv) Lookup(name string) (string, bool) {
+	o.mu.RLock()
+	value, changed := o.changes[name]
+	o.mu.RUnlock()
+	if changed {
+		if value == nil {
+			return "", false
+		}
+		return *value, true
+	}
+	return o.base.Lookup(name)
+}
+
+// Set sets name to value in the overlay
+func (o *OverlayEnv) Set(name, value string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.changes[name] = &value
+	return nil
+}
+
+// Unset hides name in the overlay, regardless of it being present in the base
+func (o *OverlayEnv) Unset(name string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.changes[name] = nil
+}
+
+// Changes returns a copy of the writes made to the overlay. Unset variables
+// map to nil, so the result can be passed to Apply to commit the changes to
+// the process environment.
+func (o *OverlayEnv) Changes() map[string]*string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	changes := make(map[string]*string, len(o.changes))
+	for name, value := range o.changes {
+		if value != nil {
+			v := *value
+			value = &v
+		}
+		changes[name] = value
+	}
+	return changes
+}
+
+// Environ returns the merged variables of the overlay and the base in
+// "key=value" form, sorted by key. Variables of the base are only included if
+// it implements Lister.
+func (o *OverlayEnv) Environ() []string {
+	vars := make(Map)
+	if lister, ok := o.base.(Lister); ok {
+		for _, kv := range lister.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			if name != "" {
+				vars[name] = value
+			}
+		}
+	}
+
+	o.mu.RLock()
+	for name, value := range o.changes {
+		if value == nil {
+			delete(vars, name)
+		} else {
+			vars[name] = *value
+		}
+	}
+	o.mu.RUnlock()
+
+	return vars.Environ()
+}
+
+// Expand expands variables in input against the overlay
+func (o *OverlayEnv) Expand(input string) (string, error) {
+	return Expand(input, o)
+}
+
+// Ping checks the base provider, see HealthChecker
+func (o *OverlayEnv) Ping(ctx context.Context) error {
+	return ping(ctx, o.base)
+}
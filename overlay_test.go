@@ -0,0 +1,60 @@
+package env
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestOverlay(t *testing.T) {
+	base := Map{"KEEP": "base", "HIDE": "base", "CHANGE": "base"}
+	o := Overlay(base)
+
+	o.Set("CHANGE", "overlay")
+	o.Unset("HIDE")
+
+	got, err := o.Expand("$KEEP $CHANGE [$HIDE] ${NEW:=assigned}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "base overlay [] assigned"; got != want {
+		t.Errorf("Expand() got = %v, want %v", got, want)
+	}
+
+	if want := (Map{"KEEP": "base", "HIDE": "base", "CHANGE": "base"}); !reflect.DeepEqual(base, want) {
+		t.Errorf("base was modified: %v", base)
+	}
+
+	changes := o.Changes()
+	if len(changes) != 3 || changes["HIDE"] != nil || *changes["CHANGE"] != "overlay" || *changes["NEW"] != "assigned" {
+		t.Errorf("Changes() got unexpected result: %v", changes)
+	}
+
+	if got, want := o.Environ(), []string{"CHANGE=overlay", "KEEP=base", "NEW=assigned"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() got = %v, want %v", got, want)
+	}
+}
+
+func TestOverlayProcessEnv(t *testing.T) {
+	os.Unsetenv("OVERLAY_ASSIGNED")
+	defer os.Unsetenv("OVERLAY_ASSIGNED")
+
+	o := Overlay(OS)
+	if _, err := o.Expand("${OVERLAY_ASSIGNED:=value}"); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if _, ok := os.LookupEnv("OVERLAY_ASSIGNED"); ok {
+		t.Errorf("assignment leaked into the process environment")
+	}
+
+	rollback, err := Apply(o.Changes())
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	if got := os.Getenv("OVERLAY_ASSIGNED"); got != "value" {
+		t.Errorf("Apply(Changes()) got = %v, want value", got)
+	}
+	if err := rollback(); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+}
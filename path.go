@@ -0,0 +1,78 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// WithAbsolutePaths makes ExpandPath turn relative paths into absolute ones,
+// relative to the current working directory
+func WithAbsolutePaths() Option {
+	return func(c *config) {
+		c.absPaths = true
+	}
+}
+
+// ExpandPath expands a file path in one call: a leading ~ or ~user is
+// replaced by the home directory, variables are expanded like ExpandEnv and
+// the result is cleaned with filepath.Clean, e.g. "~/${APP:-app}/../data"
+// becomes "/home/alice/data". With WithAbsolutePaths, relative paths are also
+// made absolute. An empty result is returned as is.
+func ExpandPath(p string, opts ...Option) (string, error) {
+	return NewExpander(opts...).ExpandPath(p)
+}
+
+// ExpandPath is like the package-level ExpandPath, using the configuration of x.
+// The home directory of ~ is the HOME variable of the provider if set.
+func (x *Expander) ExpandPath(p string) (string, error) {
+	home, p, err := x.splitTilde(p)
+	if err != nil {
+		return "", err
+	}
+	if p, err = x.Expand(p); err != nil {
+		return "", err
+	}
+	if p = home + p; p == "" {
+		return "", nil
+	}
+	p = filepath.Clean(p)
+	if x.cfg.absPaths {
+		return filepath.Abs(p)
+	}
+	return p, nil
+}
+
+// splitTilde returns the home directory the ~ or ~user prefix of p stands
+// for, which is not subject to expansion, and the rest of p
+func (x *Expander) splitTilde(p string) (string, string, error) {
+	if !strings.HasPrefix(p, "~") {
+		return "", p, nil
+	}
+	name, rest := p[1:], ""
+	if i := strings.IndexAny(name, `/`+string(filepath.Separator)); i != -1 {
+		name, rest = name[:i], name[i:]
+	}
+
+	var home string
+	if name == "" {
+		value, found, err := x.Lookup("HOME")
+		if err != nil {
+			return "", "", err
+		}
+		if home = value; !found || home == "" {
+			if home, err = os.UserHomeDir(); err != nil {
+				return "", "", fmt.Errorf("failed to expand ~: %w", err)
+			}
+		}
+	} else {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to expand ~%s: %w", name, err)
+		}
+		home = u.HomeDir
+	}
+	return home, rest, nil
+}
@@ -0,0 +1,45 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandPath(t *testing.T) {
+	vars := Map{"HOME": "/home/alice", "APP": "api", "DIR": "data/../logs"}
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"~", "/home/alice", false},
+		{"~/.config/$APP", "/home/alice/.config/api", false},
+		{"~/${NAME:-app}/../data", "/home/alice/data", false},
+		{"/var/$DIR/", "/var/logs", false},
+		{"./$APP//x", "api/x", false},
+		{"a~/b", "a~/b", false},
+		{"", "", false},
+		{"${MISSING}", "", false},
+		{"~/${MISSING:?required}", "", true},
+		{"~go_env_missing_user/x", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ExpandPath(tt.input, WithProvider(vars))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ExpandPath(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if want := filepath.FromSlash(tt.want); got != want {
+			t.Errorf("ExpandPath(%q) got = %q, want %q", tt.input, got, want)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ExpandPath("$APP/./config", WithProvider(vars), WithAbsolutePaths()); err != nil || got != filepath.Join(wd, "api", "config") {
+		t.Errorf("ExpandPath() got = %q, %v, want an absolute path", got, err)
+	}
+}
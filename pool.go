@@ -0,0 +1,51 @@
+package env
+
+import "sync"
+
+// maxPooledBuffer is the capacity above which buffers are left to the
+// garbage collector rather than kept in the pool, so that expanding one large
+// file does not pin its memory
+const maxPooledBuffer = 64 << 10
+
+// bufferPool holds the buffers expansions write their output to, so that
+// servers expanding many small strings do not allocate one per call
+var bufferPool = sync.Pool{
+	New: func() any {
+		b := make(buffer, 0, 256)
+		return &b
+	},
+}
+
+// buffer is a byte slice strings are appended to
+type buffer []byte
+
+func (b *buffer) WriteString(s string) {
+	*b = append(*b, s...)
+}
+
+func (b *buffer) Len() int {
+	return len(*b)
+}
+
+// String returns a copy of the contents of b, which may be reused
+func (b *buffer) String() string {
+	return string(*b)
+}
+
+// getBuffer returns an empty buffer of at least size bytes from the pool
+func getBuffer(size int) *buffer {
+	b := bufferPool.Get().(*buffer)
+	if cap(*b) < size {
+		*b = make(buffer, 0, size)
+	}
+	return b
+}
+
+// putBuffer returns b to the pool
+func putBuffer(b *buffer) {
+	if cap(*b) > maxPooledBuffer {
+		return
+	}
+	*b = (*b)[:0]
+	bufferPool.Put(b)
+}
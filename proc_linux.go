@@ -0,0 +1,35 @@
+//go:build linux
+
+package env
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// ProcessEnv reads the environment of the running process identified by pid
+// from /proc/<pid>/environ. The result is a snapshot: it reflects the
+// environment the process was started with, as reported by the kernel.
+// Reading the environment of another user's process requires privileges.
+func ProcessEnv(pid int) (*Env, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/environ")
+	if err != nil {
+		return nil, err
+	}
+	return EnvFromEnviron(splitEnviron(data)), nil
+}
+
+// splitEnviron splits the NUL-separated contents of an environ file
+func splitEnviron(data []byte) []string {
+	data = bytes.TrimRight(data, "\x00")
+	if len(data) == 0 {
+		return nil
+	}
+	fields := bytes.Split(data, []byte{0})
+	environ := make([]string, len(fields))
+	for i, field := range fields {
+		environ[i] = string(field)
+	}
+	return environ
+}
@@ -0,0 +1,45 @@
+//go:build linux
+
+package env
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestProcessEnv(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not available")
+	}
+	cmd := exec.Command(sleep, "10")
+	cmd.Env = []string{"PROC_TEST=value", "EMPTY="}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	e, err := ProcessEnv(cmd.Process.Pid)
+	if err != nil {
+		t.Fatalf("ProcessEnv() error = %v", err)
+	}
+	if want := []string{"EMPTY=", "PROC_TEST=value"}; !reflect.DeepEqual(e.Environ(), want) {
+		t.Errorf("ProcessEnv() got = %v, want %v", e.Environ(), want)
+	}
+
+	got, err := e.Expand("${PROC_TEST}")
+	if err != nil || got != "value" {
+		t.Errorf("Expand() got = %v, %v, want value", got, err)
+	}
+}
+
+func TestProcessEnvMissing(t *testing.T) {
+	if _, err := ProcessEnv(-1); !os.IsNotExist(err) {
+		t.Errorf("ProcessEnv() error = %v, want a not-exist error", err)
+	}
+}
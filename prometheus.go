@@ -0,0 +1,82 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PrometheusMetrics is a Metrics implementation exposing its counters in the
+// Prometheus text exposition format. It is an http.Handler meant to be mounted
+// on a metrics endpoint, or its output can be appended to an existing one
+// with WriteTo.
+type PrometheusMetrics struct {
+	// Namespace is prepended to the metric names, "goenv" if empty
+	Namespace string
+
+	counters Counters
+}
+
+func (p *PrometheusMetrics) ObserveLookup(name string, found bool) {
+	p.counters.ObserveLookup(name, found)
+}
+
+func (p *PrometheusMetrics) ObserveProviderLatency(provider string, d time.Duration) {
+	p.counters.ObserveProviderLatency(provider, d)
+}
+
+func (p *PrometheusMetrics) ObserveCache(hit bool) {
+	p.counters.ObserveCache(hit)
+}
+
+// ServeHTTP writes the metrics in the text exposition format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the text exposition format to w
+func (p *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	ns := p.Namespace
+	if ns == "" {
+		ns = "goenv"
+	}
+	s := p.counters.Snapshot()
+
+	var b strings.Builder
+	writeMetric := func(name, kind, help string, samples ...string) {
+		fmt.Fprintf(&b, "# HELP %s_%s %s\n", ns, name, help)
+		fmt.Fprintf(&b, "# TYPE %s_%s %s\n", ns, name, kind)
+		for _, sample := range samples {
+			fmt.Fprintf(&b, "%s_%s\n", ns, sample)
+		}
+	}
+
+	writeMetric("lookups_total", "counter", "Variable lookups performed during expansion.",
+		fmt.Sprintf("lookups_total %d", s.Lookups))
+	writeMetric("lookup_misses_total", "counter", "Variable lookups that found no value.",
+		fmt.Sprintf("lookup_misses_total %d", s.Misses))
+	writeMetric("cache_accesses_total", "counter", "Accesses to caching providers by result.",
+		fmt.Sprintf(`cache_accesses_total{result="hit"} %d`, s.CacheHits),
+		fmt.Sprintf(`cache_accesses_total{result="miss"} %d`, s.CacheMisses))
+
+	providers := make([]string, 0, len(s.ProviderCalls))
+	for provider := range s.ProviderCalls {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	var samples []string
+	for _, provider := range providers {
+		label := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(provider)
+		samples = append(samples,
+			fmt.Sprintf(`provider_lookup_seconds_sum{provider="%s"} %g`, label, s.ProviderLatency[provider].Seconds()),
+			fmt.Sprintf(`provider_lookup_seconds_count{provider="%s"} %d`, label, s.ProviderCalls[provider]))
+	}
+	writeMetric("provider_lookup_seconds", "summary", "Time spent looking up variables in providers.", samples...)
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
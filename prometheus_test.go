@@ -0,0 +1,34 @@
+package env
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	m := &PrometheusMetrics{Namespace: "app"}
+	m.ObserveLookup("A", true)
+	m.ObserveLookup("B", false)
+	m.ObserveCache(true)
+	m.ObserveProviderLatency("env.Map", 250*time.Millisecond)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE app_lookups_total counter\n",
+		"app_lookups_total 2\n",
+		"app_lookup_misses_total 1\n",
+		`app_cache_accesses_total{result="hit"} 1` + "\n",
+		`app_cache_accesses_total{result="miss"} 0` + "\n",
+		`app_provider_lookup_seconds_sum{provider="env.Map"} 0.25` + "\n",
+		`app_provider_lookup_seconds_count{provider="env.Map"} 1` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output is missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package env
+
+import (
+	"context"
+	"os"
+	"sort"
+)
+
+// Provider is a source of variables used during expansion
+type Provider interface {
+	// Lookup retrieves the value of the variable named by name.
+	// The boolean reports whether the variable is present.
+	Lookup(name string) (string, bool)
+}
+
+// ContextProvider is implemented by providers whose lookups can fail or block,
+// such as remote ones. When available, it is used instead of Lookup during
+// expansion and its errors abort the expansion.
+type ContextProvider interface {
+	Provider
+	LookupContext(ctx context.Context, name string) (string, bool, error)
+}
+
+// BatchProvider is implemented by providers that can resolve many variables
+// in one round trip, such as remote ones. Before expanding a string, the
+// variables it references are looked up together with LookupMany, and Lookup
+// is only used for the others.
+type BatchProvider interface {
+	Provider
+	// LookupMany returns the values of the variables of names that are set
+	LookupMany(names []string) (map[string]string, error)
+}
+
+// Prefetcher is implemented by caching providers that can load variables
+// ahead of their first lookup
+type Prefetcher interface {
+	Prefetch(ctx context.Context, names []string) error
+}
+
+// Setter is implemented by providers that accept assignments,
+// such as the ones performed by ${var:=default}
+type Setter interface {
+	Set(name, value string) error
+}
+
+// Lister is implemented by providers that can enumerate their variables.
+// Environ returns the variables in "key=value" form, like os.Environ.
+type Lister interface {
+	Environ() []string
+}
+
+// OS is the Provider backed by the environment of the current process
+var OS Provider = osProvider{}
+
+type osProvider struct{}
+
+func (osProvider) Lookup(name string) (string, bool) {
+	return os.LookupEnv(name)
+}
+
+func (osProvider) Set(name, value string) error {
+	return os.Setenv(name, value)
+}
+
+func (osProvider) Environ() []string {
+	return os.Environ()
+}
+
+// Map is a Provider backed by a plain map.
+// It is not safe for concurrent use while being modified.
+type Map map[string]string
+
+// Lookup returns the value stored under name
+func (m Map) Lookup(name string) (string, bool) {
+	value, ok := m[name]
+	return value, ok
+}
+
+// Set stores value under name
+func (m Map) Set(name, value string) error {
+	m[name] = value
+	return nil
+}
+
+// Environ returns the contents of the map in "key=value" form, sorted by key
+func (m Map) Environ() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	environ := make([]string, 0, len(keys))
+	for _, key := range keys {
+		environ = append(environ, key+"="+m[key])
+	}
+	return environ
+}
@@ -0,0 +1,49 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandMap(t *testing.T) {
+	vars := Map{
+		"USER":  "mapuser",
+		"EMPTY": "",
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "simple variable", input: "Hello $USER", want: "Hello mapuser"},
+		{name: "braced variable", input: "${USER}name", want: "mapusername"},
+		{name: "missing variable", input: "[$MISSING]", want: "[]"},
+		{name: "default value", input: "${EMPTY:-fallback}", want: "fallback"},
+		{name: "assignment", input: "${ASSIGNED:=value}", want: "value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input, vars)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if vars["ASSIGNED"] != "value" {
+		t.Errorf("assignment was not stored in the map, got = %v, want = value", vars["ASSIGNED"])
+	}
+}
+
+func TestMapEnviron(t *testing.T) {
+	vars := Map{"B": "2", "A": "1", "A_B": "3"}
+	want := []string{"A=1", "A_B=3", "B=2"}
+	if got := vars.Environ(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() got = %v, want %v", got, want)
+	}
+}
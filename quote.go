@@ -0,0 +1,84 @@
+package env
+
+import (
+	"runtime"
+	"strings"
+)
+
+// QuoteShell quotes value for the default shell of the current platform:
+// PowerShell on Windows and a POSIX shell elsewhere
+func QuoteShell(value string) string {
+	if runtime.GOOS == "windows" {
+		return QuotePowerShell(value)
+	}
+	return QuotePosix(value)
+}
+
+// Quote quotes value so that shell reads it back literally as a single word
+func Quote(value string, shell Shell) string {
+	switch shell {
+	case Fish:
+		return QuoteFish(value)
+	case PowerShell:
+		return QuotePowerShell(value)
+	}
+	return QuotePosix(value)
+}
+
+// QuotePosix quotes value for POSIX shells such as sh, bash and zsh.
+// Values made only of characters without special meaning are returned as-is;
+// others are single-quoted, which disables every expansion.
+func QuotePosix(value string) string {
+	if value != "" && isShellSafe(value) {
+		return value
+	}
+	return quotePosix(value)
+}
+
+// QuoteFish quotes value for the fish shell
+func QuoteFish(value string) string {
+	if value != "" && isShellSafe(value) {
+		return value
+	}
+	return quoteFish(value)
+}
+
+// QuotePowerShell quotes value for PowerShell. The value is always
+// single-quoted, which disables variable and subexpression expansion.
+func QuotePowerShell(value string) string {
+	return quotePowerShell(value)
+}
+
+// isShellSafe reports whether value only holds characters that no supported
+// shell treats specially. A leading '=' is excluded as zsh expands =cmd.
+func isShellSafe(value string) bool {
+	if value[0] == '=' {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if !isAlphaNum(c) && !strings.ContainsRune("_@%+=:,./-", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// quotePosix single-quotes s for POSIX shells, closing the quote around
+// embedded single quotes
+func quotePosix(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// quoteFish single-quotes s for fish, where backslashes and single quotes are
+// escaped inside single quotes
+func quoteFish(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// quotePowerShell single-quotes s for PowerShell, doubling embedded single
+// quotes, including the typographic ones PowerShell also accepts
+func quotePowerShell(s string) string {
+	return "'" + strings.NewReplacer("'", "''", "‘", "‘‘", "’", "’’",
+		"‚", "‚‚", "‛", "‛‛").Replace(s) + "'"
+}
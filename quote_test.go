@@ -0,0 +1,49 @@
+package env
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestQuote(t *testing.T) {
+	tests := []struct {
+		value string
+		shell Shell
+		want  string
+	}{
+		{"simple/path-1.0", Bash, "simple/path-1.0"},
+		{"", Bash, "''"},
+		{"a b", Bash, "'a b'"},
+		{"it's; rm -rf /", Bash, `'it'\''s; rm -rf /'`},
+		{"$(whoami)", Zsh, "'$(whoami)'"},
+		{"=ls", Zsh, "'=ls'"},
+		{"a=b", Zsh, "a=b"},
+		{`back\slash 'q'`, Fish, `'back\\slash \'q\''`},
+		{"plain", PowerShell, "'plain'"},
+		{"it's $env:HOME", PowerShell, "'it''s $env:HOME'"},
+		{"’smart’", PowerShell, "'’’smart’’'"},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.value, tt.shell); got != tt.want {
+			t.Errorf("Quote(%q, %v) got = %v, want %v", tt.value, tt.shell, got, tt.want)
+		}
+	}
+}
+
+func TestQuotePosixRoundTrip(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	for _, value := range []string{
+		"plain", "", "with space", "it's", `"double"`, "$HOME", "`id`", "$(id)", "a\nb", `\`, "*", "~user", "a;b|c&d",
+	} {
+		out, err := exec.Command(sh, "-c", "printf '%s' "+QuotePosix(value)).Output()
+		if err != nil {
+			t.Fatalf("sh error for %q: %v", value, err)
+		}
+		if string(out) != value {
+			t.Errorf("round trip of %q got = %q", value, out)
+		}
+	}
+}
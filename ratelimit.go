@@ -0,0 +1,118 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RateLimitedProvider limits the rate of lookups made to a provider, so that
+// expanding large templates cannot overwhelm a remote API.
+// It is safe for concurrent use if the wrapped provider is.
+type RateLimitedProvider struct {
+	base  Provider
+	rps   float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// RateLimit wraps p so that at most rps lookups per second are made on
+// average, allowing bursts of up to burst lookups. Lookups over the limit
+// wait for their turn, or fail if their context ends first. An rps of zero or
+// less, or NaN, leaves lookups unlimited, and a burst under 1 is taken as 1.
+func RateLimit(p Provider, rps float64, burst int) *RateLimitedProvider {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimitedProvider{
+		base:   p,
+		rps:    rps,
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+	}
+}
+
+// Lookup waits for its turn and looks name up in the wrapped provider.
+// Errors are reported as the variable being unset; use LookupContext to
+// observe them.
+func (r *RateLimitedProvider) Lookup(name string) (string, bool) {
+	value, found, _ := r.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext waits for its turn and looks name up in the wrapped provider.
+// It fails without waiting if the turn comes after the deadline of ctx.
+func (r *RateLimitedProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	if err := r.wait(ctx); err != nil {
+		return "", false, fmt.Errorf("rate limit: %w", err)
+	}
+	if cp, ok := r.base.(ContextProvider); ok {
+		return cp.LookupContext(ctx, name)
+	}
+	value, found := r.base.Lookup(name)
+	return value, found, nil
+}
+
+// Set assigns name in the wrapped provider without rate limiting, if it is a Setter
+func (r *RateLimitedProvider) Set(name, value string) error {
+	if setter, ok := r.base.(Setter); ok {
+		return setter.Set(name, value)
+	}
+	return nil
+}
+
+// Ping checks the wrapped provider without rate limiting, see HealthChecker
+func (r *RateLimitedProvider) Ping(ctx context.Context) error {
+	return ping(ctx, r.base)
+}
+
+// wait takes a token from the bucket, waiting for one to be refilled if
+// there is none left
+func (r *RateLimitedProvider) wait(ctx context.Context) error {
+	if !(r.rps > 0) {
+		return nil // unlimited
+	}
+	r.mu.Lock()
+	now := r.now()
+	if !r.last.IsZero() {
+		r.tokens = min(r.burst, r.tokens+now.Sub(r.last).Seconds()*r.rps)
+	}
+	r.last = now
+	r.tokens--
+	var delay time.Duration
+	if r.tokens < 0 {
+		delay = time.Duration(-r.tokens / r.rps * float64(time.Second))
+	}
+	r.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		r.release()
+		return context.DeadlineExceeded
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.release()
+		return ctx.Err()
+	}
+}
+
+// release gives back the token of a lookup that did not happen
+func (r *RateLimitedProvider) release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.tokens++
+}
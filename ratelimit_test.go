@@ -0,0 +1,61 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	r := RateLimit(Map{"A": "1"}, 50, 2)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if value, found := r.Lookup("A"); !found || value != "1" {
+			t.Fatalf("Lookup() got = %q, %v", value, found)
+		}
+	}
+	// Two lookups are served by the burst, the two others wait 20ms each
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("4 lookups took %v, want at least 30ms", elapsed)
+	}
+}
+
+func TestRateLimitDeadline(t *testing.T) {
+	r := RateLimit(Map{"A": "1"}, 1, 1)
+	if _, _, err := r.LookupContext(context.Background(), "A"); err != nil {
+		t.Fatalf("LookupContext() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err := r.LookupContext(ctx, "A")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LookupContext() error = %v, want a deadline error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("LookupContext() waited %v for a turn past its deadline", elapsed)
+	}
+
+	// The abandoned turn is given back
+	r.now = func() time.Time { return time.Now().Add(time.Second) }
+	if _, _, err := r.LookupContext(context.Background(), "A"); err != nil {
+		t.Errorf("LookupContext() error = %v", err)
+	}
+}
+
+func TestRateLimitUnlimited(t *testing.T) {
+	for _, rps := range []float64{0, -1, math.NaN()} {
+		r := RateLimit(Map{"A": "1"}, rps, 0)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		for i := 0; i < 100; i++ {
+			if value, found, err := r.LookupContext(ctx, "A"); err != nil || !found || value != "1" {
+				t.Fatalf("rps %v: LookupContext() got = %q, %v, %v", rps, value, found, err)
+			}
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,55 @@
+package env
+
+import (
+	"bytes"
+	"io"
+)
+
+// NewExpandingReader returns a reader yielding the content of r with its
+// variables expanded, as configured by opts. The content is expanded as it is
+// read, so it can be piped into decoders without buffering it whole.
+// Read fails if expansion fails or r ends inside an unclosed ${...} expression.
+func NewExpandingReader(r io.Reader, opts ...Option) io.Reader {
+	er := &expandingReader{src: r}
+	er.x = NewExpander(append(opts, WithOutput(&er.buf))...)
+	return er
+}
+
+type expandingReader struct {
+	src   io.Reader
+	x     *Expander
+	buf   bytes.Buffer // expanded content not yet read
+	chunk []byte       // read buffer for src
+	err   error        // error to return once buf is drained
+}
+
+func (r *expandingReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if r.chunk == nil {
+		r.chunk = make([]byte, 4096)
+	}
+	for r.buf.Len() == 0 && r.err == nil {
+		n, err := r.src.Read(r.chunk)
+		if n > 0 {
+			if _, werr := r.x.Write(r.chunk[:n]); werr != nil {
+				r.err = werr
+				break
+			}
+		}
+		if err == io.EOF {
+			if ferr := r.x.Flush(); ferr != nil {
+				r.err = ferr
+			} else {
+				r.err = io.EOF
+			}
+		} else if err != nil {
+			r.err = err
+		}
+	}
+	if r.buf.Len() > 0 {
+		return r.buf.Read(p)
+	}
+	return 0, r.err
+}
@@ -0,0 +1,52 @@
+package env
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestExpandingReader(t *testing.T) {
+	vars := Map{"HOST": "db", "PORT": "5432"}
+	input := `{"host": "${HOST}", "port": $PORT, "user": "${DB_USER:-app}"}`
+
+	got, err := io.ReadAll(NewExpandingReader(iotest.OneByteReader(strings.NewReader(input)), WithProvider(vars)))
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	want := `{"host": "db", "port": 5432, "user": "app"}`
+	if string(got) != want {
+		t.Errorf("ReadAll() got = %q, want %q", got, want)
+	}
+
+	var cfg struct {
+		Host string `json:"host"`
+		Port int    `json:"port"`
+	}
+	if err := json.NewDecoder(NewExpandingReader(strings.NewReader(input), WithProvider(vars))).Decode(&cfg); err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if cfg.Host != "db" || cfg.Port != 5432 {
+		t.Errorf("Decode() got = %+v", cfg)
+	}
+}
+
+func TestExpandingReaderErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"unclosed brace", "a ${B"},
+		{"required variable", "a ${B:?missing}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := io.ReadAll(NewExpandingReader(strings.NewReader(tt.input), WithProvider(Map{})))
+			if err == nil {
+				t.Error("ReadAll() expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package env
+
+import "strings"
+
+// redacted replaces secret values in logs
+const redacted = "****"
+
+// secretMarkers are the name fragments IsSecretName looks for
+var secretMarkers = []string{
+	"PASSWORD", "PASSWD", "SECRET", "TOKEN", "API_KEY", "APIKEY",
+	"PRIVATE_KEY", "CREDENTIAL",
+}
+
+// IsSecretName reports whether name looks like the name of a variable holding
+// a secret, e.g. DB_PASSWORD or GITHUB_TOKEN. It is the default redaction rule.
+func IsSecretName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, marker := range secretMarkers {
+		if strings.Contains(upper, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// WithRedaction decides which variables hold secrets whose values must not be
+// written to logs, replacing the IsSecretName default
+func WithRedaction(isSecret func(name string) bool) Option {
+	return func(c *config) {
+		c.isSecret = isSecret
+	}
+}
+
+// redact returns value, or a placeholder if name holds a secret
+func (c *config) redact(name, value string) string {
+	isSecret := c.isSecret
+	if isSecret == nil {
+		isSecret = IsSecretName
+	}
+	if isSecret(name) {
+		return redacted
+	}
+	return value
+}
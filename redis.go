@@ -0,0 +1,125 @@
+package env
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// RedisClient is the subset of a Redis client used by RedisProvider.
+// Adapting a client library takes a few lines; with go-redis for example:
+//
+//	func (a adapter) Get(ctx context.Context, key string) (string, bool, error) {
+//		value, err := a.rdb.Get(ctx, key).Result()
+//		if errors.Is(err, redis.Nil) {
+//			return "", false, nil
+//		}
+//		return value, err == nil, err
+//	}
+type RedisClient interface {
+	// Get returns the string stored at key
+	Get(ctx context.Context, key string) (string, bool, error)
+	// HGet returns the value of field in the hash stored at key
+	HGet(ctx context.Context, key, field string) (string, bool, error)
+}
+
+// RedisProvider resolves variables from Redis, either from string keys or from
+// the fields of a single hash. Lookups go to Redis every time; wrap the
+// provider with Cached and use InvalidateOn to keep the cache up to date.
+type RedisProvider struct {
+	client RedisClient
+	prefix string
+	hash   string
+}
+
+// RedisOption configures a RedisProvider
+type RedisOption func(*RedisProvider)
+
+// RedisKeyPrefix resolves variable NAME from the key prefix+NAME
+func RedisKeyPrefix(prefix string) RedisOption {
+	return func(r *RedisProvider) {
+		r.prefix = prefix
+	}
+}
+
+// RedisHash resolves variables from the fields of the hash stored at key
+func RedisHash(key string) RedisOption {
+	return func(r *RedisProvider) {
+		r.hash = key
+	}
+}
+
+// NewRedisProvider returns a provider resolving variables through client
+func NewRedisProvider(client RedisClient, opts ...RedisOption) *RedisProvider {
+	r := &RedisProvider{client: client}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Lookup returns the value of name, reporting errors as the variable being unset
+func (r *RedisProvider) Lookup(name string) (string, bool) {
+	value, found, _ := r.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext returns the value of name from Redis
+func (r *RedisProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	if r.hash != "" {
+		return r.client.HGet(ctx, r.hash, name)
+	}
+	return r.client.Get(ctx, r.prefix+name)
+}
+
+// Ping checks that Redis answers, see HealthChecker. It uses the Ping method
+// of the client if it has one, and otherwise looks up a variable with an
+// empty name.
+func (r *RedisProvider) Ping(ctx context.Context) error {
+	if p, ok := r.client.(HealthChecker); ok {
+		return p.Ping(ctx)
+	}
+	_, _, err := r.LookupContext(ctx, "")
+	return err
+}
+
+// KeyspacePattern returns the channel pattern to PSUBSCRIBE to for receiving
+// the keyspace notifications relevant to the provider in database db.
+// Notifications must be enabled on the server, e.g. with
+// "CONFIG SET notify-keyspace-events K$h".
+func (r *RedisProvider) KeyspacePattern(db int) string {
+	prefix := "__keyspace@" + strconv.Itoa(db) + "__:"
+	if r.hash != "" {
+		return prefix + r.hash
+	}
+	return prefix + r.prefix + "*"
+}
+
+// InvalidateOn drops entries from cache as keyspace notifications arrive on
+// channels, until channels is closed or ctx is done. channels receives the
+// channel name of every notification, e.g. "__keyspace@0__:MYAPP_DB_HOST".
+// A change to the hash of the provider invalidates the whole cache.
+func (r *RedisProvider) InvalidateOn(ctx context.Context, cache *CachedProvider, channels <-chan string) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case channel, ok := <-channels:
+			if !ok {
+				return
+			}
+			_, key, found := strings.Cut(channel, "__:")
+			if !found {
+				continue
+			}
+			switch {
+			case r.hash != "":
+				if key == r.hash {
+					cache.Invalidate()
+				}
+			case strings.HasPrefix(key, r.prefix):
+				cache.Invalidate(key[len(r.prefix):])
+			}
+		}
+	}
+}
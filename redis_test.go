@@ -0,0 +1,86 @@
+package env
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// fakeRedis is an in-memory RedisClient
+type fakeRedis struct {
+	mu      sync.Mutex
+	strings map[string]string
+	hashes  map[string]map[string]string
+	calls   int
+}
+
+func (f *fakeRedis) Get(_ context.Context, key string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	value, ok := f.strings[key]
+	return value, ok, nil
+}
+
+func (f *fakeRedis) HGet(_ context.Context, key, field string) (string, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	value, ok := f.hashes[key][field]
+	return value, ok, nil
+}
+
+func (f *fakeRedis) set(key, value string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.strings[key] = value
+}
+
+func TestRedisProvider(t *testing.T) {
+	client := &fakeRedis{
+		strings: map[string]string{"myapp:HOST": "db.internal"},
+		hashes:  map[string]map[string]string{"config": {"PORT": "6379"}},
+	}
+
+	got, err := Expand("$HOST:${PORT:-none}", NewRedisProvider(client, RedisKeyPrefix("myapp:")))
+	if err != nil || got != "db.internal:none" {
+		t.Errorf("Expand() with keys got = %v, %v", got, err)
+	}
+
+	got, err = Expand("${HOST:-none}:$PORT", NewRedisProvider(client, RedisHash("config")))
+	if err != nil || got != "none:6379" {
+		t.Errorf("Expand() with hash got = %v, %v", got, err)
+	}
+}
+
+func TestRedisInvalidation(t *testing.T) {
+	client := &fakeRedis{strings: map[string]string{"myapp:HOST": "old"}}
+	r := NewRedisProvider(client, RedisKeyPrefix("myapp:"))
+	cache := Cached(r, 0)
+
+	if pattern := r.KeyspacePattern(0); pattern != "__keyspace@0__:myapp:*" {
+		t.Errorf("KeyspacePattern() got = %v", pattern)
+	}
+
+	if got, _ := cache.Lookup("HOST"); got != "old" {
+		t.Fatalf("Lookup() got = %v, want old", got)
+	}
+	client.set("myapp:HOST", "new")
+
+	channels := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		r.InvalidateOn(context.Background(), cache, channels)
+		close(done)
+	}()
+	channels <- "__keyspace@0__:myapp:HOST"
+	close(channels)
+	<-done
+
+	if got, _ := cache.Lookup("HOST"); got != "new" {
+		t.Errorf("Lookup() got = %v, want new after the notification", got)
+	}
+	if client.calls != 2 {
+		t.Errorf("redis calls got = %d, want 2", client.calls)
+	}
+}
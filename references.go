@@ -0,0 +1,74 @@
+package env
+
+import "fmt"
+
+// Reference is a variable reference found in a template
+type Reference struct {
+	// Name is the name of the variable as written
+	Name string
+	// Op is the operator of a braced reference, such as ":-", or empty
+	Op string
+	// Word is the text following the operator
+	Word string
+	// Offset is the byte offset of the '$' starting the reference
+	Offset int
+	// Braced reports whether the reference uses the ${...} form
+	Braced bool
+	// Valid reports whether Name is a valid variable name. Invalid braced
+	// references are kept as literals by the expander.
+	Valid bool
+}
+
+// SyntaxError reports a malformed template
+type SyntaxError struct {
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("offset %d: %s", e.Offset, e.Msg)
+}
+
+// References returns the variable references of input in order of appearance,
+// without resolving them. It fails with a *SyntaxError if input cannot be
+// expanded.
+func References(input string) ([]Reference, error) {
+	var refs []Reference
+	for i := 0; i < len(input); i++ {
+		if input[i] != '$' || i+1 >= len(input) {
+			continue
+		}
+		start := i
+		i++
+
+		if input[i] != '{' {
+			if !isLetter(input[i]) && input[i] != '_' {
+				i--
+				continue
+			}
+			end := i
+			for end < len(input) && (isAlphaNum(input[end]) || input[end] == '_') && end-i < 64 {
+				end++
+			}
+			refs = append(refs, Reference{Name: input[i:end], Offset: start, Valid: true})
+			i = end - 1
+			continue
+		}
+
+		end := defaultSyntax.match(input, start)
+		if end == -1 {
+			return refs, &SyntaxError{Offset: start, Msg: "unclosed brace in variable expression"}
+		}
+		name, op, word := splitBraced(input[i+1 : end])
+		refs = append(refs, Reference{
+			Name:   name,
+			Op:     op,
+			Word:   word,
+			Offset: start,
+			Braced: true,
+			Valid:  isValidVarName(name),
+		})
+		i = end
+	}
+	return refs, nil
+}
@@ -0,0 +1,31 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestReferences(t *testing.T) {
+	got, err := References("$USER at ${HOST:-localhost}:${PORT} ${bad-name} $1 $")
+	if err != nil {
+		t.Fatalf("References() error = %v", err)
+	}
+	want := []Reference{
+		{Name: "USER", Offset: 0, Valid: true},
+		{Name: "HOST", Op: ":-", Word: "localhost", Offset: 9, Braced: true, Valid: true},
+		{Name: "PORT", Offset: 28, Braced: true, Valid: true},
+		{Name: "bad-name", Offset: 36, Braced: true, Valid: false},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("References() got = %+v, want %+v", got, want)
+	}
+}
+
+func TestReferencesSyntaxError(t *testing.T) {
+	_, err := References("ok ${UNCLOSED")
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) || syntaxErr.Offset != 3 {
+		t.Errorf("References() error = %v, want a *SyntaxError at offset 3", err)
+	}
+}
@@ -0,0 +1,207 @@
+//go:build windows
+
+package env
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// RegistryScope selects one of the environment blocks stored in the registry
+type RegistryScope int
+
+const (
+	// UserEnvironment is HKEY_CURRENT_USER\Environment
+	UserEnvironment RegistryScope = iota
+	// SystemEnvironment is HKEY_LOCAL_MACHINE\SYSTEM\CurrentControlSet\Control\Session Manager\Environment
+	SystemEnvironment
+)
+
+func (s RegistryScope) key() (syscall.Handle, string) {
+	if s == SystemEnvironment {
+		return syscall.HKEY_LOCAL_MACHINE, `SYSTEM\CurrentControlSet\Control\Session Manager\Environment`
+	}
+	return syscall.HKEY_CURRENT_USER, `Environment`
+}
+
+var (
+	modadvapi32                  = syscall.NewLazyDLL("advapi32.dll")
+	modkernel32                  = syscall.NewLazyDLL("kernel32.dll")
+	procRegEnumValueW            = modadvapi32.NewProc("RegEnumValueW")
+	procExpandEnvironmentStrings = modkernel32.NewProc("ExpandEnvironmentStringsW")
+)
+
+// RegistryProvider resolves variables from the environment stored in the
+// Windows registry rather than from the environment inherited by the process.
+// The registry is read on every lookup, so changes made after the process
+// started, e.g. with setx, are visible.
+type RegistryProvider struct {
+	scopes []RegistryScope
+}
+
+// Registry returns a provider reading the given scopes, earlier scopes taking
+// precedence. Without scopes, the user environment is consulted before the
+// system one, like Windows does when building a new environment.
+// PATH is the exception: as on Windows, the system and user values are joined.
+func Registry(scopes ...RegistryScope) *RegistryProvider {
+	if len(scopes) == 0 {
+		scopes = []RegistryScope{UserEnvironment, SystemEnvironment}
+	}
+	return &RegistryProvider{scopes: scopes}
+}
+
+// Lookup returns the value of name from the registry. REG_EXPAND_SZ values
+// have their %VAR% references expanded.
+func (r *RegistryProvider) Lookup(name string) (string, bool) {
+	if strings.EqualFold(name, "PATH") {
+		return r.lookupPath()
+	}
+	for _, scope := range r.scopes {
+		if value, ok, err := readRegistryValue(scope, name); err == nil && ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// lookupPath joins the PATH values of all scopes, system entries first
+func (r *RegistryProvider) lookupPath() (string, bool) {
+	var parts []string
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		if value, ok, err := readRegistryValue(r.scopes[i], "Path"); err == nil && ok && value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, ";"), len(parts) > 0
+}
+
+// Environ returns the variables of all scopes in "key=value" form, sorted by key
+func (r *RegistryProvider) Environ() []string {
+	vars := make(Map)
+	canonical := make(map[string]string) // upper-cased name to stored name
+	for i := len(r.scopes) - 1; i >= 0; i-- {
+		names, err := readRegistryNames(r.scopes[i])
+		if err != nil {
+			continue
+		}
+		for _, name := range names {
+			value, ok, err := readRegistryValue(r.scopes[i], name)
+			if err != nil || !ok {
+				continue
+			}
+			if previous, ok := canonical[strings.ToUpper(name)]; ok {
+				delete(vars, previous)
+			}
+			canonical[strings.ToUpper(name)] = name
+			vars[name] = value
+		}
+	}
+	if path, ok := r.lookupPath(); ok {
+		if previous, ok := canonical["PATH"]; ok {
+			vars[previous] = path
+		}
+	}
+	return vars.Environ()
+}
+
+// readRegistryValue reads a single string value from the environment key of scope
+func readRegistryValue(scope RegistryScope, name string) (string, bool, error) {
+	root, path := scope.key()
+	key, err := openRegistryKey(root, path)
+	if err != nil {
+		return "", false, err
+	}
+	defer syscall.RegCloseKey(key)
+
+	namePtr, err := syscall.UTF16PtrFromString(name)
+	if err != nil {
+		return "", false, err
+	}
+	var valueType, size uint32
+	err = syscall.RegQueryValueEx(key, namePtr, nil, &valueType, nil, &size)
+	if errors.Is(err, syscall.ERROR_FILE_NOT_FOUND) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	if valueType != syscall.REG_SZ && valueType != syscall.REG_EXPAND_SZ {
+		return "", false, nil
+	}
+
+	buf := make([]uint16, size/2+1)
+	size = uint32(len(buf) * 2)
+	if err := syscall.RegQueryValueEx(key, namePtr, nil, &valueType, (*byte)(unsafe.Pointer(&buf[0])), &size); err != nil {
+		return "", false, err
+	}
+	value := syscall.UTF16ToString(buf)
+	if valueType == syscall.REG_EXPAND_SZ {
+		value = expandEnvironmentStrings(value)
+	}
+	return value, true, nil
+}
+
+// readRegistryNames lists the value names of the environment key of scope
+func readRegistryNames(scope RegistryScope) ([]string, error) {
+	root, path := scope.key()
+	key, err := openRegistryKey(root, path)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.RegCloseKey(key)
+
+	var count, maxNameLen uint32
+	if err := syscall.RegQueryInfoKey(key, nil, nil, nil, nil, nil, nil, &count, &maxNameLen, nil, nil, nil); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, count)
+	buf := make([]uint16, maxNameLen+1)
+	for i := uint32(0); i < count; i++ {
+		length := uint32(len(buf))
+		r, _, _ := procRegEnumValueW.Call(uintptr(key), uintptr(i),
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&length)), 0, 0, 0, 0)
+		if r != 0 {
+			continue
+		}
+		names = append(names, syscall.UTF16ToString(buf[:length]))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func openRegistryKey(root syscall.Handle, path string) (syscall.Handle, error) {
+	pathPtr, err := syscall.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	var key syscall.Handle
+	if err := syscall.RegOpenKeyEx(root, pathPtr, 0, syscall.KEY_READ, &key); err != nil {
+		return 0, err
+	}
+	return key, nil
+}
+
+// expandEnvironmentStrings expands %VAR% references the way Windows does for
+// REG_EXPAND_SZ values, returning value unchanged on failure
+func expandEnvironmentStrings(value string) string {
+	src, err := syscall.UTF16PtrFromString(value)
+	if err != nil {
+		return value
+	}
+	buf := make([]uint16, len(value)*2+1)
+	for {
+		n, _, _ := procExpandEnvironmentStrings.Call(uintptr(unsafe.Pointer(src)),
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)))
+		if n == 0 {
+			return value
+		}
+		if int(n) <= len(buf) {
+			return syscall.UTF16ToString(buf[:n])
+		}
+		buf = make([]uint16, n)
+	}
+}
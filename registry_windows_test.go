@@ -0,0 +1,36 @@
+//go:build windows
+
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegistryProvider(t *testing.T) {
+	r := Registry()
+
+	// SystemRoot is always present in the system environment
+	if value, ok := Registry(SystemEnvironment).Lookup("SystemRoot"); ok && value == "" {
+		t.Errorf("Lookup(SystemRoot) returned an empty value")
+	}
+
+	path, ok := r.Lookup("PATH")
+	if !ok || path == "" {
+		t.Fatalf("Lookup(PATH) got = %q, %v, want a non-empty value", path, ok)
+	}
+
+	if _, ok := r.Lookup("GO_ENV_SURELY_MISSING_VARIABLE"); ok {
+		t.Errorf("Lookup() found a variable that does not exist")
+	}
+
+	found := false
+	for _, kv := range r.Environ() {
+		if strings.HasPrefix(strings.ToUpper(kv), "PATH=") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Environ() does not contain PATH")
+	}
+}
@@ -0,0 +1,110 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+// Reloader is implemented by providers that can read their variables again,
+// such as a CachedProvider or a layered config.Live
+type Reloader interface {
+	Reload() error
+}
+
+// ReloadOn reloads r every time the process receives one of sig, such as
+// syscall.SIGHUP, until the returned function is called. A failed reload is
+// logged to slog.Default and leaves r as it was. Without signals, nothing is
+// reloaded.
+func ReloadOn(r Reloader, sig ...os.Signal) (stop func()) {
+	if len(sig) == 0 {
+		// signal.Notify would relay every signal
+		return func() {}
+	}
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, sig...)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case s := <-signals:
+				if err := r.Reload(); err != nil {
+					slog.Default().LogAttrs(context.Background(), slog.LevelError, "failed to reload variables",
+						slog.String("signal", s.String()),
+						slog.Any("error", err))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(done)
+		})
+	}
+}
+
+// OnChange registers fn to be called by Reload with the changes of the
+// variables that were cached, sorted by name. It is not called when nothing
+// changed.
+func (c *CachedProvider) OnChange(fn func(changes []dotenv.Change)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onChange = append(c.onChange, fn)
+}
+
+// Reload flushes the cache. If change callbacks are registered, the variables
+// that were cached are looked up again to report their changes, and the cache
+// is only replaced if all of them could be looked up.
+func (c *CachedProvider) Reload() error {
+	c.mu.Lock()
+	previous := c.entries
+	callbacks := c.onChange
+	if len(callbacks) == 0 {
+		c.entries = make(map[string]cacheEntry)
+	}
+	c.mu.Unlock()
+	if len(callbacks) == 0 {
+		return nil
+	}
+
+	entries := make(map[string]cacheEntry, len(previous))
+	before, after := make(map[string]string), make(map[string]string)
+	for name, entry := range previous {
+		if entry.found {
+			before[name] = entry.value
+		}
+		value, found, err := c.lookupBase(context.Background(), name)
+		if err != nil {
+			return fmt.Errorf("failed to look up variable '%s': %w", name, err)
+		}
+		entries[name] = c.entry(value, found)
+		if found {
+			after[name] = value
+		}
+	}
+
+	c.mu.Lock()
+	c.entries = entries
+	c.mu.Unlock()
+	if changes := dotenv.DiffVars(before, after); len(changes) > 0 {
+		for _, fn := range callbacks {
+			fn(changes)
+		}
+	}
+	return nil
+}
+
+// ReloadOn flushes the cache every time the process receives one of sig,
+// see ReloadOn
+func (c *CachedProvider) ReloadOn(sig ...os.Signal) (stop func()) {
+	return ReloadOn(c, sig...)
+}
@@ -0,0 +1,84 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+func TestCachedProviderReload(t *testing.T) {
+	vars := Map{"HOST": "db", "PORT": "5432", "USER": "app"}
+	c := Cached(vars, 0)
+	for _, name := range []string{"HOST", "PORT", "NAME"} {
+		c.Lookup(name)
+	}
+	var got [][]dotenv.Change
+	c.OnChange(func(changes []dotenv.Change) {
+		got = append(got, changes)
+	})
+
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Reload() reported changes %v, want none", got)
+	}
+
+	vars["HOST"] = "replica"
+	delete(vars, "PORT")
+	vars["NAME"] = "app"
+	vars["USER"] = "admin"
+	if value, _ := c.Lookup("HOST"); value != "db" {
+		t.Fatalf("Lookup(HOST) got = %q before Reload, want the cached value", value)
+	}
+	if err := c.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	want := [][]dotenv.Change{{
+		{Key: "HOST", Kind: dotenv.Changed, Old: "db", New: "replica"},
+		{Key: "NAME", Kind: dotenv.Added, New: "app"},
+		{Key: "PORT", Kind: dotenv.Removed, Old: "5432"},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Reload() changes = %+v, want %+v", got, want)
+	}
+	if value, _ := c.Lookup("HOST"); value != "replica" {
+		t.Errorf("Lookup(HOST) got = %q after Reload", value)
+	}
+}
+
+func TestCachedProviderReloadFailure(t *testing.T) {
+	base := &flakyProvider{Map: Map{"HOST": "db"}}
+	c := Cached(base, 0)
+	c.Lookup("HOST")
+	c.OnChange(func([]dotenv.Change) {
+		t.Error("OnChange called after a failed Reload")
+	})
+
+	base.Map["HOST"] = "replica"
+	base.failures, base.err = base.calls+1, errors.New("backend unavailable")
+	if err := c.Reload(); err == nil {
+		t.Fatal("Reload() expected an error")
+	}
+	if value, _ := c.Lookup("HOST"); value != "db" {
+		t.Errorf("Lookup(HOST) got = %q after a failed Reload, want the cached value", value)
+	}
+}
+
+func TestReloadOnWithoutSignals(t *testing.T) {
+	reloads := 0
+	stop := ReloadOn(reloaderFunc(func() error {
+		reloads++
+		return nil
+	}))
+	stop()
+	if reloads != 0 {
+		t.Errorf("ReloadOn() without signals reloaded %d times", reloads)
+	}
+}
+
+type reloaderFunc func() error
+
+func (f reloaderFunc) Reload() error { return f() }
@@ -0,0 +1,38 @@
+//go:build unix
+
+package env
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-env/dotenv"
+)
+
+func TestReloadOn(t *testing.T) {
+	vars := NewEnv(map[string]string{"HOST": "db"})
+	c := Cached(vars, 0)
+	c.Lookup("HOST")
+	changed := make(chan []dotenv.Change, 1)
+	c.OnChange(func(changes []dotenv.Change) {
+		changed <- changes
+	})
+	stop := c.ReloadOn(syscall.SIGHUP)
+	defer stop()
+
+	if err := vars.Set("HOST", "replica"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case changes := <-changed:
+		if len(changes) != 1 || changes[0].Key != "HOST" || changes[0].New != "replica" {
+			t.Errorf("changes = %+v", changes)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGHUP did not reload the provider")
+	}
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"context"
+	"strings"
+)
+
+// renamed is a Provider translating the requested names before resolving
+// them from the wrapped provider
+type renamed struct {
+	base Provider
+	// mapName returns the underlying name for a requested one, or false if the
+	// requested name cannot be resolved
+	mapName func(name string) (string, bool)
+}
+
+// Renamed wraps p so that every requested name is translated by mapName
+// before being looked up or assigned in p
+func Renamed(p Provider, mapName func(name string) string) Provider {
+	return &renamed{base: p, mapName: func(name string) (string, bool) {
+		return mapName(name), true
+	}}
+}
+
+// Prefixed wraps p so that a requested name resolves the underlying variable
+// with prefix prepended, e.g. ${DB_HOST} resolves MYAPP_DB_HOST for the prefix
+// "MYAPP_"
+func Prefixed(p Provider, prefix string) Provider {
+	return &renamed{base: p, mapName: func(name string) (string, bool) {
+		return prefix + name, true
+	}}
+}
+
+// Unprefixed wraps p so that a requested name has prefix removed before being
+// resolved, e.g. ${MYAPP_DB_HOST} resolves DB_HOST for the prefix "MYAPP_".
+// Names not starting with prefix are reported as not present.
+func Unprefixed(p Provider, prefix string) Provider {
+	return &renamed{base: p, mapName: func(name string) (string, bool) {
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			return "", false
+		}
+		return name[len(prefix):], true
+	}}
+}
+
+// Aliased wraps p so that names found in aliases are resolved under the name
+// they map to. Other names are resolved unchanged.
+func Aliased(p Provider, aliases map[string]string) Provider {
+	table := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		table[from] = to
+	}
+	return &renamed{base: p, mapName: func(name string) (string, bool) {
+		if to, ok := table[name]; ok {
+			return to, true
+		}
+		return name, true
+	}}
+}
+
+// Lookup resolves the translated name from the wrapped provider
+func (r *renamed) Lookup(name string) (string, bool) {
+	underlying, ok := r.mapName(name)
+	if !ok {
+		return "", false
+	}
+	return r.base.Lookup(underlying)
+}
+
+// Set assigns the translated name in the wrapped provider, if it is a Setter
+func (r *renamed) Set(name, value string) error {
+	setter, ok := r.base.(Setter)
+	if !ok {
+		return nil
+	}
+	underlying, ok := r.mapName(name)
+	if !ok {
+		return nil
+	}
+	return setter.Set(underlying, value)
+}
+
+// Ping checks the wrapped provider, see HealthChecker
+func (r *renamed) Ping(ctx context.Context) error {
+	return ping(ctx, r.base)
+}
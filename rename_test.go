@@ -0,0 +1,65 @@
+package env
+
+import "testing"
+
+func TestRenamedProviders(t *testing.T) {
+	base := Map{
+		"MYAPP_DB_HOST": "db.internal",
+		"DB_PORT":       "5432",
+		"LEGACY_USER":   "admin",
+	}
+
+	tests := []struct {
+		name     string
+		provider Provider
+		input    string
+		want     string
+	}{
+		{
+			name:     "prefixed",
+			provider: Prefixed(base, "MYAPP_"),
+			input:    "${DB_HOST}:${DB_PORT:-none}",
+			want:     "db.internal:none",
+		},
+		{
+			name:     "unprefixed",
+			provider: Unprefixed(base, "MYAPP_"),
+			input:    "${MYAPP_DB_PORT}|${DB_PORT}|${MYAPP_}",
+			want:     "5432||",
+		},
+		{
+			name:     "aliased",
+			provider: Aliased(base, map[string]string{"DB_USER": "LEGACY_USER"}),
+			input:    "$DB_USER@$DB_PORT",
+			want:     "admin@5432",
+		},
+		{
+			name:     "renamed",
+			provider: Renamed(base, func(name string) string { return "MYAPP_" + name }),
+			input:    "$DB_HOST",
+			want:     "db.internal",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input, tt.provider)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefixedAssignment(t *testing.T) {
+	base := Map{}
+	if _, err := Expand("${LEVEL:=debug}", Prefixed(base, "MYAPP_")); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if got := base["MYAPP_LEVEL"]; got != "debug" {
+		t.Errorf("assignment got = %v, want debug under MYAPP_LEVEL", got)
+	}
+}
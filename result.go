@@ -0,0 +1,89 @@
+package env
+
+import "slices"
+
+// Result describes the outcome of an expansion
+type Result struct {
+	// Output is the expanded text
+	Output string
+	// Unresolved lists, in order of first appearance, the variables
+	// referenced without a default or alternative that were not set
+	Unresolved []string
+	// Substitutions is the number of references replaced in the output
+	Substitutions int
+	// Assignments holds the variables assigned by ${var:=default}
+	Assignments map[string]string
+	// Resolved holds the number of variables resolved from each provider,
+	// named like TraceStep.Source
+	Resolved map[string]int
+	// Bytes is the size of the output
+	Bytes int
+}
+
+// ExpandDetailed expands the input string like Expand, returning the output
+// together with details on how it was produced, such as the variables that
+// could not be resolved
+func (x *Expander) ExpandDetailed(input string) (Result, error) {
+	e := &expander{cfg: &x.cfg, pure: x.cfg.pure, result: &Result{
+		Assignments: make(map[string]string),
+		Resolved:    make(map[string]int),
+	}}
+	output, err := e.expandCommit(input)
+	if err != nil {
+		return Result{}, err
+	}
+	e.result.Output = output
+	e.result.Bytes = len(output)
+	return *e.result, nil
+}
+
+// ExpandEnvDetailed expands environment variables in the input string like
+// ExpandEnv, returning the output together with details on how it was produced
+func ExpandEnvDetailed(input string) (Result, error) {
+	return NewExpander().ExpandDetailed(input)
+}
+
+// recordSubstitution records a reference without operator replaced in the output
+func (e *expander) recordSubstitution(name string, found bool) {
+	if e.cfg.stats != nil {
+		e.cfg.stats.add(0, 1, 0, "")
+	}
+	if e.result == nil {
+		return
+	}
+	e.result.Substitutions++
+	if !found {
+		e.recordUnresolved(name)
+	}
+}
+
+// recordOperator records a reference with operator replaced in the output
+func (e *expander) recordOperator() {
+	if e.cfg.stats != nil {
+		e.cfg.stats.add(0, 1, 0, "")
+	}
+	if e.result != nil {
+		e.result.Substitutions++
+	}
+}
+
+// recordResolved records a variable found in the provider or assignments
+func (e *expander) recordResolved(name string) {
+	if e.result == nil && e.cfg.stats == nil {
+		return
+	}
+	source := e.source(name)
+	if e.cfg.stats != nil {
+		e.cfg.stats.add(0, 0, 0, source)
+	}
+	if e.result != nil {
+		e.result.Resolved[source]++
+	}
+}
+
+// recordUnresolved records a reference to a variable that is not set
+func (e *expander) recordUnresolved(name string) {
+	if e.result != nil && !slices.Contains(e.result.Unresolved, name) {
+		e.result.Unresolved = append(e.result.Unresolved, name)
+	}
+}
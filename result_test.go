@@ -0,0 +1,30 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExpandDetailed(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"HOST": "db", "EMPTY": ""}), WithPure(), WithDualSyntax())
+
+	got, err := x.ExpandDetailed("$HOST:${PORT} $USER ${PORT} $EMPTY ${MODE:-dev} ${LEVEL:=info} %TEMP% $$")
+	if err != nil {
+		t.Fatalf("ExpandDetailed() error = %v", err)
+	}
+	want := Result{
+		Output:        "db:    dev info %TEMP% $",
+		Unresolved:    []string{"PORT", "USER", "TEMP"},
+		Substitutions: 7,
+		Assignments:   map[string]string{"LEVEL": "info"},
+		Resolved:      map[string]int{"env.Map": 2},
+		Bytes:         24,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandDetailed() got = %+v, want %+v", got, want)
+	}
+
+	if _, err := x.ExpandDetailed("${A:?required}"); err == nil {
+		t.Error("ExpandDetailed() expected an error")
+	}
+}
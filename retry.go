@@ -0,0 +1,146 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// NotFoundError reports a variable missing from a provider whose API treats
+// missing variables as failures. Retry reports it as the variable being unset
+// and does not retry it.
+type NotFoundError struct {
+	Name string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("variable '%s' not found", e.Name)
+}
+
+// UnavailableError reports a provider that failed temporarily, for example
+// because it could not be reached or was overloaded. Retry retries lookups
+// failing with it.
+type UnavailableError struct {
+	Err error
+}
+
+func (e *UnavailableError) Error() string {
+	return "provider unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// Backoff describes how failed lookups are retried. Zero fields take their
+// default value.
+type Backoff struct {
+	// Attempts is the maximum number of attempts, 3 by default
+	Attempts int
+	// Initial is the delay before the first retry, 100ms by default
+	Initial time.Duration
+	// Max bounds the delay between attempts, which is unbounded by default
+	Max time.Duration
+	// Multiplier is the factor applied to the delay after each retry, 2 by default
+	Multiplier float64
+	// Jitter is the fraction of each delay that is randomized, between 0 and 1,
+	// so that many clients failing together do not retry in lockstep
+	Jitter float64
+}
+
+// delay returns the delay before the retry following the given attempt,
+// counted from 1
+func (b Backoff) delay(attempt int, random float64) time.Duration {
+	initial, multiplier := b.Initial, b.Multiplier
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	d := float64(initial)
+	for i := 1; i < attempt; i++ {
+		d *= multiplier
+		if b.Max > 0 && d >= float64(b.Max) {
+			break
+		}
+	}
+	if b.Max > 0 {
+		d = min(d, float64(b.Max))
+	}
+	return time.Duration(d * (1 - b.Jitter*random))
+}
+
+// RetryProvider retries the lookups of a provider failing with an
+// *UnavailableError. Other errors are returned at once.
+// It is safe for concurrent use if the wrapped provider is.
+type RetryProvider struct {
+	base   Provider
+	policy Backoff
+	random func() float64
+}
+
+// Retry wraps p so that its transient failures are retried with exponential
+// backoff according to policy
+func Retry(p Provider, policy Backoff) *RetryProvider {
+	return &RetryProvider{base: p, policy: policy, random: rand.Float64}
+}
+
+// Lookup looks name up in the wrapped provider, retrying transient failures.
+// Errors are reported as the variable being unset; use LookupContext to
+// observe them.
+func (r *RetryProvider) Lookup(name string) (string, bool) {
+	value, found, _ := r.LookupContext(context.Background(), name)
+	return value, found
+}
+
+// LookupContext looks name up in the wrapped provider, retrying transient
+// failures until the attempts are exhausted or ctx is done
+func (r *RetryProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	attempts := r.policy.Attempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+	cp, ok := r.base.(ContextProvider)
+	if !ok {
+		value, found := r.base.Lookup(name)
+		return value, found, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		value, found, err := cp.LookupContext(ctx, name)
+		var notFound *NotFoundError
+		var unavailable *UnavailableError
+		switch {
+		case err == nil:
+			return value, found, nil
+		case errors.As(err, &notFound):
+			return "", false, nil
+		case !errors.As(err, &unavailable) || attempt == attempts:
+			return "", false, err
+		}
+
+		timer := time.NewTimer(r.policy.delay(attempt, r.random()))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return "", false, fmt.Errorf("%w (last error: %w)", ctx.Err(), err)
+		}
+	}
+}
+
+// Set assigns name in the wrapped provider, if it is a Setter
+func (r *RetryProvider) Set(name, value string) error {
+	if setter, ok := r.base.(Setter); ok {
+		return setter.Set(name, value)
+	}
+	return nil
+}
+
+// Ping checks the wrapped provider, see HealthChecker
+func (r *RetryProvider) Ping(ctx context.Context) error {
+	return ping(ctx, r.base)
+}
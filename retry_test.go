@@ -0,0 +1,109 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// flakyProvider fails with err the first failures lookups
+type flakyProvider struct {
+	Map
+	failures int
+	err      error
+	calls    int
+}
+
+func (f *flakyProvider) LookupContext(_ context.Context, name string) (string, bool, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return "", false, f.err
+	}
+	value, found := f.Map[name]
+	return value, found, nil
+}
+
+func TestRetry(t *testing.T) {
+	unavailable := &UnavailableError{Err: errors.New("connection refused")}
+	policy := Backoff{Attempts: 3, Initial: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		failures  int
+		err       error
+		wantValue string
+		wantFound bool
+		wantErr   bool
+		wantCalls int
+	}{
+		{"success", 0, nil, "1", true, false, 1},
+		{"recovers", 2, unavailable, "1", true, false, 3},
+		{"exhausted", 3, unavailable, "", false, true, 3},
+		{"not found", 3, &NotFoundError{Name: "A"}, "", false, false, 1},
+		{"permanent", 3, errors.New("forbidden"), "", false, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &flakyProvider{Map: Map{"A": "1"}, failures: tt.failures, err: tt.err}
+			value, found, err := Retry(f, policy).LookupContext(context.Background(), "A")
+			if value != tt.wantValue || found != tt.wantFound || (err != nil) != tt.wantErr {
+				t.Errorf("LookupContext() got = %q, %v, %v", value, found, err)
+			}
+			if f.calls != tt.wantCalls {
+				t.Errorf("LookupContext() made %d calls, want %d", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	f := &flakyProvider{failures: 10, err: &UnavailableError{Err: errors.New("timeout")}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err := Retry(f, Backoff{Attempts: 10, Initial: time.Second}).LookupContext(ctx, "A")
+	if !errors.Is(err, context.DeadlineExceeded) || f.calls != 1 {
+		t.Errorf("LookupContext() error = %v after %d calls", err, f.calls)
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Initial: 10 * time.Millisecond, Max: 50 * time.Millisecond, Jitter: 0.5}
+	tests := []struct {
+		attempt int
+		random  float64
+		want    time.Duration
+	}{
+		{1, 0, 10 * time.Millisecond},
+		{2, 0, 20 * time.Millisecond},
+		{3, 0, 40 * time.Millisecond},
+		{4, 0, 50 * time.Millisecond},
+		{100, 0, 50 * time.Millisecond},
+		{2, 1, 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := b.delay(tt.attempt, tt.random); got != tt.want {
+			t.Errorf("delay(%d, %v) = %v, want %v", tt.attempt, tt.random, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPProviderUnavailable(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			http.Error(w, "busy", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("A=1\n"))
+	}))
+	defer server.Close()
+
+	r := Retry(NewHTTPProvider(server.URL), Backoff{Initial: time.Millisecond})
+	if value, found, err := r.LookupContext(context.Background(), "A"); err != nil || !found || value != "1" {
+		t.Errorf("LookupContext() got = %q, %v, %v", value, found, err)
+	}
+}
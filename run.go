@@ -0,0 +1,75 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Run expands every element of argv against env and executes the resulting
+// command with the variables of env as its environment. The program is
+// resolved with the PATH of env, see LookPath. The standard streams of the
+// current process are passed to the command.
+// env must implement Lister so that the environment of the command can be built.
+func Run(ctx context.Context, argv []string, env Provider) error {
+	if len(argv) == 0 {
+		return errors.New("no command given")
+	}
+	lister, ok := env.(Lister)
+	if !ok {
+		return fmt.Errorf("provider %T cannot enumerate its variables", env)
+	}
+
+	args := make([]string, len(argv))
+	for i, arg := range argv {
+		expanded, err := Expand(arg, env)
+		if err != nil {
+			return fmt.Errorf("failed to expand argument %d: %w", i, err)
+		}
+		args[i] = expanded
+	}
+
+	program, err := LookPath(args[0], env)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, program, args[1:]...)
+	cmd.Args[0] = args[0]
+	cmd.Env = lister.Environ()
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// LookPath resolves program like exec.LookPath, searching the directories of
+// the PATH variable of p rather than the one of the current process, so that
+// the program found is the one the environment of p points at. The PATH of the
+// current process is used if p does not set it. Relative directories in PATH
+// are skipped, as exec.LookPath does by default.
+func LookPath(program string, p Provider) (string, error) {
+	if strings.ContainsRune(program, '/') || strings.ContainsRune(program, filepath.Separator) {
+		return exec.LookPath(program)
+	}
+	path, ok := p.Lookup("PATH")
+	if !ok {
+		// Windows spells it Path
+		path, ok = p.Lookup("Path")
+	}
+	if !ok {
+		path = os.Getenv("PATH")
+	}
+	for _, dir := range filepath.SplitList(path) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		if found, err := exec.LookPath(filepath.Join(dir, program)); err == nil {
+			return found, nil
+		}
+	}
+	return "", &exec.Error{Name: program, Err: exec.ErrNotFound}
+}
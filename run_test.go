@@ -0,0 +1,120 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// TestHelperProcess is executed as the child process by TestRun
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GO_ENV_HELPER_PROCESS") != "1" {
+		return
+	}
+	args := os.Args
+	for len(args) > 0 && args[0] != "--" {
+		args = args[1:]
+	}
+	fmt.Printf("%s %s", os.Getenv("GREETING"), args[1])
+	os.Exit(0)
+}
+
+func TestRun(t *testing.T) {
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+
+	saved := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = saved }()
+
+	vars := Map{
+		"GO_ENV_HELPER_PROCESS": "1",
+		"GREETING":              "hello",
+		"TARGET":                "world",
+	}
+	argv := []string{os.Args[0], "-test.run=TestHelperProcess", "--", "${TARGET}"}
+	if err := Run(context.Background(), argv, vars); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	got, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello world" {
+		t.Errorf("Run() output = %q, want %q", got, "hello world")
+	}
+}
+
+func TestRunLookPath(t *testing.T) {
+	// The helper is only found through the PATH of the provider
+	bin := t.TempDir()
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name := "go-env-run-helper"
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	if err := os.WriteFile(filepath.Join(bin, name), data, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, err := os.CreateTemp(t.TempDir(), "stdout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	saved := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = saved }()
+
+	vars := Map{
+		"PATH":                  bin,
+		"GO_ENV_HELPER_PROCESS": "1",
+		"GREETING":              "hello",
+		"TARGET":                "path",
+	}
+	argv := []string{"go-env-run-helper", "-test.run=TestHelperProcess", "--", "${TARGET}"}
+	if err := Run(context.Background(), argv, vars); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	got, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "hello path" {
+		t.Errorf("Run() output = %q, want %q", got, "hello path")
+	}
+
+	if _, err := LookPath("go-env-run-helper", Map{"PATH": t.TempDir()}); !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("LookPath() error = %v, want the program not found", err)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	if err := Run(context.Background(), nil, Map{}); err == nil {
+		t.Error("Run() expected an error for an empty argv")
+	}
+	if err := Run(context.Background(), []string{"true"}, lookupOnly{}); err == nil {
+		t.Error("Run() expected an error for a provider that cannot list its variables")
+	}
+}
+
+// lookupOnly is a Provider that does not implement Lister
+type lookupOnly struct{}
+
+func (lookupOnly) Lookup(string) (string, bool) { return "", false }
@@ -0,0 +1,238 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hadi77ir/go-env/internal/yaml"
+)
+
+// Schema declares the variables an application expects
+type Schema struct {
+	Variables map[string]VarSpec `json:"variables"`
+}
+
+// VarSpec describes a variable of a Schema
+type VarSpec struct {
+	// Type is the type the value must parse as: string (the default), int,
+	// float, bool, duration or url
+	Type string `json:"type"`
+	// Required variables must be set and non-empty
+	Required bool `json:"required"`
+	// Enum lists the accepted values, if not empty
+	Enum        []string `json:"enum"`
+	Description string   `json:"description"`
+}
+
+// valueParsers check the values of the types a VarSpec can declare
+var valueParsers = map[string]func(string) error{
+	"string": func(string) error { return nil },
+	"int": func(s string) error {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err
+	},
+	"float": func(s string) error {
+		_, err := strconv.ParseFloat(s, 64)
+		return err
+	},
+	"bool": func(s string) error {
+		_, err := strconv.ParseBool(s)
+		return err
+	},
+	"duration": func(s string) error {
+		_, err := time.ParseDuration(s)
+		return err
+	},
+	"url": func(s string) error {
+		u, err := url.Parse(s)
+		if err == nil && (u.Scheme == "" || u.Host == "" && u.Opaque == "" && u.Path == "") {
+			err = errors.New("missing scheme or host")
+		}
+		return err
+	},
+}
+
+// ParseSchema parses a schema written in YAML or JSON:
+//
+//	variables:
+//	  PORT:
+//	    type: int
+//	    required: true
+//	  MODE:
+//	    enum: [dev, prod]
+func ParseSchema(data []byte) (*Schema, error) {
+	s := &Schema{}
+	if err := yaml.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	for name, spec := range s.Variables {
+		if spec.Type == "" {
+			continue
+		}
+		if _, ok := valueParsers[spec.Type]; !ok {
+			return nil, fmt.Errorf("invalid schema: variable '%s' has unknown type %q", name, spec.Type)
+		}
+	}
+	return s, nil
+}
+
+// ReadSchema parses the schema file with the given name
+func ReadSchema(filename string) (*Schema, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return ParseSchema(data)
+}
+
+// CheckResult is the outcome of checking a variable against its VarSpec
+type CheckResult struct {
+	Name string
+	// Found reports whether the variable is set and non-empty
+	Found bool
+	// Err describes why the variable does not match its VarSpec
+	Err error
+}
+
+// Check checks the variables of p against the schema, returning a result for
+// every declared variable sorted by name
+func (s *Schema) Check(p Provider) []CheckResult {
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+	for i, name := range names {
+		value, found := p.Lookup(name)
+		results[i] = CheckResult{Name: name, Found: found && value != ""}
+		results[i].Err = s.Variables[name].check(value, results[i].Found)
+	}
+	return results
+}
+
+// Validate checks the variables of p against the schema, joining the errors
+// of all mismatching variables
+func (s *Schema) Validate(p Provider) error {
+	var errs []error
+	for _, result := range s.Check(p) {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("variable '%s': %w", result.Name, result.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (spec VarSpec) check(value string, found bool) error {
+	if !found {
+		if spec.Required {
+			return errors.New("required but not set")
+		}
+		return nil
+	}
+	if spec.Type != "" {
+		if err := valueParsers[spec.Type](value); err != nil {
+			return fmt.Errorf("not a valid %s", spec.Type)
+		}
+	}
+	if len(spec.Enum) > 0 {
+		for _, allowed := range spec.Enum {
+			if value == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%q is not one of %q", value, spec.Enum)
+	}
+	return nil
+}
+
+// EnvironError is the report of ValidateEnviron and RequireVars, listing
+// every variable of the process environment that does not match the schema
+type EnvironError struct {
+	// Problems holds the failing results, sorted by name
+	Problems []CheckResult
+}
+
+func (e *EnvironError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid environment: %d variable(s) missing or malformed", len(e.Problems))
+	for _, problem := range e.Problems {
+		fmt.Fprintf(&b, "\n  %s: %v", problem.Name, problem.Err)
+	}
+	return b.String()
+}
+
+// Unwrap returns the errors of the failing variables
+func (e *EnvironError) Unwrap() []error {
+	errs := make([]error, len(e.Problems))
+	for i, problem := range e.Problems {
+		errs[i] = problem.Err
+	}
+	return errs
+}
+
+// ValidateEnviron checks the process environment against schema, returning
+// an *EnvironError reporting all the mismatching variables at once. It is
+// meant to be the first call of main, failing before any work is done:
+//
+//	if err := env.ValidateEnviron(schema); err != nil {
+//		log.Fatal(err)
+//	}
+func ValidateEnviron(schema *Schema) error {
+	return validateEnviron(schema, OS)
+}
+
+// RequireVars checks that the given variables are set and non-empty in the
+// process environment, like ValidateEnviron with a schema requiring them
+func RequireVars(names ...string) error {
+	schema := &Schema{Variables: make(map[string]VarSpec, len(names))}
+	for _, name := range names {
+		schema.Variables[name] = VarSpec{Required: true}
+	}
+	return ValidateEnviron(schema)
+}
+
+func validateEnviron(schema *Schema, p Provider) error {
+	var problems []CheckResult
+	for _, result := range schema.Check(p) {
+		if result.Err != nil {
+			problems = append(problems, result)
+		}
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return &EnvironError{Problems: problems}
+}
+
+// Validate checks the variables resolved by x against the schema, like
+// Schema.Validate, so that aliases, registered defaults and the other
+// lookup options of x apply as they do to Decode
+func (x *Expander) Validate(s *Schema) error {
+	var errs []error
+	p := providerFunc(func(name string) (string, bool) {
+		value, found, err := x.Lookup(name)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		return value, found
+	})
+	if err := s.Validate(p); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// providerFunc adapts a lookup function to a Provider
+type providerFunc func(name string) (string, bool)
+
+func (f providerFunc) Lookup(name string) (string, bool) {
+	return f(name)
+}
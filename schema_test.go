@@ -0,0 +1,130 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseSchema(t *testing.T) {
+	if _, err := ParseSchema([]byte("variables:\n  PORT:\n    type: port\n")); err == nil {
+		t.Error("ParseSchema() expected an error for an unknown type")
+	}
+	s, err := ParseSchema([]byte(`{"variables": {"PORT": {"type": "int", "required": true}}}`))
+	if err != nil {
+		t.Fatalf("ParseSchema() of JSON error = %v", err)
+	}
+	if spec := s.Variables["PORT"]; spec.Type != "int" || !spec.Required {
+		t.Errorf("ParseSchema() got = %+v", spec)
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	s, err := ParseSchema([]byte(`
+variables:
+  PORT:
+    type: int
+    required: true
+  TIMEOUT:
+    type: duration
+  MODE:
+    enum: [dev, prod]
+  API_URL:
+    type: url
+    required: true
+  DEBUG:
+    type: bool
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vars := Map{"PORT": "80a", "TIMEOUT": "5s", "MODE": "test", "DEBUG": ""}
+	got := make(map[string]string)
+	for _, result := range s.Check(vars) {
+		got[result.Name] = ""
+		if result.Err != nil {
+			got[result.Name] = result.Err.Error()
+		}
+	}
+	want := map[string]string{
+		"API_URL": "required but not set",
+		"DEBUG":   "",
+		"MODE":    `"test" is not one of ["dev" "prod"]`,
+		"PORT":    "not a valid int",
+		"TIMEOUT": "",
+	}
+	for name, msg := range want {
+		if got[name] != msg {
+			t.Errorf("Check() %s = %q, want %q", name, got[name], msg)
+		}
+	}
+
+	if err := s.Validate(Map{"PORT": "80", "API_URL": "https://api.example.com", "MODE": "dev"}); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := s.Validate(vars); err == nil {
+		t.Error("Validate() expected an error")
+	}
+}
+
+func TestValidateEnviron(t *testing.T) {
+	t.Setenv("VALIDATE_PORT", "80a")
+	t.Setenv("VALIDATE_MODE", "dev")
+	t.Setenv("VALIDATE_EMPTY", "")
+	s := &Schema{Variables: map[string]VarSpec{
+		"VALIDATE_PORT":    {Type: "int"},
+		"VALIDATE_MODE":    {Enum: []string{"dev", "prod"}, Required: true},
+		"VALIDATE_MISSING": {Required: true},
+	}}
+
+	err := ValidateEnviron(s)
+	var environErr *EnvironError
+	if !errors.As(err, &environErr) {
+		t.Fatalf("ValidateEnviron() error = %v, want an *EnvironError", err)
+	}
+	want := "invalid environment: 2 variable(s) missing or malformed\n" +
+		"  VALIDATE_MISSING: required but not set\n" +
+		"  VALIDATE_PORT: not a valid int"
+	if err.Error() != want {
+		t.Errorf("ValidateEnviron() error = %q, want %q", err, want)
+	}
+
+	t.Setenv("VALIDATE_PORT", "80")
+	t.Setenv("VALIDATE_MISSING", "set")
+	if err := ValidateEnviron(s); err != nil {
+		t.Errorf("ValidateEnviron() error = %v", err)
+	}
+
+	tests := []struct {
+		names []string
+		want  string
+	}{
+		{[]string{"VALIDATE_MODE", "VALIDATE_PORT"}, ""},
+		{[]string{"VALIDATE_EMPTY", "VALIDATE_MODE", "VALIDATE_UNSET"}, "invalid environment: 2 variable(s) missing or malformed\n" +
+			"  VALIDATE_EMPTY: required but not set\n" +
+			"  VALIDATE_UNSET: required but not set"},
+	}
+	for _, tt := range tests {
+		err := RequireVars(tt.names...)
+		if got := fmt.Sprint(err); tt.want == "" && err != nil || tt.want != "" && got != tt.want {
+			t.Errorf("RequireVars(%v) error = %v, want %q", tt.names, err, tt.want)
+		}
+	}
+}
+
+func TestExpanderValidate(t *testing.T) {
+	s := &Schema{Variables: map[string]VarSpec{
+		"PORT": {Type: "int", Required: true},
+		"MODE": {Enum: []string{"dev", "prod"}},
+	}}
+	setAlias(t, "OLD_PORT", "PORT")
+	if err := NewExpander(WithProvider(Map{"OLD_PORT": "80", "MODE": "dev"})).Validate(s); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	err := NewExpander(WithProvider(Map{"MODE": "test"})).Validate(s)
+	if err == nil || !strings.Contains(err.Error(), "variable 'PORT': required but not set") || !strings.Contains(err.Error(), "variable 'MODE'") {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
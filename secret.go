@@ -0,0 +1,48 @@
+package env
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// Secret is a string holding a credential. It prints, logs and marshals as
+// "****" so that it does not leak by accident; use Value to read it.
+// Decode treats fields of this type as if they were tagged secret.
+type Secret string
+
+// Value returns the secret itself
+func (s Secret) Value() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("env.Secret(%q)", redacted)
+}
+
+// Format prints the redacted placeholder whatever the verb
+func (s Secret) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, redacted)
+}
+
+// LogValue keeps the secret out of slog records
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+// MarshalText keeps the secret out of encoded output such as JSON
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(redacted), nil
+}
+
+// redact returns value, or a placeholder if name holds a secret or the
+// expander is expanding a secret value
+func (e *expander) redact(name, value string) string {
+	if e.secret {
+		return redacted
+	}
+	return e.cfg.redact(name, value)
+}
@@ -0,0 +1,65 @@
+package env
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSecretRedaction(t *testing.T) {
+	s := Secret("hunter2")
+	if s.Value() != "hunter2" {
+		t.Errorf("Value() got = %q", s.Value())
+	}
+	for _, format := range []string{"%v", "%s", "%q", "%+v", "%#v", "%x"} {
+		if got := fmt.Sprintf(format, s); strings.Contains(got, "hunter2") || strings.Contains(got, "68756e74657232") {
+			t.Errorf("Sprintf(%q) leaked the secret: %q", format, got)
+		}
+	}
+	if got := fmt.Sprintf("%v", struct{ Password Secret }{s}); got != "{****}" {
+		t.Errorf("Sprintf() of a struct got = %q", got)
+	}
+
+	encoded, err := json.Marshal(map[string]Secret{"password": s})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != `{"password":"****"}` {
+		t.Errorf("json.Marshal() got = %s", encoded)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("connecting", "password", s)
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("slog leaked the secret: %s", buf.String())
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	var values []string
+	x := NewExpander(
+		WithProvider(Map{"DB_PASS": "${VAULT}", "VAULT": "hunter2", "PIN": "12a4", "KEY": "k"}),
+		WithTrace(func(s TraceStep) { values = append(values, s.Value) }),
+	)
+
+	var cfg struct {
+		Password Secret `env:"DB_PASS,expand"`
+		Key      string `env:"KEY,secret,expand"`
+		Pin      int    `env:"PIN,secret"`
+	}
+	err := x.Decode(&cfg)
+	if err == nil || strings.Contains(err.Error(), "12a4") {
+		t.Errorf("Decode() error = %v, want an error without the value", err)
+	}
+	if cfg.Password.Value() != "hunter2" || cfg.Key != "k" {
+		t.Errorf("Decode() got = %+v", cfg)
+	}
+	for _, v := range values {
+		if v != redacted {
+			t.Errorf("Decode() traced value %q of a secret", v)
+		}
+	}
+}
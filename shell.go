@@ -0,0 +1,73 @@
+package env
+
+import (
+	"sort"
+	"strings"
+)
+
+// Shell identifies a shell dialect
+type Shell int
+
+const (
+	Bash Shell = iota
+	Zsh
+	Fish
+	PowerShell
+)
+
+// String returns the name of the shell
+func (s Shell) String() string {
+	switch s {
+	case Bash:
+		return "bash"
+	case Zsh:
+		return "zsh"
+	case Fish:
+		return "fish"
+	case PowerShell:
+		return "powershell"
+	}
+	return "unknown"
+}
+
+// ToShellExports returns a script exporting vars in the syntax of shell, one
+// variable per line sorted by name, so that it can be eval'd by users.
+// Values are quoted so that they are taken literally. Names that are not
+// valid shell identifiers are skipped.
+func ToShellExports(vars map[string]string, shell Shell) string {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		if isShellName(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		value := vars[name]
+		switch shell {
+		case Fish:
+			b.WriteString("set -gx " + name + " " + quoteFish(value))
+		case PowerShell:
+			b.WriteString("$env:" + name + " = " + quotePowerShell(value))
+		default:
+			b.WriteString("export " + name + "=" + quotePosix(value))
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// isShellName reports whether name can be assigned by all supported shells
+func isShellName(name string) bool {
+	if name == "" || isDigit(name[0]) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isAlphaNum(name[i]) && name[i] != '_' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package env
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestToShellExports(t *testing.T) {
+	vars := map[string]string{
+		"GREETING": "it's $HOME",
+		"PATH_X":   `C:\bin`,
+		"bad-name": "skipped",
+	}
+
+	tests := []struct {
+		shell Shell
+		want  string
+	}{
+		{Bash, "export GREETING='it'\\''s $HOME'\nexport PATH_X='C:\\bin'\n"},
+		{Zsh, "export GREETING='it'\\''s $HOME'\nexport PATH_X='C:\\bin'\n"},
+		{Fish, "set -gx GREETING 'it\\'s $HOME'\nset -gx PATH_X 'C:\\\\bin'\n"},
+		{PowerShell, "$env:GREETING = 'it''s $HOME'\n$env:PATH_X = 'C:\\bin'\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.shell.String(), func(t *testing.T) {
+			if got := ToShellExports(vars, tt.shell); got != tt.want {
+				t.Errorf("ToShellExports() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToShellExportsEval(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh is not available")
+	}
+	value := "a 'quoted' \"value\" with $VARS, `ticks` and \\ backslashes\nand newlines"
+	script := ToShellExports(map[string]string{"EVAL_TEST": value}, Bash) + `printf '%s' "$EVAL_TEST"`
+
+	out, err := exec.Command(sh, "-c", script).Output()
+	if err != nil {
+		t.Fatalf("sh error = %v", err)
+	}
+	if string(out) != value {
+		t.Errorf("round trip got = %q, want %q", out, value)
+	}
+}
@@ -0,0 +1,311 @@
+package env
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseShellExports reads variables from a shell script made of the subset of
+// the POSIX shell language commonly used to store environments:
+//   - export NAME=value, NAME=value and several assignments on one line
+//   - export NAME, which takes the value from fallback if not assigned before
+//   - unset NAME
+//   - comments, ';' separators and backslash line continuations
+//   - single quotes, double quotes and backslash escapes
+//   - $NAME and ${NAME...} references, resolved against the variables assigned
+//     earlier in the script and then against fallback, which may be nil
+//
+// Anything else, such as command substitution, pipes or other commands, is
+// rejected with an error reporting its line: the script is never executed.
+func ParseShellExports(r io.Reader, fallback Provider) (map[string]string, error) {
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &shellParser{src: string(src), line: 1, vars: make(Map), fallback: fallback}
+	if err := p.parse(); err != nil {
+		return nil, err
+	}
+	return p.vars, nil
+}
+
+// shellParser holds the state of ParseShellExports
+type shellParser struct {
+	src      string
+	pos      int
+	line     int
+	vars     Map
+	fallback Provider
+	// builtins are the commands supported besides assignments, export and unset
+	builtins map[string]func(args []string) error
+}
+
+// shellWord is a word of a command after quote removal and expansion
+type shellWord struct {
+	name   string // name of the variable if the word is an assignment
+	value  string
+	assign bool
+}
+
+func (p *shellParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *shellParser) parse() error {
+	for p.pos < len(p.src) {
+		line := p.line
+		words, err := p.command()
+		if err != nil {
+			return err
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if err := p.run(words); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return nil
+}
+
+// run applies the effect of a single command
+func (p *shellParser) run(words []shellWord) error {
+	switch {
+	case !words[0].assign && words[0].value == "export":
+		for _, w := range words[1:] {
+			if w.assign {
+				p.vars[w.name] = w.value
+				continue
+			}
+			if !isShellName(w.value) {
+				return fmt.Errorf("invalid variable name %q", w.value)
+			}
+			if _, ok := p.vars[w.value]; ok || p.fallback == nil {
+				continue
+			}
+			if value, ok := p.fallback.Lookup(w.value); ok {
+				p.vars[w.value] = value
+			}
+		}
+	case !words[0].assign && words[0].value == "unset":
+		for _, w := range words[1:] {
+			delete(p.vars, w.value)
+		}
+	default:
+		if builtin, ok := p.builtins[words[0].value]; ok && !words[0].assign {
+			args := make([]string, len(words)-1)
+			for i, w := range words[1:] {
+				args[i] = w.value
+			}
+			return builtin(args)
+		}
+		for _, w := range words {
+			if !w.assign {
+				return fmt.Errorf("unsupported command %q", words[0].value)
+			}
+			p.vars[w.name] = w.value
+		}
+	}
+	return nil
+}
+
+// command reads the words up to the end of the next command
+func (p *shellParser) command() ([]shellWord, error) {
+	var words []shellWord
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n':
+			p.pos++
+			p.line++
+			return words, nil
+		case c == ';':
+			p.pos++
+			return words, nil
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == '\\' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '\n':
+			p.pos += 2
+			p.line++
+		default:
+			w, err := p.word()
+			if err != nil {
+				return nil, err
+			}
+			words = append(words, w)
+		}
+	}
+	return words, nil
+}
+
+// word reads a single word, detecting NAME=value assignments
+func (p *shellParser) word() (shellWord, error) {
+	var b strings.Builder
+	var w shellWord
+	quoted := false
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case ' ', '\t', '\r', '\n', ';':
+			w.value = b.String()
+			return w, nil
+		case '\'':
+			end := strings.IndexByte(p.src[p.pos+1:], '\'')
+			if end == -1 {
+				return w, p.errorf("unterminated single quote")
+			}
+			literal := p.src[p.pos+1 : p.pos+1+end]
+			p.line += strings.Count(literal, "\n")
+			b.WriteString(literal)
+			p.pos += end + 2
+			quoted = true
+		case '"':
+			if err := p.doubleQuoted(&b); err != nil {
+				return w, err
+			}
+			quoted = true
+		case '\\':
+			p.pos++
+			if p.pos >= len(p.src) {
+				// a trailing backslash ends the word like the end of input
+				break
+			}
+			if p.src[p.pos] == '\n' {
+				p.line++
+			} else {
+				b.WriteByte(p.src[p.pos])
+			}
+			p.pos++
+		case '$':
+			if err := p.expansion(&b); err != nil {
+				return w, err
+			}
+			quoted = true
+		case '`', '|', '&', '<', '>', '(', ')':
+			return w, p.errorf("unsupported shell syntax %q", c)
+		case '=':
+			if !w.assign && !quoted && isShellName(b.String()) {
+				w.assign = true
+				w.name = b.String()
+				b.Reset()
+			} else {
+				b.WriteByte(c)
+			}
+			p.pos++
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	w.value = b.String()
+	return w, nil
+}
+
+// doubleQuoted reads a double-quoted string starting at the opening quote
+func (p *shellParser) doubleQuoted(b *strings.Builder) error {
+	p.pos++ // skip the opening quote
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return nil
+		case '\\':
+			if p.pos+1 < len(p.src) {
+				switch next := p.src[p.pos+1]; next {
+				case '$', '`', '"', '\\':
+					b.WriteByte(next)
+					p.pos += 2
+					continue
+				case '\n':
+					p.line++
+					p.pos += 2
+					continue
+				}
+			}
+			b.WriteByte(c)
+			p.pos++
+		case '$':
+			if err := p.expansion(b); err != nil {
+				return err
+			}
+		case '`':
+			return p.errorf("unsupported command substitution")
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+	return p.errorf("unterminated double quote")
+}
+
+// expansion expands the parameter reference starting at a '$'
+func (p *shellParser) expansion(b *strings.Builder) error {
+	start := p.pos
+	p.pos++
+	switch {
+	case p.pos >= len(p.src):
+		b.WriteByte('$')
+		return nil
+	case p.src[p.pos] == '(':
+		return p.errorf("unsupported command substitution")
+	case p.src[p.pos] == '{':
+		depth := 0
+		for ; p.pos < len(p.src); p.pos++ {
+			if p.src[p.pos] == '{' {
+				depth++
+			} else if p.src[p.pos] == '}' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if p.pos >= len(p.src) {
+			return p.errorf("unclosed brace in variable expression")
+		}
+		p.pos++
+	case isLetter(p.src[p.pos]) || p.src[p.pos] == '_':
+		for p.pos < len(p.src) && (isAlphaNum(p.src[p.pos]) || p.src[p.pos] == '_') {
+			p.pos++
+		}
+	default:
+		b.WriteByte('$')
+		return nil
+	}
+
+	expanded, err := Expand(p.src[start:p.pos], shellScope{p})
+	if err != nil {
+		return p.errorf("%v", err)
+	}
+	b.WriteString(expanded)
+	return nil
+}
+
+// shellScope resolves references against the variables assigned so far, then
+// the fallback provider. Assignments made by ${NAME:=value} are kept in the script.
+type shellScope struct {
+	p *shellParser
+}
+
+func (s shellScope) Lookup(name string) (string, bool) {
+	if value, ok := s.p.vars[name]; ok {
+		return value, true
+	}
+	if s.p.fallback == nil {
+		return "", false
+	}
+	return s.p.fallback.Lookup(name)
+}
+
+func (s shellScope) Set(name, value string) error {
+	s.p.vars[name] = value
+	return nil
+}
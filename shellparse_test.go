@@ -0,0 +1,77 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShellExports(t *testing.T) {
+	script := `#!/bin/sh
+# legacy environment
+export APP_NAME=demo
+export GREETING='it'\''s "quoted"'; export TARGET="world of $APP_NAME"
+PLAIN=value OTHER=two
+export LONG="first \
+second"
+export PATH="/opt/bin:$PATH"
+export HOME
+export DB_URL=postgres://${DB_HOST:-localhost}:5432
+export TEMP=1
+unset TEMP
+export ESCAPED=a\ b\$c
+`
+	fallback := Map{"PATH": "/usr/bin", "HOME": "/home/user"}
+	got, err := ParseShellExports(strings.NewReader(script), fallback)
+	if err != nil {
+		t.Fatalf("ParseShellExports() error = %v", err)
+	}
+
+	want := map[string]string{
+		"APP_NAME": "demo",
+		"GREETING": `it's "quoted"`,
+		"TARGET":   "world of demo",
+		"PLAIN":    "value",
+		"OTHER":    "two",
+		"LONG":     "first second",
+		"PATH":     "/opt/bin:/usr/bin",
+		"HOME":     "/home/user",
+		"DB_URL":   "postgres://localhost:5432",
+		"ESCAPED":  "a b$c",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseShellExports() got = %v, want %v", got, want)
+	}
+}
+
+func TestParseShellExportsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{name: "command substitution", script: "export A=1\nexport B=$(whoami)", want: "line 2"},
+		{name: "backticks", script: "export B=`whoami`", want: "line 1"},
+		{name: "other command", script: "\n\necho hello", want: `line 3: unsupported command "echo"`},
+		{name: "unterminated quote", script: "export A='oops", want: "unterminated single quote"},
+		{name: "pipe", script: "export A=1 | cat", want: "unsupported shell syntax"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseShellExports(strings.NewReader(tt.script), nil)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ParseShellExports() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseShellExportsTrailingBackslash(t *testing.T) {
+	got, err := ParseShellExports(strings.NewReader(`export FOO=bar\`), nil)
+	if err != nil {
+		t.Fatalf("ParseShellExports() error = %v", err)
+	}
+	if got["FOO"] != "bar" {
+		t.Errorf("FOO got = %q, want %q", got["FOO"], "bar")
+	}
+}
@@ -0,0 +1,165 @@
+package env
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// snapshotIterations is the PBKDF2 iteration count of new snapshots
+var snapshotIterations = 600_000
+
+// maxSnapshotIterations bounds the iteration count read from a snapshot, so
+// that a tampered file cannot make the key derivation run for hours
+const maxSnapshotIterations = 6_000_000
+
+// snapshotFile is the JSON form of a snapshot. Data holds the variables as a
+// JSON object, encrypted with AES-256-GCM under a key derived from the
+// passphrase with PBKDF2-HMAC-SHA256.
+type snapshotFile struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+// WriteSnapshot resolves the variables of p and writes them to w as a JSON
+// document encrypted with passphrase, to be read back with ReadSnapshot where
+// p is not reachable, as in air-gapped deployments.
+// If names are given, only those variables are resolved and the ones that are
+// unset are left out; otherwise p must implement Lister.
+func WriteSnapshot(w io.Writer, p Provider, passphrase string, names ...string) error {
+	vars, err := resolveAll(p, names)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(vars)
+	if err != nil {
+		return err
+	}
+
+	s := snapshotFile{Version: 1, KDF: "pbkdf2-sha256", Iterations: snapshotIterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(s.Salt); err != nil {
+		return err
+	}
+	gcm, err := snapshotCipher(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return err
+	}
+	s.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return err
+	}
+	s.Data = gcm.Seal(nil, s.Nonce, plaintext, nil)
+	return json.NewEncoder(w).Encode(s)
+}
+
+// ReadSnapshot decrypts a snapshot written by WriteSnapshot with passphrase,
+// returning its variables as an offline provider
+func ReadSnapshot(r io.Reader, passphrase string) (Map, error) {
+	var s snapshotFile
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if s.Version != 1 || s.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported snapshot version %d with key derivation %q", s.Version, s.KDF)
+	}
+	gcm, err := snapshotCipher(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(s.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("malformed snapshot nonce")
+	}
+	plaintext, err := gcm.Open(nil, s.Nonce, s.Data, nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt snapshot: wrong passphrase or corrupted data")
+	}
+	vars := make(Map)
+	if err := json.Unmarshal(plaintext, &vars); err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	return vars, nil
+}
+
+// snapshotCipher returns the AEAD sealing snapshots for passphrase
+func snapshotCipher(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	if iterations <= 0 {
+		return nil, errors.New("malformed snapshot iteration count")
+	}
+	if iterations > maxSnapshotIterations {
+		return nil, fmt.Errorf("snapshot iteration count %d exceeds the limit of %d", iterations, maxSnapshotIterations)
+	}
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// refresher is implemented by providers caching a remote document, such as
+// HTTPProvider, to fetch it again
+type refresher interface {
+	Refresh(ctx context.Context) error
+}
+
+// resolveAll looks up names in p, or all of its variables if there are none.
+// Providers caching a remote document fetch it first, so that a snapshot is
+// not taken from stale or missing data.
+func resolveAll(p Provider, names []string) (map[string]string, error) {
+	var err error
+	switch p := p.(type) {
+	case refresher:
+		err = p.Refresh(context.Background())
+	case Prefetcher:
+		err = p.Prefetch(context.Background(), names)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch variables: %w", err)
+	}
+
+	vars := make(map[string]string)
+	if len(names) == 0 {
+		lister, ok := p.(Lister)
+		if !ok {
+			return nil, errors.New("provider cannot enumerate its variables")
+		}
+		for _, kv := range lister.Environ() {
+			if name, value, _ := strings.Cut(kv, "="); name != "" {
+				vars[name] = value
+			}
+		}
+		return vars, nil
+	}
+
+	for _, name := range names {
+		var value string
+		var found bool
+		if cp, ok := p.(ContextProvider); ok {
+			var err error
+			if value, found, err = cp.LookupContext(context.Background(), name); err != nil {
+				return nil, fmt.Errorf("failed to look up variable '%s': %w", name, err)
+			}
+		} else {
+			value, found = p.Lookup(name)
+		}
+		if found {
+			vars[name] = value
+		}
+	}
+	return vars, nil
+}
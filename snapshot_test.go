@@ -0,0 +1,81 @@
+package env
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSnapshot(t *testing.T) {
+	defer func(n int) { snapshotIterations = n }(snapshotIterations)
+	snapshotIterations = 1000
+
+	tests := []struct {
+		name  string
+		p     Provider
+		names []string
+		want  Map
+	}{
+		{"all", Map{"A": "1", "B": "x=y"}, nil, Map{"A": "1", "B": "x=y"}},
+		{"names", Map{"A": "1", "B": "2"}, []string{"B", "C"}, Map{"B": "2"}},
+		{"empty", Map{}, nil, Map{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteSnapshot(&buf, tt.p, "s3cret", tt.names...); err != nil {
+				t.Fatalf("WriteSnapshot() error = %v", err)
+			}
+			if strings.Contains(buf.String(), "x=y") {
+				t.Errorf("WriteSnapshot() wrote plaintext: %s", buf.String())
+			}
+			got, err := ReadSnapshot(bytes.NewReader(buf.Bytes()), "s3cret")
+			if err != nil {
+				t.Fatalf("ReadSnapshot() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadSnapshot() got = %v, want %v", got, tt.want)
+			}
+			if _, err := ReadSnapshot(bytes.NewReader(buf.Bytes()), "wrong"); err == nil {
+				t.Error("ReadSnapshot() expected an error with a wrong passphrase")
+			}
+		})
+	}
+}
+
+func TestSnapshotErrors(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteSnapshot(&buf, struct{ Provider }{Map{}}, "p"); err == nil {
+		t.Error("WriteSnapshot() expected an error for a provider that cannot be listed")
+	}
+	for _, input := range []string{
+		"",
+		"{}",
+		`{"version":1,"kdf":"pbkdf2-sha256","iterations":1,"nonce":"AAAA"}`,
+		`{"version":1,"kdf":"pbkdf2-sha256","iterations":2000000000,"salt":"AAAAAAAAAAAAAAAAAAAAAA==","nonce":"AAAAAAAAAAAAAAAA"}`,
+	} {
+		if _, err := ReadSnapshot(strings.NewReader(input), "p"); err == nil {
+			t.Errorf("ReadSnapshot(%q) expected an error", input)
+		}
+	}
+}
+
+func TestSnapshotUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	server.Close()
+
+	for _, names := range [][]string{nil, {"HOST"}} {
+		var buf bytes.Buffer
+		if err := WriteSnapshot(&buf, NewHTTPProvider(server.URL), "p", names...); err == nil {
+			t.Errorf("WriteSnapshot(%v) expected an error for an unreachable provider", names)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("WriteSnapshot(%v) wrote %d bytes for an unreachable provider", names, buf.Len())
+		}
+	}
+}
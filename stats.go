@@ -0,0 +1,76 @@
+package env
+
+import (
+	"fmt"
+	"maps"
+	"sync"
+)
+
+// Stats accumulates statistics over every expansion made with the WithStats
+// option, for tools rendering many templates to report a summary.
+// It is safe for concurrent use.
+type Stats struct {
+	mu            sync.Mutex
+	files         int
+	substitutions int
+	bytes         int
+	resolved      map[string]int
+}
+
+// StatsSnapshot is a point-in-time copy of Stats
+type StatsSnapshot struct {
+	// Files is the number of files expanded by ExpandFile, ExpandGlob and ExpandTree
+	Files int
+	// Substitutions is the number of references replaced in the outputs
+	Substitutions int
+	// Bytes is the total size of the outputs
+	Bytes int
+	// Resolved holds the number of variables resolved from each provider,
+	// named like TraceStep.Source
+	Resolved map[string]int
+}
+
+// String summarizes the statistics, e.g. "412 substitutions across 37 files"
+func (s StatsSnapshot) String() string {
+	return fmt.Sprintf("%d substitutions across %d files", s.Substitutions, s.Files)
+}
+
+// WithStats adds the statistics of every expansion to s
+func WithStats(s *Stats) Option {
+	return func(c *config) {
+		c.stats = s
+	}
+}
+
+// Snapshot returns a copy of the current statistics
+func (s *Stats) Snapshot() StatsSnapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return StatsSnapshot{
+		Files:         s.files,
+		Substitutions: s.substitutions,
+		Bytes:         s.bytes,
+		Resolved:      maps.Clone(s.resolved),
+	}
+}
+
+// Reset sets every statistic back to zero
+func (s *Stats) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files, s.substitutions, s.bytes, s.resolved = 0, 0, 0, nil
+}
+
+func (s *Stats) add(files, substitutions, bytes int, source string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files += files
+	s.substitutions += substitutions
+	s.bytes += bytes
+	if source != "" {
+		if s.resolved == nil {
+			s.resolved = make(map[string]int)
+		}
+		s.resolved[source]++
+	}
+}
@@ -0,0 +1,42 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWithStats(t *testing.T) {
+	src, dst := t.TempDir(), t.TempDir()
+	os.WriteFile(filepath.Join(src, "a.conf"), []byte("host=$HOST port=${PORT:-80}\n"), 0o600)
+	os.WriteFile(filepath.Join(src, "b.conf"), []byte("${HOST} ${MISSING}\n"), 0o600)
+
+	var stats Stats
+	x := NewExpander(WithProvider(Map{"HOST": "db"}), WithStats(&stats))
+	if err := x.ExpandGlob(filepath.Join(src, "*.conf"), dst); err != nil {
+		t.Fatalf("ExpandGlob() error = %v", err)
+	}
+	if _, err := x.Expand("$HOST"); err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+
+	want := StatsSnapshot{
+		Files:         2,
+		Substitutions: 5,
+		Bytes:         len("host=db port=80\n") + len("db \n") + len("db"),
+		Resolved:      map[string]int{"env.Map": 3},
+	}
+	got := stats.Snapshot()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Snapshot() got = %+v, want %+v", got, want)
+	}
+	if s := got.String(); s != "5 substitutions across 2 files" {
+		t.Errorf("String() got = %q", s)
+	}
+
+	stats.Reset()
+	if got := stats.Snapshot(); !reflect.DeepEqual(got, StatsSnapshot{}) {
+		t.Errorf("Snapshot() got = %+v after Reset", got)
+	}
+}
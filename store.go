@@ -0,0 +1,107 @@
+package env
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Env is an in-memory set of variables that is safe for concurrent use.
+// It implements Provider, Setter and Lister so it can be used for expansion and
+// as the environment of commands run with Run.
+// The zero value is an empty Env ready to use.
+type Env struct {
+	mu         sync.RWMutex
+	vars       map[string]string
+	generation uint64
+}
+
+// NewEnv returns an Env holding a copy of vars
+func NewEnv(vars map[string]string) *Env {
+	e := &Env{vars: make(map[string]string, len(vars))}
+	for name, value := range vars {
+		e.vars[name] = value
+	}
+	return e
+}
+
+// EnvFromEnviron returns an Env holding the variables of environ, which is in
+// the "key=value" form returned by os.Environ
+func EnvFromEnviron(environ []string) *Env {
+	e := &Env{vars: make(map[string]string, len(environ))}
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if name != "" {
+			e.vars[name] = value
+		}
+	}
+	return e
+}
+
+// Lookup returns the value of the variable named by name
+func (e *Env) Lookup(name string) (string, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	value, ok := e.vars[name]
+	return value, ok
+}
+
+// Set sets the variable named by name to value
+func (e *Env) Set(name, value string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.vars == nil {
+		e.vars = make(map[string]string)
+	}
+	e.vars[name] = value
+	e.generation++
+	return nil
+}
+
+// Unset removes the variable named by name
+func (e *Env) Unset(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.vars, name)
+	e.generation++
+}
+
+// Generation returns the number of changes made to e with Set and Unset,
+// see Generational
+func (e *Env) Generation() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.generation
+}
+
+// Clone returns an independent copy of e
+func (e *Env) Clone() *Env {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return NewEnv(e.vars)
+}
+
+// Environ returns the variables in "key=value" form, sorted by key
+func (e *Env) Environ() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return Map(e.vars).Environ()
+}
+
+// Names returns the names of the variables, sorted
+func (e *Env) Names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	names := make([]string, 0, len(e.vars))
+	for name := range e.vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Expand expands variables in input against e.
+// Assignments performed by ${var:=default} are stored in e.
+func (e *Env) Expand(input string) (string, error) {
+	return Expand(input, e)
+}
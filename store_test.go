@@ -0,0 +1,70 @@
+package env
+
+import (
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestEnv(t *testing.T) {
+	var e Env
+	e.Set("NAME", "value")
+	e.Set("OTHER", "other")
+
+	if got, ok := e.Lookup("NAME"); !ok || got != "value" {
+		t.Errorf("Lookup() got = %v, %v, want value, true", got, ok)
+	}
+
+	clone := e.Clone()
+	e.Unset("OTHER")
+	if _, ok := e.Lookup("OTHER"); ok {
+		t.Errorf("Unset() did not remove the variable")
+	}
+	if got, ok := clone.Lookup("OTHER"); !ok || got != "other" {
+		t.Errorf("Clone() is not independent, got = %v, %v", got, ok)
+	}
+
+	if got, want := clone.Environ(), []string{"NAME=value", "OTHER=other"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Environ() got = %v, want %v", got, want)
+	}
+	if got, want := clone.Names(), []string{"NAME", "OTHER"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() got = %v, want %v", got, want)
+	}
+}
+
+func TestEnvExpand(t *testing.T) {
+	e := EnvFromEnviron([]string{"HOST=localhost", "PORT=8080", "=C:=ignored"})
+
+	got, err := e.Expand("http://$HOST:${PORT}/${PATH_PREFIX:=api}")
+	if err != nil {
+		t.Fatalf("Expand() error = %v", err)
+	}
+	if want := "http://localhost:8080/api"; got != want {
+		t.Errorf("Expand() got = %v, want %v", got, want)
+	}
+	if got, _ := e.Lookup("PATH_PREFIX"); got != "api" {
+		t.Errorf("assignment was not stored, got = %v, want api", got)
+	}
+	if len(e.Names()) != 3 {
+		t.Errorf("Names() got = %v, want 3 variables", e.Names())
+	}
+}
+
+func TestEnvConcurrent(t *testing.T) {
+	e := NewEnv(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			name := "VAR_" + strconv.Itoa(i)
+			for j := 0; j < 100; j++ {
+				e.Set(name, strconv.Itoa(j))
+				_, _ = e.Expand("${" + name + "}")
+				_ = e.Environ()
+			}
+		}(i)
+	}
+	wg.Wait()
+}
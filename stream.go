@@ -0,0 +1,141 @@
+package env
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// WithOutput sets the writer Expander.Write and Expander.Flush write the
+// expanded text to
+func WithOutput(w io.Writer) Option {
+	return func(c *config) {
+		c.output = w
+	}
+}
+
+// Write expands p as the next chunk of a stream and writes the result to the
+// writer set by WithOutput. A reference split across chunks, such as "${HO"
+// followed by "ST}", is held back until it is complete, so the input can be
+// fed in chunks of any size. Assignments performed by ${var:=default} are
+// visible to the rest of the stream; with WithTransactionalAssignments they are
+// applied by Flush if the whole stream expanded without error.
+// Call Flush at the end of the stream to expand the text held back.
+func (x *Expander) Write(p []byte) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.cfg.output == nil {
+		return 0, errors.New("no output set for the expander")
+	}
+	x.pending = append(x.pending, p...)
+	cut := x.cfg.refSyntax().completePrefix(string(x.pending))
+	if cut == 0 {
+		return len(p), nil
+	}
+	if err := x.writeExpanded(x.pending[:cut]); err != nil {
+		return 0, err
+	}
+	x.pending = append(x.pending[:0], x.pending[cut:]...)
+	return len(p), nil
+}
+
+// Flush expands the text held back by Write and ends the stream. It fails if
+// the stream ends inside an unclosed ${...} expression.
+func (x *Expander) Flush() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.cfg.output == nil {
+		return errors.New("no output set for the expander")
+	}
+	err := x.writeExpanded(x.pending)
+	if err == nil {
+		err = x.stream.commit()
+	}
+	x.pending = x.pending[:0]
+	x.stream = nil
+	return err
+}
+
+// writeExpanded expands chunk with the state of the current stream
+func (x *Expander) writeExpanded(chunk []byte) error {
+	if x.stream == nil {
+		x.stream = &expander{cfg: &x.cfg, pure: x.cfg.pure}
+	}
+	expanded, err := x.stream.expand(string(chunk))
+	if err != nil {
+		x.stream.failed = true
+		return err
+	}
+	_, err = io.WriteString(x.cfg.output, expanded)
+	return err
+}
+
+// completePrefix returns the length of the longest prefix of p that does not
+// end inside a reference more input could still change
+func (s syntax) completePrefix(p string) int {
+	i := 0
+	for i < len(p) {
+		next := s.next(p[i:])
+		if next == -1 {
+			break
+		}
+		i += next
+
+		start := i
+		if s.percent && p[i] == '%' {
+			if end := matchPercent(p, i); end != -1 {
+				i = end + 1
+			} else if strings.IndexByte(p[i+1:], '%') == -1 && (i+1 == len(p) || isPercentVarName("_"+p[i+1:])) {
+				// The closing '%' may still come
+				return start
+			} else {
+				i++
+			}
+			continue
+		}
+		if rest := p[i:]; len(rest) < len(s.open) && strings.HasPrefix(s.open, rest) {
+			return i
+		}
+		if s.escaped(p, i) {
+			i += 2
+			continue
+		}
+		if s.escape != 0 && p[i] == s.escape && i+1 == len(p) {
+			// The escaped character may come next
+			return i
+		}
+		if s.quotes && p[i] == '\\' {
+			if i+1 == len(p) {
+				return i
+			}
+			i += 2
+			continue
+		}
+
+		if strings.HasPrefix(p[i:], s.open) {
+			// A reference closed only by ignoring quotes and escapes, or
+			// with a quote still open as in ${X:-'}, may be closed
+			// differently once more input comes
+			end, openQuote := s.matchDelimiters(p, i, true)
+			if end == -1 || openQuote {
+				return start
+			}
+			i = end + len(s.close)
+			continue
+		}
+		i++
+		if s.sigil == 0 || p[start] != s.sigil {
+			continue
+		}
+		end := scanName(p, i, s.unicode)
+		if end == len(p) || s.unicode && !utf8.FullRuneInString(p[end:]) {
+			// The name may continue in the next chunk
+			return start
+		}
+		i = end
+	}
+	return len(p)
+}
@@ -0,0 +1,86 @@
+package env
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpanderWriteChunks(t *testing.T) {
+	vars := Map{"HOST": "db", "PORT": "5432", "LONG_NAME_VAR": "x"}
+	input := "host=${HOST} port=$PORT ${MISSING:-a${b}} $LONG_NAME_VAR$ ${NEW:=set} $NEW $"
+
+	want, err := NewExpander(WithProvider(vars), WithPure()).Expand(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(vars), WithPure(), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			end := min(i+size, len(input))
+			if _, err := x.Write([]byte(input[i:end])); err != nil {
+				t.Fatalf("chunk size %d: Write() error = %v", size, err)
+			}
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestExpanderWriteSplitQuotes(t *testing.T) {
+	vars := Map{"A": "1"}
+	for _, input := range []string{
+		"${Z:-'}'}",
+		`${Z:-"}"} $A`,
+		"${Z:- '}' x} ${A:+'{}'}",
+		`${DIR:-C:\} $A`,
+		`${DIR:-C:\}}`,
+		"${Z:-it's} $A",
+	} {
+		want, err := NewExpander(WithProvider(vars)).Expand(input)
+		if err != nil {
+			t.Fatalf("Expand(%q) error = %v", input, err)
+		}
+		for cut := 0; cut <= len(input); cut++ {
+			var out strings.Builder
+			x := NewExpander(WithProvider(vars), WithOutput(&out))
+			if _, err := x.Write([]byte(input[:cut])); err != nil {
+				t.Fatalf("%q split at %d: Write() error = %v", input, cut, err)
+			}
+			if _, err := x.Write([]byte(input[cut:])); err != nil {
+				t.Fatalf("%q split at %d: Write() error = %v", input, cut, err)
+			}
+			if err := x.Flush(); err != nil {
+				t.Fatalf("%q split at %d: Flush() error = %v", input, cut, err)
+			}
+			if out.String() != want {
+				t.Errorf("%q split at %d: got = %q, want %q", input, cut, out.String(), want)
+			}
+		}
+	}
+}
+
+func TestExpanderFlushUnclosed(t *testing.T) {
+	var out strings.Builder
+	x := NewExpander(WithProvider(Map{"A": "1"}), WithOutput(&out))
+	if _, err := x.Write([]byte("$A ${B")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if out.String() != "1 " {
+		t.Errorf("Write() wrote %q before the unclosed brace, want %q", out.String(), "1 ")
+	}
+	if err := x.Flush(); err == nil {
+		t.Error("Flush() expected an error for an unclosed brace")
+	}
+}
+
+func TestExpanderWriteWithoutOutput(t *testing.T) {
+	if _, err := NewExpander().Write([]byte("x")); err == nil {
+		t.Error("Write() expected an error without an output")
+	}
+}
@@ -0,0 +1,243 @@
+package env
+
+import "strings"
+
+// syntax describes how references are written in the expanded text
+type syntax struct {
+	sigil byte   // starts bare references such as $VAR, 0 if they are disabled
+	open  string // starts braced references
+	nest  string // deepens the nesting inside braced references
+	close string // ends braced references
+	// percent enables %VAR% references and the %% and doubled sigil escapes
+	percent bool
+	// unicode allows non-ASCII letters and digits in names
+	unicode bool
+	// colonOps lists the characters c forming the ${VAR:c...} operators and
+	// bareOps the ones forming the ${VARc...} operators
+	colonOps string
+	bareOps  string
+	// transforms enables the ${VAR@op} operators of bash
+	transforms bool
+	// quotes leaves single-quoted text alone, see WithShellQuotes
+	quotes bool
+	// escape makes the reference following it literal: '\\' escapes the
+	// sigil and itself, any other character escapes itself when doubled
+	escape byte
+}
+
+// defaultSyntax is the shell-like syntax of ExpandEnv
+var defaultSyntax = syntax{sigil: '$', open: "${", nest: "{", close: "}", colonOps: "-+?=", transforms: true}
+
+// WithSigil replaces '$' as the character starting references, so that
+// %VAR and %{VAR:-default} are expanded while $ is left alone
+func WithSigil(sigil byte) Option {
+	return func(c *config) {
+		c.syntax = syntax{sigil: sigil, open: string(sigil) + "{", nest: "{", close: "}"}
+	}
+}
+
+// WithDelimiters makes braced references start with open and end with close,
+// such as {{VAR:-default}} for WithDelimiters("{{", "}}").
+// Bare references like $VAR are not expanded with custom delimiters.
+func WithDelimiters(open, close string) Option {
+	return func(c *config) {
+		c.syntax = syntax{open: open, nest: open, close: close}
+	}
+}
+
+// WithBracedOnly expands only braced references like ${VAR}, leaving $VAR
+// untouched, for text where bare $word tokens are meaningful such as nginx
+// configurations or SQL queries
+func WithBracedOnly() Option {
+	return func(c *config) {
+		c.bracedOnly = true
+	}
+}
+
+// WithDualSyntax expands the %VAR% references of Windows scripts in the same
+// pass as $VAR and ${VAR}, for tooling processing scripts written for either
+// platform. In this mode "%%" stands for a literal '%' and "$$" for a literal
+// '$'. %VAR% is left as is if VAR is unset or its name is not a valid one,
+// which may contain parentheses, dots and hyphens as in %ProgramFiles(x86)%.
+func WithDualSyntax() Option {
+	return func(c *config) {
+		c.dual = true
+	}
+}
+
+// WithStrictSyntax makes malformed references such as ${VAR-WITH-HYPHENS:-x}
+// fail with a *SyntaxError locating them instead of being kept as literals
+func WithStrictSyntax() Option {
+	return func(c *config) {
+		c.strictSyntax = true
+	}
+}
+
+// WithUnicodeNames allows Unicode letters and digits in variable names, as in
+// $ÜBER or ${名前}, as some platforms do. Names are ASCII-only by default.
+func WithUnicodeNames() Option {
+	return func(c *config) {
+		c.unicodeNames = true
+	}
+}
+
+// WithShellQuotes makes expansion respect the quoting of shell command lines:
+// text between single quotes is kept as is, text between double quotes is
+// expanded, and characters escaped with a backslash outside single quotes are
+// not expanded. Quotes and backslashes are kept in the output, which remains
+// a valid command line. Quotes inside references, as in "${A:-it's}", do not
+// start quoted text.
+func WithShellQuotes() Option {
+	return func(c *config) {
+		c.shellQuotes = true
+	}
+}
+
+// refSyntax returns the syntax configured for c
+func (c *config) refSyntax() syntax {
+	s := c.syntax
+	if s.open == "" {
+		s = defaultSyntax
+	}
+	if c.bracedOnly {
+		s.sigil = 0
+	}
+	s.percent = c.dual
+	s.unicode = c.unicodeNames
+	s.quotes = c.shellQuotes
+	m := modes[c.mode]
+	s.colonOps, s.bareOps, s.transforms, s.escape = m.colonOps, m.bareOps, m.transforms, m.escape
+	if c.dual && s.escape == 0 {
+		s.escape = s.sigil
+	}
+	return s
+}
+
+// trigger returns the character starting references
+func (s syntax) trigger() byte {
+	if s.sigil == 0 {
+		return s.open[0]
+	}
+	return s.sigil
+}
+
+// next returns the index of the first byte of input that may start a
+// reference or an escape, or -1
+func (s syntax) next(input string) int {
+	trigger := s.trigger()
+	escape := s.escape != 0 && s.escape != trigger
+	if !s.percent && !escape && !s.quotes {
+		return strings.IndexByte(input, trigger)
+	}
+	chars := string(trigger)
+	if s.percent {
+		chars += "%"
+	}
+	if escape {
+		chars += string(s.escape)
+	}
+	if s.quotes {
+		chars += `'"\`
+	}
+	return strings.IndexAny(input, chars)
+}
+
+// escaped reports whether input[pos] and the byte following it form an
+// escape sequence, which stands for the second byte
+func (s syntax) escaped(input string, pos int) bool {
+	if s.escape == 0 || input[pos] != s.escape || pos+1 >= len(input) {
+		return false
+	}
+	next := input[pos+1]
+	return next == s.escape || s.escape == '\\' && next == s.trigger()
+}
+
+// split splits the content of a ${...} expression into the variable name, the
+// operator and the word following it. The content is scanned once and the
+// leftmost operator wins, so the word may itself contain operators. op is
+// empty if the content has no operator.
+func (s syntax) split(content string) (name, op, word string) {
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c == ':' && i+1 < len(content) && strings.IndexByte(s.colonOps, content[i+1]) != -1 {
+			return content[:i], content[i : i+2], content[i+2:]
+		}
+		if c != ':' && strings.IndexByte(s.bareOps, c) != -1 || c == '@' && s.transforms {
+			return content[:i], content[i : i+1], content[i+1:]
+		}
+	}
+	return content, "", ""
+}
+
+// match returns the index of the delimiter closing the braced reference
+// starting at pos, or -1 if it is not closed. Delimiters escaped with a
+// backslash or enclosed in quotes, as in ${X:-'}'}, are skipped, unless the
+// reference could not be closed that way: this keeps references such as
+// ${DIR:-C:\} working. Only quotes starting the word of an operator or
+// following a space are taken as quotes, so that ${A:-it's} is not affected.
+func (s syntax) match(input string, pos int) int {
+	if end, _ := s.matchDelimiters(input, pos, true); end != -1 {
+		return end
+	}
+	end, _ := s.matchDelimiters(input, pos, false)
+	return end
+}
+
+// matchDelimiters is match, skipping quoted and escaped delimiters if quoted.
+// It also reports whether a quote left unterminated was taken literally.
+func (s syntax) matchDelimiters(input string, pos int, quoted bool) (end int, openQuote bool) {
+	depth := 1
+	start := pos + len(s.open)
+	for i := start; i < len(input); {
+		switch c := input[i]; {
+		case quoted && c == '\\' && i+1 < len(input):
+			i += 2
+		case quoted && (c == '\'' || c == '"') && i > start && strings.IndexByte(" \t:-+?=", input[i-1]) != -1:
+			closing := strings.IndexByte(input[i+1:], c)
+			if closing == -1 {
+				openQuote = true
+				i++
+			} else {
+				i += closing + 2
+			}
+		case strings.HasPrefix(input[i:], s.close):
+			depth--
+			if depth == 0 {
+				return i, openQuote
+			}
+			i += len(s.close)
+		case strings.HasPrefix(input[i:], s.nest):
+			depth++
+			i += len(s.nest)
+		default:
+			i++
+		}
+	}
+	return -1, openQuote
+}
+
+// matchPercent returns the index of the '%' closing the %VAR% reference or %%
+// escape starting at pos, or -1 if the '%' at pos starts neither
+func matchPercent(input string, pos int) int {
+	end := strings.IndexByte(input[pos+1:], '%')
+	if end == -1 || !isPercentVarName(input[pos+1:pos+1+end]) && end != 0 {
+		return -1
+	}
+	return pos + 1 + end
+}
+
+// isPercentVarName reports whether name may be written as %name%: a letter or
+// underscore followed by letters, digits, underscores, parentheses, dots or hyphens
+func isPercentVarName(name string) bool {
+	if name == "" || !isLetter(name[0]) && name[0] != '_' {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		switch c := name[i]; {
+		case isAlphaNum(c), c == '_', c == '(', c == ')', c == '.', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,281 @@
+package env
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCustomSyntax(t *testing.T) {
+	vars := Map{"HOST": "db", "PORT": "5432"}
+	tests := []struct {
+		name    string
+		opts    []Option
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "sigil",
+			opts:  []Option{WithSigil('%')},
+			input: "SELECT $1 FROM t WHERE host = '%HOST:%{PORT}' -- %{USER:-app} 100%",
+			want:  "SELECT $1 FROM t WHERE host = 'db:5432' -- app 100%",
+		},
+		{
+			name:  "sigil keeps invalid names",
+			opts:  []Option{WithSigil('%')},
+			input: "%{not valid} %1",
+			want:  "%{not valid} %1",
+		},
+		{
+			name:  "delimiters",
+			opts:  []Option{WithDelimiters("{{", "}}")},
+			input: "<?php $host = '{{HOST}}'; $port = {{PORT:-80}}; ?> {x}",
+			want:  "<?php $host = 'db'; $port = 5432; ?> {x}",
+		},
+		{
+			name:  "delimiters nest",
+			opts:  []Option{WithDelimiters("{{", "}}")},
+			input: "{{USER:-{{x}}}}",
+			want:  "{{x}}",
+		},
+		{
+			name:  "braced only",
+			opts:  []Option{WithBracedOnly()},
+			input: "location / { proxy_pass http://${HOST}:$PORT$request_uri; } $",
+			want:  "location / { proxy_pass http://db:$PORT$request_uri; } $",
+		},
+		{
+			name:  "braced only with sigil",
+			opts:  []Option{WithBracedOnly(), WithSigil('%')},
+			input: "%HOST %{HOST}",
+			want:  "%HOST db",
+		},
+		{
+			name:  "dual",
+			opts:  []Option{WithDualSyntax()},
+			input: `copy %HOST%\a ${PORT}\b $HOST %ProgramFiles(x86)% 100%% $$HOST %1 50% of %UNSET%`,
+			want:  `copy db\a 5432\b db %ProgramFiles(x86)% 100% $HOST %1 50% of %UNSET%`,
+		},
+		{
+			name:  "dual braced only",
+			opts:  []Option{WithDualSyntax(), WithBracedOnly()},
+			input: "%HOST% $HOST $$ ${PORT}",
+			want:  "db $HOST $$ 5432",
+		},
+		{
+			name:    "unclosed delimiters",
+			opts:    []Option{WithDelimiters("{{", "}}")},
+			input:   "{{HOST}",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewExpander(append(tt.opts, WithProvider(vars))...)
+			got, err := x.Expand(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Expand() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBracedOnlyWriteChunks(t *testing.T) {
+	input := "$host ${HOST} $"
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(Map{"HOST": "db"}), WithBracedOnly(), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			x.Write([]byte(input[i:min(i+size, len(input))]))
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if want := "$host db $"; out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestDualSyntaxWriteChunks(t *testing.T) {
+	input := "%HOST%:${PORT} 100%% $$x 5% %ProgramFiles(x86)% $HOST %"
+	x := NewExpander(WithProvider(Map{"HOST": "db", "PORT": "80"}), WithDualSyntax())
+	want, err := x.Expand(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(Map{"HOST": "db", "PORT": "80"}), WithDualSyntax(), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			x.Write([]byte(input[i:min(i+size, len(input))]))
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestCustomSyntaxWriteChunks(t *testing.T) {
+	input := "a {{HOST}} b {{PORT:-80}} c {"
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(Map{"HOST": "db"}), WithDelimiters("{{", "}}"), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			x.Write([]byte(input[i:min(i+size, len(input))]))
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if want := "a db b 80 c {"; out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestStrictSyntax(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		wantOffset int
+	}{
+		{"invalid name", "a ${VAR-WITH-HYPHENS:-x}", 2},
+		{"leading digit", "${1X}", 0},
+		{"unclosed brace", "abc ${X", 4},
+	}
+	x := NewExpander(WithProvider(Map{}), WithStrictSyntax())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := x.Expand(tt.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expand() error = %v, want a *SyntaxError", err)
+			}
+			if syntaxErr.Offset != tt.wantOffset {
+				t.Errorf("Expand() error offset = %d, want %d", syntaxErr.Offset, tt.wantOffset)
+			}
+		})
+	}
+
+	if got, err := x.Expand("$1 ${X:-ok}"); err != nil || got != "$1 ok" {
+		t.Errorf("Expand() got = %q, %v, want valid references to expand", got, err)
+	}
+}
+
+func TestUnicodeNames(t *testing.T) {
+	vars := Map{"ÜBER": "over", "名前": "name", "CAFÉ1": "x"}
+	input := "$ÜBER/${名前}/${CAFÉ1:-no}/$1ÉTÉ/${ÉTÉ:-summer}"
+
+	got, err := NewExpander(WithProvider(vars), WithUnicodeNames()).Expand(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "over/name/x/$1ÉTÉ/summer"; got != want {
+		t.Errorf("Expand() with Unicode names got = %q, want %q", got, want)
+	}
+
+	got, err = NewExpander(WithProvider(vars)).Expand(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "$ÜBER/${名前}/${CAFÉ1:-no}/$1ÉTÉ/${ÉTÉ:-summer}"; got != want {
+		t.Errorf("Expand() with ASCII names got = %q, want %q", got, want)
+	}
+
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(vars), WithUnicodeNames(), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			x.Write([]byte(input[i:min(i+size, len(input))]))
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if want := "over/name/x/$1ÉTÉ/summer"; out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestShellQuotes(t *testing.T) {
+	vars := Map{"HOME": "/home/app", "NAME": "it's"}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"unquoted", `cd $HOME`, `cd /home/app`},
+		{"single", `echo '$HOME' $HOME`, `echo '$HOME' /home/app`},
+		{"double", `echo "$HOME/'x'"`, `echo "/home/app/'x'"`},
+		{"single in double", `echo "'$HOME'"`, `echo "'/home/app'"`},
+		{"double in single", `echo '"$HOME"'`, `echo '"$HOME"'`},
+		{"escaped", `echo \$HOME "\$HOME" "\\$HOME"`, `echo \$HOME "\$HOME" "\\/home/app"`},
+		{"escaped quote", `echo \'$HOME\'`, `echo \'/home/app\'`},
+		{"quote in reference", `echo "${MISSING:-it's}" $NAME`, `echo "it's" it's`},
+		{"unterminated", `echo '$HOME`, `echo '$HOME`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input, vars, WithShellQuotes())
+			if err != nil || got != tt.want {
+				t.Errorf("Expand() got = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestShellQuotesWriteChunks(t *testing.T) {
+	vars := Map{"HOME": "/home/app"}
+	input := `a '$HOME' "$HOME" \$HOME \\$HOME 'x`
+	want, err := Expand(input, vars, WithShellQuotes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for size := 1; size <= len(input); size++ {
+		var out strings.Builder
+		x := NewExpander(WithProvider(vars), WithShellQuotes(), WithOutput(&out))
+		for i := 0; i < len(input); i += size {
+			x.Write([]byte(input[i:min(i+size, len(input))]))
+		}
+		if err := x.Flush(); err != nil {
+			t.Fatalf("chunk size %d: Flush() error = %v", size, err)
+		}
+		if out.String() != want {
+			t.Errorf("chunk size %d: got = %q, want %q", size, out.String(), want)
+		}
+	}
+}
+
+func TestQuotedBraces(t *testing.T) {
+	vars := Map{"SET": "value"}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"single quoted closer", `${X:-'}'}!`, `'}'!`},
+		{"single quoted braces", `${X:-'{literal}'}`, `'{literal}'`},
+		{"double quoted opener", `${X:-"{"} ${SET}`, `"{" value`},
+		{"quote after space", `${X:-a '}' b}`, `a '}' b`},
+		{"escaped closer", `${X:-a\}b}`, `a\}b`},
+		{"apostrophe", `${X:-it's} '${SET}'`, `it's 'value'`},
+		{"trailing backslash", `${X:-C:\}`, `C:\`},
+		{"unmatched quote", `${X:-'} x`, `' x`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expand(tt.input, vars)
+			if err != nil || got != tt.want {
+				t.Errorf("Expand() got = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package env
+
+import (
+	"fmt"
+	"text/template"
+)
+
+// FuncMap returns template functions resolving variables from the process
+// environment, see (*Expander).FuncMap
+func FuncMap() template.FuncMap {
+	return NewExpander().FuncMap()
+}
+
+// FuncMap returns functions for text/template (or html/template, after a
+// conversion) that resolve variables with the configuration of x:
+//   - env NAME: the value of NAME, empty if unset
+//   - envOr NAME FALLBACK: the value of NAME, or FALLBACK if unset or empty
+//   - requiredEnv NAME: the value of NAME, failing the execution if unset or empty
+//   - expand STRING: STRING with its variables expanded, operators included
+func (x *Expander) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"env": func(name string) (string, error) {
+			value, _, err := x.Lookup(name)
+			return value, err
+		},
+		"envOr": func(name, fallback string) (string, error) {
+			value, found, err := x.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			if !found || value == "" {
+				return fallback, nil
+			}
+			return value, nil
+		},
+		"requiredEnv": func(name string) (string, error) {
+			value, found, err := x.Lookup(name)
+			if err != nil {
+				return "", err
+			}
+			if !found || value == "" {
+				return "", fmt.Errorf("variable '%s' is unset or empty", name)
+			}
+			return value, nil
+		},
+		"expand": x.Expand,
+	}
+}
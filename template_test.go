@@ -0,0 +1,39 @@
+package env
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFuncMap(t *testing.T) {
+	x := NewExpander(WithProvider(Map{"HOST": "db.internal", "EMPTY": ""}))
+
+	tests := []struct {
+		name    string
+		tmpl    string
+		want    string
+		wantErr bool
+	}{
+		{name: "env", tmpl: `{{env "HOST"}}|{{env "MISSING"}}`, want: "db.internal|"},
+		{name: "envOr", tmpl: `{{envOr "EMPTY" "fallback"}}|{{envOr "HOST" "x"}}`, want: "fallback|db.internal"},
+		{name: "requiredEnv", tmpl: `{{requiredEnv "HOST"}}`, want: "db.internal"},
+		{name: "requiredEnv missing", tmpl: `{{requiredEnv "EMPTY"}}`, wantErr: true},
+		{name: "expand", tmpl: `{{expand "postgres://${HOST}:${PORT:-5432}"}}`, want: "postgres://db.internal:5432"},
+		{name: "pipeline", tmpl: `{{"HOST" | env | printf "%q"}}`, want: `"db.internal"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl := template.Must(template.New(tt.name).Funcs(x.FuncMap()).Parse(tt.tmpl))
+			var out strings.Builder
+			err := tmpl.Execute(&out, nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && out.String() != tt.want {
+				t.Errorf("Execute() got = %v, want %v", out.String(), tt.want)
+			}
+		})
+	}
+}
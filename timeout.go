@@ -0,0 +1,61 @@
+package env
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// WithTimeout bounds every expansion to d. Lookups made through a
+// ContextProvider are canceled when the time is up, and the expansion fails
+// with a *TimeoutError naming the variable being resolved. Lookups that
+// cannot be canceled are allowed to finish, but no further lookups are made.
+// Streams fed through Expander.Write are bounded as a whole.
+func WithTimeout(d time.Duration) Option {
+	return func(c *config) {
+		c.timeout = d
+	}
+}
+
+// TimeoutError reports an expansion that did not complete within the
+// duration set by WithTimeout
+type TimeoutError struct {
+	// Name is the variable being resolved when the time ran out
+	Name    string
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("expansion timed out after %s while resolving variable '%s'", e.Timeout, e.Name)
+}
+
+// Unwrap returns context.DeadlineExceeded, so the error matches it with errors.Is
+func (e *TimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// startDeadline starts the clock of WithTimeout if it is not running yet
+func (e *expander) startDeadline() {
+	if e.cfg.timeout > 0 && e.deadline.IsZero() {
+		e.deadline = time.Now().Add(e.cfg.timeout)
+	}
+}
+
+// ctx returns the context lookups are performed under, which ends at the
+// deadline of the expansion if WithTimeout is set
+func (e *expander) ctx() (context.Context, context.CancelFunc) {
+	e.startDeadline()
+	if e.deadline.IsZero() {
+		return context.Background(), func() {}
+	}
+	return context.WithDeadline(context.Background(), e.deadline)
+}
+
+// timedOut reports whether the deadline of the expansion has passed
+func (e *expander) timedOut() bool {
+	return !e.deadline.IsZero() && !time.Now().Before(e.deadline)
+}
+
+func (e *expander) timeoutError(name string) error {
+	return &TimeoutError{Name: name, Timeout: e.cfg.timeout}
+}
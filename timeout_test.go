@@ -0,0 +1,65 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingProvider blocks lookups of Slow until their context ends
+type blockingProvider struct {
+	Map
+}
+
+func (b blockingProvider) LookupContext(ctx context.Context, name string) (string, bool, error) {
+	if name != "SLOW" {
+		value, found := b.Map[name]
+		return value, found, nil
+	}
+	<-ctx.Done()
+	return "", false, ctx.Err()
+}
+
+// sleepyProvider takes a while for every lookup and cannot be canceled
+type sleepyProvider struct {
+	delay time.Duration
+}
+
+func (s sleepyProvider) Lookup(name string) (string, bool) {
+	time.Sleep(s.delay)
+	return name, true
+}
+
+func TestWithTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		p        Provider
+		input    string
+		wantName string
+	}{
+		{"canceled", blockingProvider{Map{"FAST": "1"}}, "$FAST ${SLOW}", "SLOW"},
+		{"between lookups", sleepyProvider{20 * time.Millisecond}, "$A $B $C $D $E", "C"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			x := NewExpander(WithProvider(tt.p), WithTimeout(30*time.Millisecond))
+			_, err := x.Expand(tt.input)
+			var timeout *TimeoutError
+			if !errors.As(err, &timeout) || timeout.Name != tt.wantName {
+				t.Fatalf("Expand() error = %v, want a timeout while resolving %s", err, tt.wantName)
+			}
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Expand() error = %v does not match context.DeadlineExceeded", err)
+			}
+		})
+	}
+
+	// Every expansion gets the full duration
+	x := NewExpander(WithProvider(sleepyProvider{10 * time.Millisecond}), WithTimeout(30*time.Millisecond))
+	for i := 0; i < 3; i++ {
+		if _, err := x.Expand("$A"); err != nil {
+			t.Errorf("Expand() error = %v", err)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package env
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// TraceStep records how a reference was resolved
+type TraceStep struct {
+	// Name is the name of the variable
+	Name string
+	// Op is the operator of the reference, "%" for %VAR% references or empty
+	Op string
+	// Source names the provider the variable was looked up in, or is
+	// "assignment" for variables assigned earlier by ${var:=default}
+	Source string
+	// Found reports whether the variable was set
+	Found bool
+	// Branch is the outcome of the reference: value, unset, default,
+	// alternative, empty, error, assign or literal
+	Branch string
+	// Value is the text the reference was replaced with, redacted if the
+	// variable holds a secret
+	Value string
+}
+
+func (s TraceStep) String() string {
+	ref := s.Name
+	if s.Op != "" && s.Op != "%" {
+		ref += " " + s.Op
+	}
+	state := "unset"
+	if s.Found {
+		state = "set"
+	}
+	return fmt.Sprintf("%s %s in %s -> %s %s", ref, state, s.Source, s.Branch, strconv.Quote(s.Value))
+}
+
+// WithTrace calls fn with a TraceStep for every reference resolved
+func WithTrace(fn func(TraceStep)) Option {
+	return func(c *config) {
+		c.trace = append(c.trace, fn)
+	}
+}
+
+// Trace expands the input string without writing to the provider, like
+// ExpandPure, and returns the steps taken to resolve its references. It is
+// meant to debug why a template renders the way it does.
+func (x *Expander) Trace(input string) (string, []TraceStep, error) {
+	var steps []TraceStep
+	cfg := x.cfg
+	cfg.trace = append(cfg.trace[:len(cfg.trace):len(cfg.trace)], func(s TraceStep) {
+		steps = append(steps, s)
+	})
+	e := &expander{cfg: &cfg, pure: true}
+	output, err := e.expand(input)
+	return output, steps, err
+}
+
+// traceValue traces a reference without operator
+func (e *expander) traceValue(name string, found bool, value string) {
+	branch := "value"
+	if !found {
+		branch = "unset"
+	}
+	e.traceStep(name, "", found, branch, value)
+}
+
+// source names where the variable name is resolved from
+func (e *expander) source(name string) string {
+	if _, ok := e.assigned[name]; ok {
+		return "assignment"
+	}
+	return providerName(e.cfg.provider)
+}
+
+// traceStep reports a resolved reference to the trace callbacks
+func (e *expander) traceStep(name, op string, found bool, branch, value string) {
+	if len(e.cfg.trace) == 0 {
+		return
+	}
+	step := TraceStep{
+		Name:   name,
+		Op:     op,
+		Source: e.source(name),
+		Found:  found,
+		Branch: branch,
+		Value:  e.redact(name, value),
+	}
+	for _, fn := range e.cfg.trace {
+		fn(step)
+	}
+}
@@ -0,0 +1,50 @@
+package env
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTrace(t *testing.T) {
+	vars := Map{"HOST": "db", "DB_PASSWORD": "hunter2"}
+	x := NewExpander(WithProvider(vars))
+
+	output, steps, err := x.Trace("$HOST ${PORT:-5432} ${DB_PASSWORD} ${MODE:=dev} $MODE $USER")
+	if err != nil {
+		t.Fatalf("Trace() error = %v", err)
+	}
+	if want := "db 5432 hunter2 dev dev "; output != want {
+		t.Errorf("Trace() output = %q, want %q", output, want)
+	}
+	if _, ok := vars["MODE"]; ok {
+		t.Error("Trace() assigned MODE in the provider")
+	}
+
+	want := []TraceStep{
+		{Name: "HOST", Source: "env.Map", Found: true, Branch: "value", Value: "db"},
+		{Name: "PORT", Op: ":-", Source: "env.Map", Branch: "default", Value: "5432"},
+		{Name: "DB_PASSWORD", Source: "env.Map", Found: true, Branch: "value", Value: "****"},
+		{Name: "MODE", Op: ":=", Source: "env.Map", Branch: "assign", Value: "dev"},
+		{Name: "MODE", Source: "assignment", Found: true, Branch: "value", Value: "dev"},
+		{Name: "USER", Source: "env.Map", Branch: "unset"},
+	}
+	if !reflect.DeepEqual(steps, want) {
+		t.Errorf("Trace() steps = %+v, want %+v", steps, want)
+	}
+	if got, want := steps[1].String(), `PORT :- unset in env.Map -> default "5432"`; got != want {
+		t.Errorf("String() got = %q, want %q", got, want)
+	}
+}
+
+func TestWithTrace(t *testing.T) {
+	var branches []string
+	x := NewExpander(WithProvider(Map{"A": "1"}), WithTrace(func(s TraceStep) {
+		branches = append(branches, s.Branch)
+	}))
+	if _, err := x.Expand("${A:+alt}${B:+alt}${B:?missing}"); err == nil {
+		t.Fatal("Expand() expected an error")
+	}
+	if want := []string{"alternative", "empty", "error"}; !reflect.DeepEqual(branches, want) {
+		t.Errorf("traced branches = %q, want %q", branches, want)
+	}
+}
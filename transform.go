@@ -0,0 +1,37 @@
+package env
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// isTransform reports whether op names a ${var@op} transformation:
+//   - Q: the value quoted for POSIX shells
+//   - U, u and L: the value in upper case, with its first character in upper
+//     case, and in lower case
+//   - A: an assignment statement setting the variable to its value
+func isTransform(op string) bool {
+	return len(op) == 1 && strings.IndexByte("QUuLA", op[0]) != -1
+}
+
+// transform applies the transformation op to the value of the variable name
+func transform(name, value string, op byte) string {
+	switch op {
+	case 'Q':
+		return quotePosix(value)
+	case 'U':
+		return strings.ToUpper(value)
+	case 'u':
+		if value == "" {
+			return ""
+		}
+		r, size := utf8.DecodeRuneInString(value)
+		return string(unicode.ToUpper(r)) + value[size:]
+	case 'L':
+		return strings.ToLower(value)
+	case 'A':
+		return name + "=" + quotePosix(value)
+	}
+	return value
+}
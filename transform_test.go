@@ -0,0 +1,38 @@
+package env
+
+import "testing"
+
+func TestTransforms(t *testing.T) {
+	vars := Map{"NAME": "élan vital", "QUOTE": "it's", "EMPTY": ""}
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"${NAME@U}", "ÉLAN VITAL", false},
+		{"${NAME@u}", "Élan vital", false},
+		{"${NAME@L}", "élan vital", false},
+		{"${QUOTE@Q}", `'it'\''s'`, false},
+		{"${EMPTY@Q}", "''", false},
+		{"${EMPTY@u}", "", false},
+		{"${QUOTE@A}", `QUOTE='it'\''s'`, false},
+		{"${UNSET@Q}", "", false},
+		{"${NAME@X}", "${NAME@X}", false},
+		{"${NAME@}", "${NAME@}", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := Expand(tt.input, vars)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("Expand() got = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+
+	if _, err := Expand("${NAME@X}", vars, WithMode(ModeBash)); err == nil {
+		t.Error("Expand() expected an error for an unknown transformation in ModeBash")
+	}
+	if got, err := Expand("${NAME@U}", vars, WithMode(ModeEnvsubst)); err != nil || got != "${NAME@U}" {
+		t.Errorf("Expand() got = %q, %v, want the reference kept in ModeEnvsubst", got, err)
+	}
+}
@@ -0,0 +1,126 @@
+package env
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// WithInclude restricts ExpandTree to expanding files matching one of
+// patterns. Patterns use the filepath.Match syntax and are matched against both
+// the base name and the slash-separated path relative to the source directory.
+// Without it, every file is expanded.
+func WithInclude(patterns ...string) Option {
+	return func(c *config) {
+		c.include = append(c.include, patterns...)
+	}
+}
+
+// WithExclude keeps ExpandTree from expanding files matching one of patterns;
+// they are copied verbatim instead. Patterns are matched like in WithInclude.
+func WithExclude(patterns ...string) Option {
+	return func(c *config) {
+		c.exclude = append(c.exclude, patterns...)
+	}
+}
+
+// WithTrimSuffix removes suffix, e.g. ".tmpl", from the names of the files
+// expanded by ExpandTree
+func WithTrimSuffix(suffix string) Option {
+	return func(c *config) {
+		c.trimSuffix = suffix
+	}
+}
+
+// ExpandTree copies the directory srcDir to dstDir, expanding environment
+// variables in the files selected by the WithInclude and WithExclude options
+// and copying the others verbatim. File and directory permissions are preserved
+// and symbolic links are recreated as-is.
+func ExpandTree(srcDir, dstDir string, opts ...Option) error {
+	x := NewExpander(opts...)
+	return filepath.WalkDir(srcDir, func(src string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(srcDir, src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(dstDir, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(dst, info.Mode().Perm()|0o700)
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(src)
+			if err != nil {
+				return err
+			}
+			return symlinkAtomic(target, dst)
+		case !d.Type().IsRegular():
+			return nil // skip devices, sockets and pipes
+		}
+
+		if !x.cfg.shouldExpand(filepath.ToSlash(rel)) {
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			return writeFileAtomic(dst, data, info.Mode().Perm())
+		}
+		if x.cfg.trimSuffix != "" && strings.HasSuffix(dst, x.cfg.trimSuffix) && len(d.Name()) > len(x.cfg.trimSuffix) {
+			dst = strings.TrimSuffix(dst, x.cfg.trimSuffix)
+		}
+		return x.ExpandFile(src, dst, 0)
+	})
+}
+
+// symlinkAtomic creates a symbolic link to target at name, replacing the file
+// or link already there, such as one created by a previous ExpandTree
+func symlinkAtomic(target, name string) error {
+	// Reserve a unique name next to name, then replace it with the link
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+".tmp*")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	if err := os.Remove(tmp.Name()); err != nil {
+		return err
+	}
+	if err := os.Symlink(target, tmp.Name()); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// shouldExpand reports whether the file at the slash-separated relative path
+// rel is selected by the include and exclude patterns
+func (c *config) shouldExpand(rel string) bool {
+	if matchAny(c.exclude, rel) {
+		return false
+	}
+	return len(c.include) == 0 || matchAny(c.include, rel)
+}
+
+func matchAny(patterns []string, rel string) bool {
+	base := path.Base(rel)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, base); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExpandTree(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "out")
+
+	files := map[string]string{
+		"app.conf.tmpl":          "name=$TREE_NAME",
+		"static/logo.txt":        "literal $TREE_NAME",
+		"nested/db.tmpl":         "db=${TREE_DB:-sqlite}",
+		"nested/skip/raw.tmpl":   "raw=$TREE_NAME",
+		"nested/skip/other.tmpl": "other=$TREE_NAME",
+	}
+	for name, content := range files {
+		p := filepath.Join(src, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := ExpandTree(src, dst,
+		WithProvider(Map{"TREE_NAME": "demo"}),
+		WithInclude("*.tmpl"),
+		WithExclude("nested/skip/raw.tmpl"),
+		WithTrimSuffix(".tmpl"),
+	)
+	if err != nil {
+		t.Fatalf("ExpandTree() error = %v", err)
+	}
+
+	want := map[string]string{
+		"app.conf":             "name=demo",
+		"static/logo.txt":      "literal $TREE_NAME",
+		"nested/db":            "db=sqlite",
+		"nested/skip/raw.tmpl": "raw=$TREE_NAME",
+		"nested/skip/other":    "other=demo",
+	}
+	for name, content := range want {
+		got, err := os.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("missing output file %s: %v", name, err)
+			continue
+		}
+		if string(got) != content {
+			t.Errorf("%s got = %q, want %q", name, got, content)
+		}
+	}
+}
+
+func TestExpandTreeTwice(t *testing.T) {
+	src := t.TempDir()
+	dst := filepath.Join(t.TempDir(), "out")
+	if err := os.WriteFile(filepath.Join(src, "app.conf"), []byte("name=$TREE_NAME"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("app.conf", filepath.Join(src, "current.conf")); err != nil {
+		t.Skipf("cannot create symbolic links: %v", err)
+	}
+
+	for _, name := range []string{"first", "second"} {
+		if err := ExpandTree(src, dst, WithProvider(Map{"TREE_NAME": name})); err != nil {
+			t.Fatalf("ExpandTree() rendering %s error = %v", name, err)
+		}
+		got, err := os.ReadFile(filepath.Join(dst, "current.conf"))
+		if err != nil {
+			t.Fatalf("reading through the link: %v", err)
+		}
+		if want := "name=" + name; string(got) != want {
+			t.Errorf("current.conf got = %q, want %q", got, want)
+		}
+	}
+	entries, err := os.ReadDir(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("output holds %d entries, want no temporary files left", len(entries))
+	}
+}
@@ -0,0 +1,144 @@
+// Package viperenv serves go-env providers to viper as remote configuration,
+// in its own module so that go-env does not depend on viper.
+//
+// A provider registered under an endpoint is read by viper like an etcd or
+// Consul store, as a JSON document nested like by env.NewKoanfProvider:
+//
+//	viperenv.Register("secrets", env.Cached(vault, time.Minute))
+//	viper.AddRemoteProvider(viperenv.Name, "secrets", "/")
+//	viper.SetConfigType("json")
+//	err := viper.ReadRemoteConfig() // DB_HOST is read as db.host
+//
+// Providers of other names, such as "etcd", are passed to the remote
+// configuration installed before, so viper/remote keeps working when
+// imported.
+package viperenv
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/spf13/viper"
+)
+
+// Name is the remote provider name to give viper.AddRemoteProvider
+const Name = "goenv"
+
+// watchInterval is the interval at which watched providers are read again
+var watchInterval = 10 * time.Second
+
+var (
+	mu        sync.RWMutex
+	providers = make(map[string]env.Provider)
+	installed bool
+)
+
+// Register serves p to viper under endpoint, replacing the provider
+// registered before under the same endpoint. The first call installs the
+// remote configuration of this package and adds Name to
+// viper.SupportedRemoteProviders. The path given to viper is not used.
+// Providers must implement env.Lister to be read in full.
+func Register(endpoint string, p env.Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[endpoint] = p
+	if installed {
+		return
+	}
+	installed = true
+	if !slices.Contains(viper.SupportedRemoteProviders, Name) {
+		viper.SupportedRemoteProviders = append(viper.SupportedRemoteProviders, Name)
+	}
+	viper.RemoteConfig = &remoteConfig{next: viper.RemoteConfig}
+}
+
+// remoteConfig implements the remote configuration factory of viper
+type remoteConfig struct {
+	// next serves the providers of other names, if any
+	next interface {
+		Get(rp viper.RemoteProvider) (io.Reader, error)
+		Watch(rp viper.RemoteProvider) (io.Reader, error)
+		WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool)
+	}
+}
+
+// Get returns the variables of the provider registered under the endpoint
+// of rp as a nested JSON document
+func (r *remoteConfig) Get(rp viper.RemoteProvider) (io.Reader, error) {
+	if rp.Provider() != Name && r.next != nil {
+		return r.next.Get(rp)
+	}
+	data, err := read(rp)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(data), nil
+}
+
+// Watch is Get, as providers are read on demand
+func (r *remoteConfig) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	if rp.Provider() != Name && r.next != nil {
+		return r.next.Watch(rp)
+	}
+	return r.Get(rp)
+}
+
+// WatchChannel reads the provider again every watchInterval, sending the
+// document whenever it changes, until a value is sent on or the returned
+// quit channel is closed. Failed reads are skipped, as viper would replace
+// its configuration with an empty one.
+func (r *remoteConfig) WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	if rp.Provider() != Name && r.next != nil {
+		return r.next.WatchChannel(rp)
+	}
+	// viper reads responses until the process exits, so the channel is
+	// never closed
+	responses := make(chan *viper.RemoteResponse)
+	quit := make(chan bool)
+	last, _ := read(rp)
+	go func() {
+		ticker := time.NewTicker(watchInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+			case <-quit:
+				return
+			}
+			data, err := read(rp)
+			if err != nil || bytes.Equal(data, last) {
+				continue
+			}
+			last = data
+			select {
+			case responses <- &viper.RemoteResponse{Value: data}:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return responses, quit
+}
+
+// read returns the document of the provider registered under the endpoint
+// of rp
+func read(rp viper.RemoteProvider) ([]byte, error) {
+	mu.RLock()
+	p, ok := providers[rp.Endpoint()]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no provider registered under endpoint %q", rp.Endpoint())
+	}
+	if pf, ok := p.(env.Prefetcher); ok {
+		if err := pf.Prefetch(context.Background(), nil); err != nil {
+			return nil, err
+		}
+	}
+	return env.NewKoanfProvider(p, nil).ReadBytes()
+}
@@ -0,0 +1,64 @@
+package viperenv
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hadi77ir/go-env"
+	"github.com/spf13/viper"
+)
+
+func TestRemoteConfig(t *testing.T) {
+	vars := env.NewEnv(map[string]string{"DB_HOST": "db.internal", "DB_PORT": "5432"})
+	Register("test", vars)
+
+	v := viper.New()
+	if err := v.AddRemoteProvider(Name, "test", "/"); err != nil {
+		t.Fatalf("AddRemoteProvider() error = %v", err)
+	}
+	v.SetConfigType("json")
+	if err := v.ReadRemoteConfig(); err != nil {
+		t.Fatalf("ReadRemoteConfig() error = %v", err)
+	}
+	if got := v.GetString("db.host"); got != "db.internal" {
+		t.Errorf("GetString(db.host) got = %q", got)
+	}
+	if got := v.GetInt("db.port"); got != 5432 {
+		t.Errorf("GetInt(db.port) got = %d", got)
+	}
+
+	unknown := viper.New()
+	unknown.AddRemoteProvider(Name, "missing", "/")
+	unknown.SetConfigType("json")
+	if err := unknown.ReadRemoteConfig(); err == nil {
+		t.Error("ReadRemoteConfig() expected an error for an unregistered endpoint")
+	}
+}
+
+func TestWatchChannel(t *testing.T) {
+	defer func(d time.Duration) { watchInterval = d }(watchInterval)
+	watchInterval = time.Millisecond
+	vars := env.NewEnv(map[string]string{"LOG_LEVEL": "info"})
+	Register("watched", vars)
+
+	responses, quit := viper.RemoteConfig.WatchChannel(remoteProvider("watched"))
+	defer close(quit)
+
+	vars.Set("LOG_LEVEL", "debug")
+	select {
+	case resp := <-responses:
+		if want := `{"log":{"level":"debug"}}`; string(resp.Value) != want {
+			t.Errorf("WatchChannel() sent %s, want %s", resp.Value, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("WatchChannel() did not report the change")
+	}
+}
+
+// remoteProvider is a viper.RemoteProvider for the endpoint it names
+type remoteProvider string
+
+func (remoteProvider) Provider() string      { return Name }
+func (r remoteProvider) Endpoint() string    { return string(r) }
+func (remoteProvider) Path() string          { return "/" }
+func (remoteProvider) SecretKeyring() string { return "" }
@@ -0,0 +1,105 @@
+package env
+
+import "io"
+
+// NewExpandingWriter returns a writer expanding the variables of whatever is
+// written through it, as configured by opts, and writing the result to w.
+// References split across writes are expanded once complete. Close expands
+// the remaining content; it does not close w.
+func NewExpandingWriter(w io.Writer, opts ...Option) io.WriteCloser {
+	return &expandingWriter{x: NewExpander(append(opts, WithOutput(w))...)}
+}
+
+type expandingWriter struct {
+	x *Expander
+}
+
+func (w *expandingWriter) Write(p []byte) (int, error) {
+	return w.x.Write(p)
+}
+
+func (w *expandingWriter) Close() error {
+	return w.x.Flush()
+}
+
+// ExpandTo expands variables in the input string like ExpandEnv, writing the
+// output to w as it is produced instead of returning it, so that large outputs
+// are not held in memory. It returns the number of bytes written. If the
+// expansion fails, part of the output may already have been written.
+func ExpandTo(w io.Writer, input string, opts ...Option) (int, error) {
+	cfg := newConfig(opts...)
+	e := &expander{cfg: &cfg, pure: cfg.pure}
+	return e.expandTo(w, input)
+}
+
+// ExpandTo expands variables in the input string like Expand, writing the
+// output to w, see ExpandTo
+func (x *Expander) ExpandTo(w io.Writer, input string) (int, error) {
+	e := &expander{cfg: &x.cfg, pure: x.cfg.pure}
+	return e.expandTo(w, input)
+}
+
+func (e *expander) expandTo(w io.Writer, input string) (int, error) {
+	e.startDeadline()
+	if err := e.prefetch(input); err != nil {
+		return 0, err
+	}
+	out := &chunkWriter{w: w, buf: getBuffer(maxChunk)}
+	defer putBuffer(out.buf)
+	if e.quote == 0 && e.cfg.literal(input) {
+		out.WriteString(input)
+	} else if err := e.write(out, input); err != nil {
+		return out.n, err
+	}
+	if err := out.flush(); err != nil {
+		return out.n, err
+	}
+	return out.n, e.commit()
+}
+
+// maxChunk is the size above which the output of ExpandTo is written out
+const maxChunk = 32 << 10
+
+// chunkWriter collects the output of an expansion, writing it to w in chunks
+// of up to maxChunk bytes. Strings longer than that are written directly.
+type chunkWriter struct {
+	w   io.Writer
+	buf *buffer
+	// n is the number of bytes written to w
+	n   int
+	err error
+}
+
+func (c *chunkWriter) WriteString(s string) {
+	if c.err != nil {
+		return
+	}
+	if c.buf.Len()+len(s) > maxChunk {
+		if c.flush() != nil {
+			return
+		}
+	}
+	if len(s) < maxChunk {
+		c.buf.WriteString(s)
+		return
+	}
+	n, err := io.WriteString(c.w, s)
+	c.n += n
+	c.err = err
+}
+
+// Len returns the number of bytes produced so far
+func (c *chunkWriter) Len() int {
+	return c.n + c.buf.Len()
+}
+
+// flush writes the collected output to w
+func (c *chunkWriter) flush() error {
+	if c.err == nil && c.buf.Len() > 0 {
+		var n int
+		n, c.err = c.w.Write(*c.buf)
+		c.n += n
+		*c.buf = (*c.buf)[:0]
+	}
+	return c.err
+}
@@ -0,0 +1,81 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExpandingWriter(t *testing.T) {
+	var out strings.Builder
+	w := NewExpandingWriter(&out, WithProvider(Map{"NAME": "api", "PORT": "8080"}))
+	fmt.Fprint(w, "service: ${NA")
+	fmt.Fprint(w, "ME}\nport: $PO")
+	fmt.Fprint(w, "RT")
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if want := "service: api\nport: 8080"; out.String() != want {
+		t.Errorf("got = %q, want %q", out.String(), want)
+	}
+}
+
+func TestExpandingWriterError(t *testing.T) {
+	var out strings.Builder
+	w := NewExpandingWriter(&out, WithProvider(Map{}))
+	if _, err := fmt.Fprint(w, "${A:?required} "); err == nil {
+		t.Error("Write() expected an error")
+	}
+}
+
+// failingWriter accepts limit bytes, then fails
+type failingWriter struct {
+	written int
+	limit   int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.written+len(p) > w.limit {
+		return 0, errors.New("connection closed")
+	}
+	w.written += len(p)
+	return len(p), nil
+}
+
+func TestExpandTo(t *testing.T) {
+	large := strings.Repeat("x", 100<<10)
+	vars := Map{"NAME": "api", "LARGE": large}
+
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"service: $NAME", "service: api", false},
+		{"no references", "no references", false},
+		{"[$LARGE]", "[" + large + "]", false},
+		{strings.Repeat("$NAME ", 10<<10), strings.Repeat("api ", 10<<10), false},
+		{"$NAME ${MISSING:?required}", "", true},
+	}
+	for _, tt := range tests {
+		var out strings.Builder
+		n, err := ExpandTo(&out, tt.input, WithProvider(vars))
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("ExpandTo(%.20q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+		}
+		if out.String() != tt.want || n != len(tt.want) {
+			t.Errorf("ExpandTo(%.20q) got = %d, %.20q, want %.20q", tt.input, n, out.String(), tt.want)
+		}
+	}
+
+	vars = Map{}
+	x := NewExpander(WithProvider(vars), WithTransactionalAssignments())
+	w := &failingWriter{limit: 10}
+	if n, err := x.ExpandTo(w, "${A:=1}"+large); err == nil || n != w.written {
+		t.Errorf("ExpandTo() got = %d, %v, want a write error", n, err)
+	}
+	if len(vars) != 0 {
+		t.Errorf("ExpandTo() assigned variables despite the write error: %v", vars)
+	}
+}
@@ -0,0 +1,92 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// xdgHomes holds the directories, relative to the home directory, that the
+// XDG Base Directory Specification defines as fallbacks for its variables
+var xdgHomes = map[string]string{
+	"XDG_CONFIG_HOME": ".config",
+	"XDG_DATA_HOME":   filepath.Join(".local", "share"),
+	"XDG_CACHE_HOME":  ".cache",
+	"XDG_STATE_HOME":  filepath.Join(".local", "state"),
+}
+
+// xdgDirs holds the fallbacks of the XDG search path variables
+var xdgDirs = map[string]string{
+	"XDG_CONFIG_DIRS": "/etc/xdg",
+	"XDG_DATA_DIRS":   "/usr/local/share/:/usr/share/",
+}
+
+// XDGConfigHome returns the directory for user configuration files:
+// $XDG_CONFIG_HOME, or ~/.config if it is unset or not an absolute path
+func XDGConfigHome() string {
+	return xdgHome("XDG_CONFIG_HOME")
+}
+
+// XDGDataHome returns the directory for user data files: $XDG_DATA_HOME, or
+// ~/.local/share if it is unset or not an absolute path
+func XDGDataHome() string {
+	return xdgHome("XDG_DATA_HOME")
+}
+
+// XDGCacheHome returns the directory for user cache files: $XDG_CACHE_HOME,
+// or ~/.cache if it is unset or not an absolute path
+func XDGCacheHome() string {
+	return xdgHome("XDG_CACHE_HOME")
+}
+
+// XDGStateHome returns the directory for user state files such as logs and
+// history: $XDG_STATE_HOME, or ~/.local/state if it is unset or not an
+// absolute path
+func XDGStateHome() string {
+	return xdgHome("XDG_STATE_HOME")
+}
+
+// xdgHome returns the value of the XDG variable name from the process
+// environment, falling back to its default under the home directory. It
+// returns an empty string if the home directory is unknown.
+func xdgHome(name string) string {
+	if dir := os.Getenv(name); filepath.IsAbs(dir) {
+		return dir
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, xdgHomes[name])
+}
+
+// WithXDG makes the variables of the XDG Base Directory Specification always
+// resolvable: when the provider does not set XDG_CONFIG_HOME, XDG_DATA_HOME,
+// XDG_CACHE_HOME, XDG_STATE_HOME, XDG_CONFIG_DIRS or XDG_DATA_DIRS, they
+// resolve to the defaults of the specification, under the HOME of the
+// provider or else the home directory of the user
+func WithXDG() Option {
+	return func(c *config) {
+		c.xdg = true
+	}
+}
+
+// xdgLookup returns the default of the XDG variable name
+func (e *expander) xdgLookup(name string) (string, bool, error) {
+	if dirs, ok := xdgDirs[name]; ok {
+		return dirs, true, nil
+	}
+	rel, ok := xdgHomes[name]
+	if !ok {
+		return "", false, nil
+	}
+	home, found, err := e.providerLookup("HOME")
+	if err != nil {
+		return "", false, err
+	}
+	if !found || home == "" {
+		if home, err = os.UserHomeDir(); err != nil {
+			return "", false, nil
+		}
+	}
+	return filepath.Join(home, rel), true, nil
+}
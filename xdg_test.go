@@ -0,0 +1,56 @@
+package env
+
+import (
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestXDGHomes(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("the home directory is not read from HOME")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "/etc/custom")
+	t.Setenv("XDG_DATA_HOME", "relative/data")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	tests := []struct {
+		name string
+		fn   func() string
+		want string
+	}{
+		{"XDGConfigHome", XDGConfigHome, "/etc/custom"},
+		{"XDGDataHome", XDGDataHome, filepath.Join(home, ".local", "share")},
+		{"XDGCacheHome", XDGCacheHome, filepath.Join(home, ".cache")},
+		{"XDGStateHome", XDGStateHome, filepath.Join(home, ".local", "state")},
+	}
+	for _, tt := range tests {
+		if got := tt.fn(); got != tt.want {
+			t.Errorf("%s() got = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWithXDG(t *testing.T) {
+	vars := Map{"HOME": "/home/app", "XDG_CACHE_HOME": "/tmp/cache"}
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"$XDG_CONFIG_HOME/app", filepath.Join("/home/app", ".config") + "/app"},
+		{"${XDG_STATE_HOME}", filepath.Join("/home/app", ".local", "state")},
+		{"$XDG_CACHE_HOME", "/tmp/cache"},
+		{"$XDG_DATA_DIRS", "/usr/local/share/:/usr/share/"},
+		{"[$XDG_RUNTIME_DIR]", "[]"},
+	}
+	for _, tt := range tests {
+		if got, err := Expand(tt.input, vars, WithXDG()); err != nil || got != tt.want {
+			t.Errorf("Expand(%q) got = %q, %v, want %q", tt.input, got, err, tt.want)
+		}
+	}
+	if got, err := Expand("[$XDG_CONFIG_HOME]", vars); err != nil || got != "[]" {
+		t.Errorf("Expand() got = %q, %v without WithXDG", got, err)
+	}
+}